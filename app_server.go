@@ -6,17 +6,26 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/idempotency"
+	"github.com/xpzouying/xiaohongshu-mcp/jobs"
+	"github.com/xpzouying/xiaohongshu-mcp/ratelimit"
 )
 
 // AppServer 应用服务器结构体，封装所有服务和处理器
 type AppServer struct {
 	xiaohongshuService *XiaohongshuService
 	mcpServer          *mcp.Server
+	tools              []tool // 同时注册为 MCP 工具与 REST 路由的操作
+	auth               *apiKeyAuth
+	jobs               *jobs.Manager      // 后台任务
+	limits             *ratelimit.Limiter // 写操作的频率限制
+	idempotency        *idempotency.Store // 发布、评论请求的 idempotency_key
 	router             *gin.Engine
 	httpServer         *http.Server
 }
@@ -25,9 +34,20 @@ type AppServer struct {
 func NewAppServer(xiaohongshuService *XiaohongshuService) *AppServer {
 	appServer := &AppServer{
 		xiaohongshuService: xiaohongshuService,
+		limits:             newRateLimiter(),
+		idempotency:        newIdempotencyStore(),
+	}
+
+	// stdio 模式下请求来自启动本进程的客户端，不经过网络，也无法携带 API Key
+	if cfg := configs.Get(); cfg.Server.Transport == configs.TransportStdio {
+		logrus.Info("stdio 模式，不做 API Key 鉴权")
+	} else {
+		appServer.auth = newAPIKeyAuth(cfg.Auth)
 	}
 
 	// 初始化 MCP Server（需要在创建 appServer 之后，因为工具注册需要访问 appServer）
+	appServer.tools = newToolRegistry(appServer)
+	appServer.jobs = newJobManager(appServer)
 	appServer.mcpServer = InitMCPServer(appServer)
 
 	return appServer
@@ -58,7 +78,7 @@ func (s *AppServer) Start(port string) error {
 
 	logrus.Infof("正在关闭服务器...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), configs.Get().Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
@@ -67,5 +87,56 @@ func (s *AppServer) Start(port string) error {
 		logrus.Infof("服务器已优雅关闭")
 	}
 
+	s.closeBackground()
+	return nil
+}
+
+// ServeStdio 通过标准输入输出提供 MCP 服务，客户端关闭标准输入或收到中断信号时返回。
+// 标准输出只用于 MCP 消息，日志写到标准错误或日志文件
+func (s *AppServer) ServeStdio() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return s.serveStdio(ctx, &mcp.StdioTransport{})
+}
+
+// serveStdio 通过 transport 提供 MCP 服务，连接断开或 ctx 取消时关闭后台任务与浏览器并返回
+func (s *AppServer) serveStdio(ctx context.Context, transport mcp.Transport) error {
+	session, err := s.mcpServer.Connect(ctx, transport, nil)
+	if err != nil {
+		return err
+	}
+	// 连接已持有真正的标准输出，之后误写到 os.Stdout 的内容转到标准错误，避免破坏 MCP 消息
+	os.Stdout = os.Stderr
+	logrus.Info("MCP 服务已通过 stdio 启动")
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	select {
+	case <-ctx.Done():
+		logrus.Infof("正在关闭服务器...")
+		_ = session.Close()
+		err = <-done
+	case err = <-done:
+		logrus.Infof("客户端已断开连接")
+	}
+	if err != nil {
+		logrus.Debugf("stdio 连接结束: %v", err)
+	}
+
+	s.closeBackground()
 	return nil
 }
+
+// closeBackground 停止后台任务并关闭浏览器
+func (s *AppServer) closeBackground() {
+	// 取消执行中的后台任务，下次启动时仍可查询到它们被中断
+	jobsCtx, jobsCancel := context.WithTimeout(context.Background(), configs.Get().Server.ShutdownTimeout)
+	defer jobsCancel()
+	s.jobs.Close(jobsCtx)
+
+	// 关闭共享的浏览器实例，避免 Chrome 进程在容器中残留
+	browser.GetGlobalManager().CloseBrowser()
+	logrus.Infof("浏览器实例已关闭")
+}
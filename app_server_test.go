@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// newTestAppServer 创建不启动浏览器、不写入本地文件的 AppServer，edit 可以修改配置
+func newTestAppServer(t *testing.T, edit func(cfg *configs.Config)) *AppServer {
+	t.Helper()
+
+	old := configs.Get()
+	cfg := configs.Default()
+	cfg.Paths.JobsDir = ""
+	cfg.Paths.RateLimitFile = ""
+	cfg.Paths.IdempotencyFile = ""
+	cfg.Paths.DebugDir = ""
+	if edit != nil {
+		edit(cfg)
+	}
+	configs.Set(cfg)
+	t.Cleanup(func() { configs.Set(&old) })
+
+	return NewAppServer(&XiaohongshuService{})
+}
+
+// connectMCP 通过内存传输连接 MCP 服务端，返回客户端会话
+func connectMCP(t *testing.T, server *mcp.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ss.Close() })
+
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, opts).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func TestServeStdioRoundTrip(t *testing.T) {
+	s := newTestAppServer(t, nil)
+
+	// 捕获服务期间写到标准输出的内容
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	done := make(chan error, 1)
+	go func() { done <- s.serveStdio(context.Background(), serverTransport) }()
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil).Connect(context.Background(), clientTransport, nil)
+	require.NoError(t, err)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Len(t, tools.Tools, len(s.tools))
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "like_feed",
+		Arguments: map[string]any{"feed_id": "", "xsec_token": ""},
+	})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "[INVALID_REQUEST]")
+
+	// 连接建立后误写到 os.Stdout 的内容转到标准错误
+	fmt.Println("stray output")
+
+	require.NoError(t, session.Close())
+	select {
+	case err := <-done:
+		assert.NoError(t, err, "客户端断开后正常返回")
+	case <-time.After(5 * time.Second):
+		t.Fatal("客户端断开后 serveStdio 没有返回")
+	}
+
+	require.NoError(t, w.Close())
+	written, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Empty(t, string(written), "标准输出只用于 MCP 消息")
+}
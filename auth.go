@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// 鉴权相关的错误码
+const (
+	errCodeUnauthorized = "UNAUTHORIZED"
+	errCodeForbidden    = "FORBIDDEN"
+)
+
+// errForbidden API Key 缺少调用该操作所需的权限
+var errForbidden = errors.New("权限不足")
+
+// apiKeyContextKey gin 上下文中保存已通过鉴权的 API Key 名称的键
+const apiKeyContextKey = "api_key"
+
+// mcpTokenLifetime MCP 请求中 TokenInfo 的有效期。API Key 本身不过期，
+// 但 SDK 要求 TokenInfo 带有过期时间，且每个 HTTP 请求都会重新校验
+const mcpTokenLifetime = time.Hour
+
+// anonymousAccount 未启用鉴权时操作所属的账号名称
+const anonymousAccount = "anonymous"
+
+type accountContextKey struct{}
+
+// withAccount 在 ctx 中记录发起操作的 API Key 名称，供监控指标等按账号统计
+func withAccount(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, accountContextKey{}, name)
+}
+
+// accountFrom 返回发起操作的 API Key 名称，未启用鉴权时返回 anonymous
+func accountFrom(ctx context.Context) string {
+	if name, _ := ctx.Value(accountContextKey{}).(string); name != "" {
+		return name
+	}
+	return anonymousAccount
+}
+
+// mcpAccount 返回 MCP 请求（工具调用、资源读取）所携带的 API Key 名称
+func mcpAccount(extra *mcp.RequestExtra) string {
+	if extra == nil || extra.TokenInfo == nil {
+		return ""
+	}
+	name, _ := extra.TokenInfo.Extra["name"].(string)
+	return name
+}
+
+// apiKeyAuth 基于 API Key 的鉴权，客户端通过 Authorization: Bearer <key> 发送。
+// 未配置任何 API Key 时所有请求直接放行
+type apiKeyAuth struct {
+	keys []configs.APIKey
+}
+
+// newAPIKeyAuth 根据配置创建鉴权器
+func newAPIKeyAuth(cfg configs.AuthConfig) *apiKeyAuth {
+	if !cfg.Enabled() {
+		logrus.Warn("未配置 API Key，REST API 与 MCP 端点不做鉴权，请勿暴露到公网")
+	} else {
+		logrus.Infof("已启用 API Key 鉴权，共 %d 个 Key", len(cfg.APIKeys))
+	}
+	return &apiKeyAuth{keys: cfg.APIKeys}
+}
+
+func (a *apiKeyAuth) enabled() bool {
+	return a != nil && len(a.keys) > 0
+}
+
+// lookup 查找与 token 匹配的 API Key，比较时间与 token 内容无关
+func (a *apiKeyAuth) lookup(token string) (configs.APIKey, bool) {
+	var found configs.APIKey
+	ok := false
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(token)) == 1 {
+			found, ok = k, true
+		}
+	}
+	return found, ok
+}
+
+// bearerToken 从 Authorization 头中取出 Bearer token
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// authenticate 校验请求携带的 API Key，失败时返回 401 并记录日志（不记录 token 内容）
+func (a *apiKeyAuth) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.enabled() {
+			c.Next()
+			return
+		}
+
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			a.reject(c, http.StatusUnauthorized, errCodeUnauthorized, "缺少 API Key", "请求未携带 Bearer token")
+			return
+		}
+		key, ok := a.lookup(token)
+		if !ok {
+			a.reject(c, http.StatusUnauthorized, errCodeUnauthorized, "API Key 无效", "API Key 不匹配")
+			return
+		}
+
+		c.Set(apiKeyContextKey, key.Name)
+		c.Set("account", key.Name)
+		c.Next()
+	}
+}
+
+// requireScope 要求已通过鉴权的 API Key 拥有指定权限，否则返回 403
+func (a *apiKeyAuth) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.allowed(c, scope) {
+			a.rejectScope(c, scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowed 当前请求的 API Key 是否拥有指定权限，未启用鉴权时总是返回 true
+func (a *apiKeyAuth) allowed(c *gin.Context, scope string) bool {
+	if !a.enabled() {
+		return true
+	}
+
+	name := c.GetString(apiKeyContextKey)
+	for _, k := range a.keys {
+		if k.Name == name {
+			return k.HasScope(scope)
+		}
+	}
+	return false
+}
+
+// rejectScope 返回 403 并记录缺少的权限
+func (a *apiKeyAuth) rejectScope(c *gin.Context, scope string) {
+	a.reject(c, http.StatusForbidden, errCodeForbidden, "权限不足",
+		fmt.Sprintf("API Key %q 缺少 %s 权限", c.GetString(apiKeyContextKey), scope))
+}
+
+// reject 记录被拒绝的请求并返回错误响应
+func (a *apiKeyAuth) reject(c *gin.Context, status int, code, message, reason string) {
+	logrus.Warnf("拒绝访问 %s %s from %s: %s", c.Request.Method, c.Request.URL.Path, c.ClientIP(), reason)
+	respondError(c, status, code, message, reason)
+	c.Abort()
+}
+
+// verifyMCPToken 供 auth.RequireBearerToken 使用，将 API Key 转换为 MCP 请求中的 TokenInfo，
+// 工具调用时据此检查权限
+func (a *apiKeyAuth) verifyMCPToken(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+	key, ok := a.lookup(token)
+	if !ok {
+		return nil, fmt.Errorf("%w: API Key 无效", auth.ErrInvalidToken)
+	}
+	return &auth.TokenInfo{
+		Scopes:     key.Scopes,
+		Expiration: time.Now().Add(mcpTokenLifetime),
+		Extra:      map[string]any{"name": key.Name},
+	}, nil
+}
+
+// wrapMCP 启用鉴权时为 MCP handler 加上 Bearer token 校验
+func (a *apiKeyAuth) wrapMCP(h http.Handler) http.Handler {
+	if !a.enabled() {
+		return h
+	}
+	return auth.RequireBearerToken(a.verifyMCPToken, nil)(h)
+}
+
+// authorizeMCP 检查 MCP 请求的 API Key 是否拥有所需的权限，target 为请求的对象，如“工具 like_feed”
+func (a *apiKeyAuth) authorizeMCP(extra *mcp.RequestExtra, target, scope string) error {
+	if !a.enabled() {
+		return nil
+	}
+
+	var info *auth.TokenInfo
+	if extra != nil {
+		info = extra.TokenInfo
+	}
+	if info == nil {
+		logrus.Warnf("拒绝访问 MCP %s: 请求未携带 API Key", target)
+		return fmt.Errorf("%w: 请求未携带 API Key", errForbidden)
+	}
+
+	keyName, _ := info.Extra["name"].(string)
+	if slices.Contains(info.Scopes, scope) {
+		return nil
+	}
+	logrus.Warnf("拒绝访问 MCP %s: API Key %q 缺少 %s 权限", target, keyName, scope)
+	return fmt.Errorf("%w: API Key %q 缺少 %s 权限", errForbidden, keyName, scope)
+}
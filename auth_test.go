@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+const (
+	testReaderKey   = "reader-key-0123456789"
+	testInteractKey = "interact-key-0123456789"
+)
+
+// newAuthTestServer 启用鉴权的 AppServer，reader 只有 read 权限，interact 只有 interact 权限
+func newAuthTestServer(t *testing.T) *AppServer {
+	return newTestAppServer(t, func(cfg *configs.Config) {
+		cfg.Auth.APIKeys = []configs.APIKey{
+			{Name: "reader", Key: testReaderKey, Scopes: []string{configs.ScopeRead}},
+			{Name: "interact", Key: testInteractKey, Scopes: []string{configs.ScopeInteract}},
+		}
+	})
+}
+
+func TestAuthRESTRejections(t *testing.T) {
+	router := setupRoutes(newAuthTestServer(t))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		status int
+		code   string
+	}{
+		{"缺少 API Key", http.MethodGet, "/api/v1/selectors", "", http.StatusUnauthorized, errCodeUnauthorized},
+		{"不是 Bearer", http.MethodGet, "/api/v1/selectors", "Basic " + testReaderKey, http.StatusUnauthorized, errCodeUnauthorized},
+		{"API Key 无效", http.MethodGet, "/api/v1/selectors", "Bearer wrong-key-0123456789", http.StatusUnauthorized, errCodeUnauthorized},
+		{"缺少 admin 权限", http.MethodPost, "/api/v1/selectors/reload", "Bearer " + testReaderKey, http.StatusForbidden, errCodeForbidden},
+		{"缺少工具所需权限", http.MethodPost, "/api/v1/feeds/like", "Bearer " + testReaderKey, http.StatusForbidden, errCodeForbidden},
+		{"缺少任务工具所需权限", http.MethodPost, "/api/v1/jobs", "Bearer " + testReaderKey, http.StatusForbidden, errCodeForbidden},
+		{"MCP 缺少 API Key", http.MethodPost, "/mcp", "", http.StatusUnauthorized, errCodeUnauthorized},
+		{"指标缺少 read 权限", http.MethodGet, "/metrics", "Bearer " + testInteractKey, http.StatusForbidden, errCodeForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"tool":"like_feed"}`))
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+
+			var resp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/selectors", nil)
+	req.Header.Set("Authorization", "Bearer "+testReaderKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "拥有所需权限时放行")
+}
+
+// bearerTransport 为请求加上 Authorization 头
+type bearerTransport string
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+string(b))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAuthMCPToolScopes(t *testing.T) {
+	srv := httptest.NewServer(setupRoutes(newAuthTestServer(t)))
+	t.Cleanup(srv.Close)
+
+	// 参数中的空字符串通过输入 schema 检查，在权限检查之后的参数校验中失败，不会启动浏览器
+	callLike := func(key string) *mcp.CallToolResult {
+		transport := &mcp.StreamableClientTransport{
+			Endpoint:   srv.URL + "/mcp",
+			HTTPClient: &http.Client{Transport: bearerTransport(key)},
+			MaxRetries: -1,
+		}
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil).Connect(context.Background(), transport, nil)
+		require.NoError(t, err)
+		defer session.Close()
+
+		res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "like_feed",
+			Arguments: map[string]any{"feed_id": "", "xsec_token": ""},
+		})
+		require.NoError(t, err)
+		require.True(t, res.IsError)
+		return res
+	}
+
+	text := callLike(testReaderKey).Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "[FORBIDDEN]")
+	assert.Contains(t, text, "缺少 interact 权限")
+
+	text = callLike(testInteractKey).Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "[INVALID_REQUEST]", "拥有 interact 权限时通过权限检查")
+}
@@ -0,0 +1,275 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/headless_browser"
+	"github.com/xpzouying/xiaohongshu-mcp/metrics"
+)
+
+// pingTimeout 健康检查时等待 Chrome 响应的最长时间
+const pingTimeout = 5 * time.Second
+
+// IsSessionNotFoundErr 判断是否为 CDP session 失效错误（页面或浏览器已关闭、崩溃）
+func IsSessionNotFoundErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, cdp.ErrSessionNotFound) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Session with given id not found") || strings.Contains(msg, "-32001")
+}
+
+// IsBrowserGoneErr 判断错误是否意味着 Chrome 崩溃或与 Chrome 的连接已断开，
+// 此类错误在重建浏览器（或页面）后重试通常可以恢复
+func IsBrowserGoneErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsSessionNotFoundErr(err) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, s := range []string{
+		"use of closed network connection",
+		"websocket: close",
+		"broken pipe",
+		"connection reset by peer",
+		"connection refused",
+		"Target closed",
+		"Target crashed",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// recoverErr 将 panic 转换为错误，headless_browser 与 stealth 的 Must* 调用在 Chrome 异常时会 panic
+func recoverErr(r any) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
+
+// launchBrowser 启动新的浏览器实例（每次启动都会重新从文件加载 cookies），启动失败时返回错误而不是 panic
+func launchBrowser(headless bool, binPath string) (b *headless_browser.Browser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("启动浏览器失败: %w", recoverErr(r))
+		}
+	}()
+	return NewBrowser(headless, WithBinPath(binPath)), nil
+}
+
+// newPage 在浏览器实例上创建新页面，Chrome 已崩溃或连接断开时返回错误
+func newPage(b *headless_browser.Browser) (page *rod.Page, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("创建页面失败: %w", recoverErr(r))
+		}
+	}()
+	return b.NewPage(), nil
+}
+
+// closeQuietly 关闭浏览器实例，忽略已崩溃的浏览器在关闭时产生的 panic
+func closeQuietly(b *headless_browser.Browser) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Warnf("关闭浏览器实例时出错（可能已崩溃）: %v", r)
+		}
+	}()
+	b.Close()
+}
+
+// ping 向 Chrome 发送一个轻量的 CDP 请求，确认浏览器进程和连接仍然可用
+func ping(ctx context.Context, handle *rod.Browser) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if _, err := (proto.BrowserGetVersion{}).Call(handle.Context(ctx)); err != nil {
+		return fmt.Errorf("浏览器无响应: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck 检查当前浏览器实例是否可用。
+// 浏览器尚未启动时视为健康（下次申请页面时按需启动）；
+// 浏览器已崩溃或连接断开时丢弃该实例并返回错误，下次申请页面时会重新启动并加载 cookies
+func (m *Manager) HealthCheck(ctx context.Context) error {
+	m.mu.Lock()
+	b, handle := m.browser, m.handle
+	m.mu.Unlock()
+
+	if b == nil {
+		return nil
+	}
+
+	var err error
+	if handle != nil {
+		err = ping(ctx, handle)
+	} else {
+		// 尚未创建过页面，没有可用的 CDP 句柄，以创建并关闭一个页面作为探测
+		var page *rod.Page
+		if page, err = newPage(b); err == nil {
+			m.rememberHandle(b, page.Browser())
+			_ = page.Close()
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err() // 调用方取消，不代表浏览器异常
+		}
+		m.discardBrowser(b, err)
+		return err
+	}
+	return nil
+}
+
+// CheckReady 检查浏览器能否使用：已启动时向 Chrome 发送探测请求，未启动时检查能否找到浏览器可执行文件。
+// 浏览器未启动时不会为此启动浏览器，以免探测请求使空闲自动关闭失效
+func (m *Manager) CheckReady(ctx context.Context) error {
+	m.mu.Lock()
+	running, binPath := m.browser != nil, m.binPath
+	m.mu.Unlock()
+
+	if running {
+		return m.HealthCheck(ctx)
+	}
+	_, err := findBrowserBin(binPath)
+	return err
+}
+
+// findBrowserBin 返回将要启动的浏览器可执行文件：优先使用指定的路径，否则在系统常用位置查找
+func findBrowserBin(binPath string) (string, error) {
+	if binPath != "" {
+		info, err := os.Stat(binPath)
+		if err != nil {
+			return "", fmt.Errorf("浏览器可执行文件不可用: %w", err)
+		}
+		if info.IsDir() {
+			return "", fmt.Errorf("浏览器路径 %s 是目录", binPath)
+		}
+		return binPath, nil
+	}
+
+	if path, ok := launcher.LookPath(); ok {
+		return path, nil
+	}
+	return "", errors.New("未找到 Chrome/Chromium，请通过 -bin 或 ROD_BROWSER_BIN 指定浏览器路径")
+}
+
+// HandleOpError 在操作失败后调用：如果错误表明 Chrome 可能已崩溃或断开，
+// 则检查浏览器健康状态并在必要时丢弃它。返回该错误是否值得在新页面上重试
+func (m *Manager) HandleOpError(ctx context.Context, err error) bool {
+	if !IsBrowserGoneErr(err) {
+		return false
+	}
+
+	logrus.Warnf("操作失败，疑似浏览器崩溃或连接断开: %v", err)
+	if hcErr := m.HealthCheck(context.WithoutCancel(ctx)); hcErr != nil {
+		logrus.Warnf("浏览器健康检查失败，已丢弃当前实例: %v", hcErr)
+	}
+	return true
+}
+
+// currentBrowser 返回当前浏览器实例，不存在时启动新实例
+func (m *Manager) currentBrowser() (*headless_browser.Browser, *rod.Browser, error) {
+	b, err := m.ensureBrowser()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var handle *rod.Browser
+	if m.browser == b {
+		handle = m.handle
+	}
+	return b, handle, nil
+}
+
+// rememberHandle 记录浏览器实例对应的 rod 句柄，用于后续的健康检查
+func (m *Manager) rememberHandle(b *headless_browser.Browser, handle *rod.Browser) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.browser == b && m.handle == nil {
+		m.handle = handle
+	}
+}
+
+// discardBrowser 丢弃已失效的浏览器实例。如果该实例已被其他操作替换则什么也不做
+func (m *Manager) discardBrowser(b *headless_browser.Browser, reason error) {
+	m.mu.Lock()
+	if m.browser != b {
+		m.mu.Unlock()
+		return
+	}
+	m.browser = nil
+	m.handle = nil
+	m.restarts++
+	m.mu.Unlock()
+	metrics.BrowserRestarted()
+
+	logrus.Warnf("浏览器实例已失效，将在下次使用时重新启动: %v", reason)
+	go m.closeInstance(b) // 已崩溃的浏览器关闭时可能阻塞，不占用调用方
+}
+
+// openPage 在当前浏览器上创建页面。浏览器已崩溃或无响应时丢弃它并重新启动一次
+func (m *Manager) openPage(ctx context.Context) (*rod.Page, error) {
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		b, handle, err := m.currentBrowser()
+		if err != nil {
+			return nil, err
+		}
+
+		if handle != nil {
+			if err := ping(ctx, handle); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				lastErr = err
+				m.discardBrowser(b, err)
+				continue
+			}
+		}
+
+		page, err := newPage(b)
+		if err != nil {
+			lastErr = err
+			m.discardBrowser(b, err)
+			continue
+		}
+
+		m.rememberHandle(b, page.Browser())
+		return page, nil
+	}
+
+	return nil, lastErr
+}
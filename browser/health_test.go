@@ -0,0 +1,59 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBrowserGoneErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"session not found", fmt.Errorf("click: %w", cdp.ErrSessionNotFound), true},
+		{"session not found text", errors.New("{-32001 Session with given id not found. }"), true},
+		{"websocket eof", fmt.Errorf("read: %w", io.EOF), true},
+		{"closed conn", errors.New("write tcp 127.0.0.1:1->127.0.0.1:2: use of closed network connection"), true},
+		{"context canceled", context.Canceled, false},
+		{"element not found", errors.New("未找到发布按钮"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsBrowserGoneErr(tt.err))
+		})
+	}
+}
+
+func TestHealthCheckWithoutBrowser(t *testing.T) {
+	m := newManager()
+
+	assert.NoError(t, m.HealthCheck(context.Background()))
+	assert.False(t, m.HandleOpError(context.Background(), errors.New("普通错误")))
+	assert.Equal(t, 0, m.Status().Restarts)
+}
+
+func TestFindBrowserBin(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "chrome")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"), 0o755))
+
+	got, err := findBrowserBin(bin)
+	assert.NoError(t, err)
+	assert.Equal(t, bin, got)
+
+	_, err = findBrowserBin(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+	_, err = findBrowserBin(t.TempDir())
+	assert.Error(t, err, "目录不是可执行文件")
+}
@@ -0,0 +1,58 @@
+package browser
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultIdleTimeout 浏览器空闲（没有任何页面占用）多久后自动关闭
+const DefaultIdleTimeout = 10 * time.Minute
+
+// SetIdleTimeout 设置浏览器空闲自动关闭的时间，<=0 表示不自动关闭。
+// 关闭后下次申请页面时会重新启动浏览器并加载 cookies
+func (m *Manager) SetIdleTimeout(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d < 0 {
+		d = 0
+	}
+	m.idleTimeout = d
+	if m.active == 0 {
+		m.scheduleIdleCloseLocked()
+	}
+}
+
+// scheduleIdleCloseLocked 在所有页面释放后开始空闲计时，调用方需持有 m.mu
+func (m *Manager) scheduleIdleCloseLocked() {
+	if m.idleTimer != nil {
+		m.idleTimer.Stop()
+		m.idleTimer = nil
+	}
+	if m.idleTimeout <= 0 || m.browser == nil {
+		return
+	}
+	m.idleSince = time.Now()
+	m.idleTimer = time.AfterFunc(m.idleTimeout, m.closeIfIdleTooLong)
+}
+
+// closeIfIdleTooLong 空闲计时到期后检查浏览器是否仍处于空闲状态，是则在锁外关闭
+func (m *Manager) closeIfIdleTooLong() {
+	m.mu.Lock()
+	if m.browser == nil || m.active > 0 || m.idleTimeout <= 0 {
+		m.mu.Unlock()
+		return
+	}
+	idle := time.Since(m.idleSince)
+	if idle < m.idleTimeout {
+		m.mu.Unlock()
+		return // 期间有操作执行过，由新的计时器负责
+	}
+
+	logrus.Infof("浏览器已空闲 %v，自动关闭以释放资源", idle.Round(time.Second))
+	b := m.detachBrowserLocked()
+	m.mu.Unlock()
+
+	m.closeDetached(b)
+}
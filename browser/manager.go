@@ -1,23 +1,171 @@
 package browser
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
-	// "runtime"
+	"time"
 
 	"github.com/go-rod/rod"
-	// "github.com/go-rod/rod/lib/proto"
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/headless_browser"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/metrics"
 )
 
-// Manager 浏览器实例管理器，确保同一时间只有一个浏览器实例在使用
+// DefaultAcquireTimeout 申请页面时的默认最长等待时间
+const DefaultAcquireTimeout = 3 * time.Minute
+
+// BusyError 在等待页面配额超时时返回，表示浏览器当前繁忙
+type BusyError struct {
+	Op         string        // 申请页面的操作
+	Waited     time.Duration // 已等待的时长
+	QueueDepth int           // 超时时仍在排队的操作数（不含自身）
+	Running    []string      // 超时时正在执行的操作
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("浏览器繁忙: 操作 %s 等待 %v 后仍未获得页面，正在执行: %v，排队中: %d",
+		e.Op, e.Waited.Round(time.Second), e.Running, e.QueueDepth)
+}
+
+// IsBusyError 判断错误是否为浏览器繁忙错误
+func IsBusyError(err error) bool {
+	var busy *BusyError
+	return errors.As(err, &busy)
+}
+
+// OpKind 操作类型，决定页面申请时占用哪一类配额
+type OpKind int
+
+const (
+	// OpRead 只读操作（搜索、详情、用户主页等），可与其他操作并发
+	OpRead OpKind = iota
+	// OpWrite 写操作（发布、评论、点赞、收藏等），受写操作配额限制
+	OpWrite
+	// OpExclusive 独占操作，执行期间不允许任何其他页面存在
+	OpExclusive
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpRead:
+		return "read"
+	case OpWrite:
+		return "write"
+	case OpExclusive:
+		return "exclusive"
+	default:
+		return "unknown"
+	}
+}
+
+// Op 描述一次页面申请
+type Op struct {
+	// Name 操作名称，用于日志
+	Name string
+	// Kind 操作类型
+	Kind OpKind
+	// Serial 串行分组，相同分组的操作不会同时执行；为空表示不限制
+	Serial string
+}
+
+// PoolConfig 页面池配置
+type PoolConfig struct {
+	// MaxPages 同一时间最多打开的页面数
+	MaxPages int
+	// MaxReadPages 同一时间最多执行的只读操作数
+	MaxReadPages int
+	// MaxWritePages 同一时间最多执行的写操作数
+	MaxWritePages int
+}
+
+// DefaultPoolConfig 默认页面池配置
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxPages:      4,
+		MaxReadPages:  3,
+		MaxWritePages: 2,
+	}
+}
+
+// normalize 修正非法配置，保证各项配额至少为 1 且不超过总数
+func (c PoolConfig) normalize() PoolConfig {
+	def := DefaultPoolConfig()
+	if c.MaxPages <= 0 {
+		c.MaxPages = def.MaxPages
+	}
+	if c.MaxReadPages <= 0 || c.MaxReadPages > c.MaxPages {
+		c.MaxReadPages = min(def.MaxReadPages, c.MaxPages)
+	}
+	if c.MaxWritePages <= 0 || c.MaxWritePages > c.MaxPages {
+		c.MaxWritePages = min(def.MaxWritePages, c.MaxPages)
+	}
+	return c
+}
+
+// Manager 浏览器实例管理器，在同一个 Chrome 进程中按配额并发分配页面
 type Manager struct {
-	mu       sync.Mutex
-	cond     *sync.Cond // 条件变量，用于等待浏览器释放
-	browser  *headless_browser.Browser
-	headless bool
-	binPath  string
-	inUse    bool // 标记浏览器是否正在使用中
+	mu             sync.Mutex
+	changed        chan struct{} // 配额变化时关闭并重建，用于唤醒等待者
+	browser        *headless_browser.Browser
+	handle         *rod.Browser // 当前浏览器实例的 rod 句柄，用于健康检查
+	launching      *launchCall  // 正在进行的浏览器启动，为空表示没有在启动
+	restarts       int          // 因崩溃或断开而被丢弃的浏览器实例数
+	headless       bool
+	binPath        string
+	launch         func(headless bool, binPath string) (*headless_browser.Browser, error) // 启动浏览器，测试中可替换
+	closeInstance  func(b *headless_browser.Browser)                                      // 关闭浏览器，测试中可替换
+	pool           PoolConfig
+	acquireTimeout time.Duration
+
+	idleTimeout time.Duration // 空闲多久后自动关闭浏览器，0 表示不自动关闭
+	idleTimer   *time.Timer
+	idleSince   time.Time // 最近一次所有页面都被释放的时间
+
+	active           int                 // 当前占用的页面数
+	activeByKind     map[OpKind]int      // 按操作类型统计的占用数
+	serials          map[string]struct{} // 正在执行的串行分组
+	exclusiveWaiting int                 // 正在等待的独占操作数，用于避免独占操作饿死
+
+	nextTicket uint64
+	running    map[uint64]opEntry // 正在执行的操作
+	waiting    map[uint64]opEntry // 正在排队的操作
+}
+
+// launchCall 一次浏览器启动，同时需要浏览器的调用方等待同一次启动的结果
+type launchCall struct {
+	done    chan struct{}
+	browser *headless_browser.Browser
+	err     error
+}
+
+// opEntry 记录操作进入运行或排队状态的时间
+type opEntry struct {
+	op    Op
+	since time.Time
+}
+
+// OpStatus 操作的运行/排队状态
+type OpStatus struct {
+	Name    string    `json:"name"`
+	Kind    string    `json:"kind"`
+	Serial  string    `json:"serial,omitempty"`
+	Since   time.Time `json:"since"`
+	Elapsed string    `json:"elapsed"`
+}
+
+// Status 浏览器管理器的当前状态
+type Status struct {
+	BrowserRunning bool       `json:"browser_running"`
+	Restarts       int        `json:"restarts"`
+	ActivePages    int        `json:"active_pages"`
+	MaxPages       int        `json:"max_pages"`
+	QueueDepth     int        `json:"queue_depth"`
+	Running        []OpStatus `json:"running"`
+	Waiting        []OpStatus `json:"waiting"`
 }
 
 var (
@@ -28,13 +176,26 @@ var (
 // GetGlobalManager 获取全局浏览器管理器（单例）
 func GetGlobalManager() *Manager {
 	globalManagerOnce.Do(func() {
-		m := &Manager{}
-		m.cond = sync.NewCond(&m.mu)
-		globalManager = m
+		globalManager = newManager()
 	})
 	return globalManager
 }
 
+func newManager() *Manager {
+	return &Manager{
+		changed:        make(chan struct{}),
+		launch:         launchBrowser,
+		closeInstance:  closeQuietly,
+		pool:           DefaultPoolConfig(),
+		acquireTimeout: DefaultAcquireTimeout,
+		idleTimeout:    DefaultIdleTimeout,
+		activeByKind:   make(map[OpKind]int),
+		serials:        make(map[string]struct{}),
+		running:        make(map[uint64]opEntry),
+		waiting:        make(map[uint64]opEntry),
+	}
+}
+
 // SetConfig 设置浏览器配置
 func (m *Manager) SetConfig(headless bool, binPath string) {
 	m.mu.Lock()
@@ -43,72 +204,354 @@ func (m *Manager) SetConfig(headless bool, binPath string) {
 	m.binPath = binPath
 }
 
-// AcquireBrowser 获取浏览器实例（会阻塞直到浏览器可用）
-// 返回浏览器实例和一个 release 函数，使用完毕后必须调用 release 函数释放浏览器
-func (m *Manager) AcquireBrowser() (*headless_browser.Browser, func()) {
+// SetPoolConfig 设置页面池配额
+func (m *Manager) SetPoolConfig(cfg PoolConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pool = cfg.normalize()
+	m.broadcastLocked()
+}
+
+// SetAcquireTimeout 设置申请页面时的最长等待时间，<=0 表示使用默认值
+func (m *Manager) SetAcquireTimeout(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d <= 0 {
+		d = DefaultAcquireTimeout
+	}
+	m.acquireTimeout = d
+}
+
+// Status 返回当前的页面占用、排队深度以及正在执行的操作
+func (m *Manager) Status() Status {
 	m.mu.Lock()
-	
-	// 如果浏览器正在使用中，等待其释放
-	for m.inUse {
-		logrus.Info("⏳ 浏览器正在使用中，等待释放...")
-		m.cond.Wait() // 释放锁并等待信号，被唤醒后会重新获得锁
-		logrus.Info("✓ 浏览器已释放，继续执行")
-	}
-	
-	// 如果浏览器实例不存在或已关闭，创建新实例
-	if m.browser == nil {
-		logrus.Info("创建新的浏览器实例...")
-		m.browser = NewBrowser(m.headless, WithBinPath(m.binPath))
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	toStatus := func(entries map[uint64]opEntry) []OpStatus {
+		list := make([]OpStatus, 0, len(entries))
+		for _, e := range entries {
+			list = append(list, OpStatus{
+				Name:    e.op.Name,
+				Kind:    e.op.Kind.String(),
+				Serial:  e.op.Serial,
+				Since:   e.since,
+				Elapsed: now.Sub(e.since).Round(time.Second).String(),
+			})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Since.Before(list[j].Since) })
+		return list
+	}
+
+	return Status{
+		BrowserRunning: m.browser != nil,
+		Restarts:       m.restarts,
+		ActivePages:    m.active,
+		MaxPages:       m.pool.MaxPages,
+		QueueDepth:     len(m.waiting),
+		Running:        toStatus(m.running),
+		Waiting:        toStatus(m.waiting),
+	}
+}
+
+// runningNamesLocked 返回正在执行的操作名称，调用方需持有 m.mu
+func (m *Manager) runningNamesLocked() []string {
+	names := make([]string, 0, len(m.running))
+	for _, e := range m.running {
+		names = append(names, e.op.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// broadcastLocked 唤醒所有等待者重新检查配额，调用方需持有 m.mu
+func (m *Manager) broadcastLocked() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// canAcquire 判断当前是否可以为 op 分配页面，调用方需持有 m.mu
+func (m *Manager) canAcquire(op Op) bool {
+	if m.activeByKind[OpExclusive] > 0 {
+		return false
+	}
+	if op.Kind == OpExclusive {
+		return m.active == 0
+	}
+	// 有独占操作在等待时，暂停分配新的页面，让已有操作尽快退出
+	if m.exclusiveWaiting > 0 {
+		return false
+	}
+	if m.active >= m.pool.MaxPages {
+		return false
+	}
+	switch op.Kind {
+	case OpRead:
+		if m.activeByKind[OpRead] >= m.pool.MaxReadPages {
+			return false
+		}
+	case OpWrite:
+		if m.activeByKind[OpWrite] >= m.pool.MaxWritePages {
+			return false
+		}
+	}
+	if op.Serial != "" {
+		if _, busy := m.serials[op.Serial]; busy {
+			return false
+		}
+	}
+	return true
+}
+
+// acquireSlot 阻塞直到 op 可以执行并占用对应配额，返回用于释放的票据。
+// ctx 被取消或等待超过 acquireTimeout 时返回错误。调用方需持有 m.mu，
+// 等待期间会临时释放锁。
+func (m *Manager) acquireSlot(ctx context.Context, op Op) (uint64, error) {
+	m.nextTicket++
+	ticket := m.nextTicket
+	start := time.Now()
+	defer m.reportPoolLocked() // 在其他 defer 之后执行，此时排队与占用已更新
+
+	if !m.canAcquire(op) {
+		if op.Kind == OpExclusive {
+			m.exclusiveWaiting++
+			defer func() {
+				m.exclusiveWaiting--
+				m.broadcastLocked() // 独占操作不再等待，唤醒被其阻塞的操作
+			}()
+		}
+		m.waiting[ticket] = opEntry{op: op, since: start}
+		defer delete(m.waiting, ticket)
+		m.reportPoolLocked()
+
+		logging.From(ctx).Infof("⏳ 浏览器配额已满，操作 %s(%s) 排队等待，正在执行: %v，排队中: %d",
+			op.Name, op.Kind, m.runningNamesLocked(), len(m.waiting)-1)
+
+		timer := time.NewTimer(m.acquireTimeout)
+		defer timer.Stop()
+
+		for !m.canAcquire(op) {
+			changed := m.changed
+			m.mu.Unlock()
+			select {
+			case <-changed:
+				m.mu.Lock()
+			case <-ctx.Done():
+				m.mu.Lock()
+				logging.From(ctx).Infof("操作 %s 的调用方已取消，放弃等待浏览器", op.Name)
+				metrics.ObserveBrowserWait(op.Kind.String(), metrics.WaitCanceled, time.Since(start))
+				return 0, ctx.Err()
+			case <-timer.C:
+				m.mu.Lock()
+				metrics.ObserveBrowserWait(op.Kind.String(), metrics.WaitTimeout, time.Since(start))
+				return 0, &BusyError{
+					Op:         op.Name,
+					Waited:     time.Since(start),
+					QueueDepth: len(m.waiting) - 1,
+					Running:    m.runningNamesLocked(),
+				}
+			}
+		}
+		logging.From(ctx).Infof("✓ 操作 %s 等待 %v 后获得浏览器页面", op.Name, time.Since(start).Round(time.Millisecond))
+	}
+
+	metrics.ObserveBrowserWait(op.Kind.String(), metrics.WaitAcquired, time.Since(start))
+	m.active++
+	m.activeByKind[op.Kind]++
+	if op.Serial != "" {
+		m.serials[op.Serial] = struct{}{}
+	}
+	m.running[ticket] = opEntry{op: op, since: time.Now()}
+	return ticket, nil
+}
+
+// releaseSlot 归还 op 占用的配额，调用方需持有 m.mu
+func (m *Manager) releaseSlot(ticket uint64) {
+	entry, ok := m.running[ticket]
+	if !ok {
+		return
+	}
+	delete(m.running, ticket)
+
+	m.active--
+	m.activeByKind[entry.op.Kind]--
+	if entry.op.Serial != "" {
+		delete(m.serials, entry.op.Serial)
+	}
+	if m.active == 0 {
+		m.scheduleIdleCloseLocked()
+	}
+	m.reportPoolLocked()
+	m.broadcastLocked() // 配额释放后可能有多个等待者可以继续执行
+}
+
+// reportPoolLocked 将排队与占用情况同步到监控指标，调用方需持有 m.mu
+func (m *Manager) reportPoolLocked() {
+	metrics.SetBrowserPool(len(m.waiting), m.active)
+}
+
+// ensureBrowser 返回当前浏览器实例，不存在时启动新实例，调用方不能持有 m.mu。
+// 启动 Chrome 可能需要数秒，期间不持有 m.mu；同一时间只进行一次启动，其余调用方等待其结果
+func (m *Manager) ensureBrowser() (*headless_browser.Browser, error) {
+	m.mu.Lock()
+	if m.browser != nil {
+		b := m.browser
+		m.mu.Unlock()
+		return b, nil
+	}
+	if call := m.launching; call != nil {
+		m.mu.Unlock()
+		<-call.done
+		return call.browser, call.err
+	}
+
+	call := &launchCall{done: make(chan struct{})}
+	m.launching = call
+	headless, binPath := m.headless, m.binPath
+	m.mu.Unlock()
+
+	logrus.Info("创建新的浏览器实例...")
+	b, err := m.launch(headless, binPath)
+
+	m.mu.Lock()
+	switch {
+	case err != nil:
+	case m.launching != call:
+		// 启动期间浏览器被关闭（如服务退出），丢弃刚启动的实例
+		err = errors.New("浏览器在启动期间被关闭")
+		go m.closeInstance(b)
+	default:
+		m.browser = b
+		metrics.BrowserLaunched()
 		logrus.Info("✓ 浏览器实例创建成功")
 	}
-	
-	m.inUse = true
-	browser := m.browser
-	
-	// 返回释放函数
+	if m.launching == call {
+		m.launching = nil
+	}
+	if err == nil {
+		call.browser = b
+	}
+	call.err = err
+	close(call.done)
+	m.mu.Unlock()
+
+	return call.browser, call.err
+}
+
+// AcquireBrowser 以独占方式获取浏览器实例（会阻塞直到所有页面释放、ctx 取消或等待超时）
+// 返回浏览器实例和一个 release 函数，使用完毕后必须调用 release 函数释放浏览器
+func (m *Manager) AcquireBrowser(ctx context.Context) (*headless_browser.Browser, func(), error) {
+	op := Op{Name: "acquire_browser", Kind: OpExclusive}
+
+	m.mu.Lock()
+	ticket, err := m.acquireSlot(ctx, op)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	browser, err := m.ensureBrowser()
+	if err != nil {
+		m.mu.Lock()
+		m.releaseSlot(ticket)
+		m.mu.Unlock()
+		return nil, nil, err
+	}
+
 	release := func() {
 		m.mu.Lock()
 		defer m.mu.Unlock()
-		m.inUse = false
+		m.releaseSlot(ticket)
 		logrus.Debug("浏览器实例已释放，可供其他操作使用")
-		m.cond.Signal() // 唤醒一个等待的 goroutine
 	}
-	
-	m.mu.Unlock()
-	return browser, release
+
+	return browser, release, nil
 }
 
 // CloseBrowser 关闭并清理浏览器实例
 func (m *Manager) CloseBrowser() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	if m.browser != nil {
-		logrus.Info("关闭浏览器实例...")
-		m.browser.Close()
-		m.browser = nil
-		m.inUse = false
+	b := m.detachBrowserLocked()
+	m.mu.Unlock()
+
+	m.closeDetached(b)
+}
+
+// CloseBrowserIfIdle 仅在没有任何页面占用时关闭浏览器实例，返回是否已关闭
+func (m *Manager) CloseBrowserIfIdle() bool {
+	m.mu.Lock()
+	if m.active > 0 {
+		logrus.Debugf("仍有 %d 个页面在使用中，暂不关闭浏览器", m.active)
+		m.mu.Unlock()
+		return false
+	}
+	b := m.detachBrowserLocked()
+	m.mu.Unlock()
+
+	m.closeDetached(b)
+	return true
+}
+
+// detachBrowserLocked 取下当前浏览器实例并停止空闲计时，返回的实例由调用方在锁外关闭。调用方需持有 m.mu
+func (m *Manager) detachBrowserLocked() *headless_browser.Browser {
+	m.launching = nil // 正在进行的启动完成后会丢弃其实例
+	b := m.browser
+	m.browser = nil
+	m.handle = nil
+	if m.idleTimer != nil {
+		m.idleTimer.Stop()
+		m.idleTimer = nil
 	}
+	return b
 }
 
-// NewPageWithRelease 获取一个新的页面，并返回页面和释放函数
-// 这是一个便捷方法，组合了获取浏览器和创建页面的操作
-func (m *Manager) NewPageWithRelease() (*rod.Page, func()) {
-	browser, releaseBrowser := m.AcquireBrowser()
-	
-	page := browser.NewPage()
-	
+// closeDetached 关闭已取下的浏览器实例。关闭已崩溃的浏览器可能阻塞，调用方不能持有 m.mu
+func (m *Manager) closeDetached(b *headless_browser.Browser) {
+	if b == nil {
+		return
+	}
+	logrus.Info("关闭浏览器实例...")
+	m.closeInstance(b)
+}
+
+// NewPageWithRelease 按 op 的配额申请一个新的页面，并返回页面和释放函数
+// 同一个 Chrome 进程中可以同时存在多个页面，配额用尽时阻塞等待；
+// ctx 被取消时返回 ctx.Err()，等待超时返回 *BusyError。
+// 浏览器已崩溃或连接断开时会自动丢弃并重新启动（重新加载 cookies）
+func (m *Manager) NewPageWithRelease(ctx context.Context, op Op) (*rod.Page, func(), error) {
+	m.mu.Lock()
+	ticket, err := m.acquireSlot(ctx, op)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, nil, err
+	}
+	m.mu.Unlock()
+
+	page, err := m.openPage(ctx)
+	if err != nil {
+		m.mu.Lock()
+		m.releaseSlot(ticket)
+		m.mu.Unlock()
+		return nil, nil, err
+	}
+
 	// 配置页面（应用 UA 修复等）
 	ConfigurePage(page)
-	
-	// 组合释放函数：先关闭页面，再释放浏览器
+
+	logging.From(ctx).Debugf("页面已分配给操作 %s(%s)", op.Name, op.Kind)
+
+	// 组合释放函数：先关闭页面，再归还配额
+	var once sync.Once
 	release := func() {
-		if page != nil {
-			page.Close()
-		}
-		releaseBrowser()
+		once.Do(func() {
+			if page != nil {
+				page.Close()
+			}
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.releaseSlot(ticket)
+		})
 	}
-	
-	return page, release
-}
 
+	return page, release, nil
+}
@@ -0,0 +1,158 @@
+package browser
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/headless_browser"
+)
+
+func TestPoolConfigNormalize(t *testing.T) {
+	cfg := PoolConfig{MaxPages: 2, MaxReadPages: 5, MaxWritePages: 0}.normalize()
+
+	assert.Equal(t, 2, cfg.MaxPages)
+	assert.Equal(t, 2, cfg.MaxReadPages)
+	assert.Equal(t, 2, cfg.MaxWritePages)
+
+	assert.Equal(t, DefaultPoolConfig(), PoolConfig{}.normalize())
+}
+
+func TestManagerCanAcquire(t *testing.T) {
+	m := newManager()
+	m.pool = PoolConfig{MaxPages: 3, MaxReadPages: 2, MaxWritePages: 1}
+
+	read := Op{Name: "read", Kind: OpRead}
+	write := Op{Name: "write", Kind: OpWrite}
+	publish := Op{Name: "publish", Kind: OpRead, Serial: "publish"}
+	exclusive := Op{Name: "exclusive", Kind: OpExclusive}
+
+	ctx := context.Background()
+	acquire := func(op Op) uint64 {
+		ticket, err := m.acquireSlot(ctx, op)
+		require.NoError(t, err)
+		return ticket
+	}
+
+	w := acquire(write)
+	assert.False(t, m.canAcquire(write), "写操作配额已满")
+	assert.True(t, m.canAcquire(read), "读操作不受写操作影响")
+	assert.False(t, m.canAcquire(exclusive), "有页面占用时不能独占")
+
+	p := acquire(publish)
+	assert.False(t, m.canAcquire(publish), "相同串行分组不能并发")
+	assert.True(t, m.canAcquire(read))
+
+	r := acquire(read)
+	assert.False(t, m.canAcquire(read), "总页面数已满")
+
+	m.releaseSlot(w)
+	m.releaseSlot(p)
+	m.releaseSlot(r)
+	assert.True(t, m.canAcquire(exclusive))
+
+	e := acquire(exclusive)
+	assert.False(t, m.canAcquire(read), "独占期间不分配页面")
+	m.releaseSlot(e)
+	assert.True(t, m.canAcquire(read))
+}
+
+func TestManagerAcquireSlotBusyAndCancel(t *testing.T) {
+	m := newManager()
+	m.pool = PoolConfig{MaxPages: 1, MaxReadPages: 1, MaxWritePages: 1}
+	m.acquireTimeout = 50 * time.Millisecond
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	running := Op{Name: "browse_recommendations", Kind: OpWrite}
+	_, err := m.acquireSlot(context.Background(), running)
+	require.NoError(t, err)
+
+	_, err = m.acquireSlot(context.Background(), Op{Name: "search_feeds", Kind: OpRead})
+	require.Error(t, err)
+	assert.True(t, IsBusyError(err))
+
+	var busy *BusyError
+	require.ErrorAs(t, err, &busy)
+	assert.Equal(t, "search_feeds", busy.Op)
+	assert.Equal(t, []string{"browse_recommendations"}, busy.Running)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = m.acquireSlot(ctx, Op{Name: "get_feed_detail", Kind: OpRead})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Empty(t, m.waiting, "放弃等待的操作应从队列中移除")
+	assert.Len(t, m.running, 1)
+}
+
+func TestEnsureBrowserLaunchesOnceWithoutHoldingLock(t *testing.T) {
+	m := newManager()
+	unblock := make(chan struct{})
+	var launches atomic.Int32
+	m.launch = func(bool, string) (*headless_browser.Browser, error) {
+		launches.Add(1)
+		<-unblock
+		return &headless_browser.Browser{}, nil
+	}
+
+	var wg sync.WaitGroup
+	got := make([]*headless_browser.Browser, 3)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := m.ensureBrowser()
+			assert.NoError(t, err)
+			got[i] = b
+		}()
+	}
+
+	require.Eventually(t, func() bool { return launches.Load() == 1 }, time.Second, time.Millisecond)
+	assert.False(t, m.Status().BrowserRunning, "启动期间不持有锁，可以查询状态")
+
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, int32(1), launches.Load())
+	assert.Same(t, got[0], got[1])
+	assert.Same(t, got[0], got[2])
+	assert.True(t, m.Status().BrowserRunning)
+}
+
+func TestIdleCloseAndLazyRelaunch(t *testing.T) {
+	m := newManager()
+	var launches atomic.Int32
+	m.launch = func(bool, string) (*headless_browser.Browser, error) {
+		launches.Add(1)
+		return &headless_browser.Browser{}, nil
+	}
+	closed := make(chan *headless_browser.Browser, 1)
+	m.closeInstance = func(b *headless_browser.Browser) {
+		m.Status() // 关闭时不持有锁，否则这里会死锁
+		closed <- b
+	}
+	m.SetIdleTimeout(20 * time.Millisecond)
+
+	first, release, err := m.AcquireBrowser(context.Background())
+	require.NoError(t, err)
+	release()
+
+	select {
+	case b := <-closed:
+		assert.Same(t, first, b)
+	case <-time.After(2 * time.Second):
+		t.Fatal("空闲计时到期后没有关闭浏览器")
+	}
+	assert.False(t, m.Status().BrowserRunning)
+
+	second, release, err := m.AcquireBrowser(context.Background())
+	require.NoError(t, err)
+	defer release()
+	assert.NotSame(t, first, second, "下次申请时重新启动浏览器")
+	assert.Equal(t, int32(2), launches.Load())
+}
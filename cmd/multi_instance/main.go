@@ -98,6 +98,13 @@ func main() {
 		logrus.Warn("当前以无头模式运行，首次登录时可能无法扫码，建议第一次使用时 headless=false")
 	}
 
+	// 读取配置文件（XHS_CONFIG）与环境变量中的超时、目录等设置，命令行参数优先
+	appCfg, err := configs.Load(os.Getenv(configs.ConfigEnv), nil)
+	if err != nil {
+		logrus.Fatalf("failed to load config: %v", err)
+	}
+	configs.Set(appCfg)
+
 	// 初始化全局配置（主要是给可能复用的浏览器管理器使用）
 	configs.InitHeadless(headless)
 	configs.SetBinPath(binPath)
@@ -0,0 +1,77 @@
+package configs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// API Key 的权限范围
+const (
+	ScopeRead     = "read"     // 登录状态、列表、搜索、详情、用户主页等只读操作
+	ScopeInteract = "interact" // 点赞、收藏、评论、浏览推荐页
+	ScopePublish  = "publish"  // 发布图文、视频
+	ScopeAdmin    = "admin"    // 扫码登录、重新加载选择器、查看调试包
+)
+
+// AllScopes 所有权限范围
+var AllScopes = []string{ScopeRead, ScopeInteract, ScopePublish, ScopeAdmin}
+
+// minAPIKeyLength API Key 的最短长度
+const minAPIKeyLength = 16
+
+// AuthConfig 访问控制配置。未配置任何 API Key 时不做鉴权
+type AuthConfig struct {
+	APIKeys []APIKey `yaml:"api_keys"`
+}
+
+// APIKey 一个 API Key，客户端通过 Authorization: Bearer <key> 发送
+type APIKey struct {
+	Name   string   `yaml:"name"`   // 用于日志，不要包含敏感信息
+	Key    string   `yaml:"key"`    // 至少 16 个字符
+	Scopes []string `yaml:"scopes"` // 见 AllScopes
+}
+
+// HasScope 是否拥有指定权限
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Enabled 是否启用了 API Key 鉴权
+func (a AuthConfig) Enabled() bool {
+	return len(a.APIKeys) > 0
+}
+
+// validate 校验 API Key 的名称、长度与权限范围
+func (a AuthConfig) validate() []error {
+	var errs []error
+	names := map[string]bool{}
+	keys := map[string]bool{}
+
+	for i, k := range a.APIKeys {
+		field := fmt.Sprintf("auth.api_keys[%d]", i)
+		if k.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name 不能为空", field))
+		} else if names[k.Name] {
+			errs = append(errs, fmt.Errorf("%s.name 重复: %s", field, k.Name))
+		}
+		names[k.Name] = true
+
+		if len(k.Key) < minAPIKeyLength {
+			errs = append(errs, fmt.Errorf("%s.key 至少需要 %d 个字符", field, minAPIKeyLength))
+		} else if keys[k.Key] {
+			errs = append(errs, fmt.Errorf("%s.key 与其他 API Key 重复", field))
+		}
+		keys[k.Key] = true
+
+		if len(k.Scopes) == 0 {
+			errs = append(errs, fmt.Errorf("%s.scopes 不能为空", field))
+		}
+		for _, s := range k.Scopes {
+			if !slices.Contains(AllScopes, s) {
+				errs = append(errs, fmt.Errorf("%s.scopes 包含未知的权限 %q，可选值: %s", field, s, strings.Join(AllScopes, ", ")))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,414 @@
+package configs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 推荐页浏览的默认参数
+const (
+	DefaultBrowseDurationMinutes = 60
+	DefaultMinScrollsPerRound    = 1
+	DefaultMaxScrollsPerRound    = 3
+	DefaultClickProbability      = 100
+	DefaultInteractProbability   = 60
+	DefaultLikeOnlyProbability   = 30
+)
+
+// 小红书站点的默认地址
+const (
+	DefaultSiteOrigin    = "https://www.xiaohongshu.com"
+	DefaultCreatorOrigin = "https://creator.xiaohongshu.com"
+)
+
+// defaultJobsDir 后台任务记录的默认目录
+var defaultJobsDir = filepath.Join(os.TempDir(), "xiaohongshu_jobs")
+
+// defaultRateLimitFile 写操作计数的默认保存文件
+var defaultRateLimitFile = filepath.Join(os.TempDir(), "xiaohongshu_rate_limits.json")
+
+// defaultIdempotencyFile 幂等请求记录的默认保存文件
+var defaultIdempotencyFile = filepath.Join(os.TempDir(), "xiaohongshu_idempotency.json")
+
+// ConfigEnv 指定配置文件路径的环境变量
+const ConfigEnv = "XHS_CONFIG"
+
+// Config 服务的全部配置。加载顺序：内置默认值 < 配置文件 < 环境变量 < 命令行参数
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Log         LogConfig         `yaml:"log"`
+	Browser     BrowserConfig     `yaml:"browser"`
+	Paths       PathsConfig       `yaml:"paths"`
+	Site        SiteConfig        `yaml:"site"`
+	Timeouts    TimeoutsConfig    `yaml:"timeouts"`
+	Browse      BrowseConfig      `yaml:"browse"`
+	Auth        AuthConfig        `yaml:"auth"`
+	RateLimits  RateLimitConfig   `yaml:"rate_limits"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Resources   ResourcesConfig   `yaml:"resources"`
+	Confirm     ConfirmConfig     `yaml:"confirm"`
+}
+
+// MCP 服务的传输方式：http 监听 Port 提供 Streamable HTTP 与 REST API，
+// stdio 由 MCP 客户端启动进程，通过标准输入输出通信
+const (
+	TransportHTTP  = "http"
+	TransportStdio = "stdio"
+)
+
+// Transports 可选的传输方式
+var Transports = []string{TransportHTTP, TransportStdio}
+
+// ServerConfig 服务配置
+type ServerConfig struct {
+	Transport       string        `yaml:"transport"`        // 传输方式，见 Transports
+	Port            string        `yaml:"port"`             // 监听地址，如 :18060
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // 优雅关闭时等待连接结束的最长时间
+	CORSOrigins     []string      `yaml:"cors_origins"`     // 允许跨域访问的来源，"*" 表示任意来源，为空表示不允许跨域
+}
+
+// 可选的日志级别与格式
+var (
+	LogLevels  = []string{"trace", "debug", "info", "warn", "error"}
+	LogFormats = []string{"text", "json"}
+)
+
+// LogConfig 日志输出
+type LogConfig struct {
+	Level  string `yaml:"level"`  // 日志级别，见 LogLevels
+	Format string `yaml:"format"` // text 或 json，json 格式每行一条记录，便于日志系统按 request_id、job_id 检索
+	File   string `yaml:"file"`   // 日志文件路径（追加写入），为空时输出到标准错误
+}
+
+// BrowserConfig 浏览器与页面池配置
+type BrowserConfig struct {
+	Headless       bool          `yaml:"headless"`
+	BinPath        string        `yaml:"bin_path"`        // 浏览器二进制文件路径，为空时自动查找
+	MaxPages       int           `yaml:"max_pages"`       // 同一时间最多打开的页面数
+	MaxReadPages   int           `yaml:"max_read_pages"`  // 同一时间最多执行的只读操作数
+	MaxWritePages  int           `yaml:"max_write_pages"` // 同一时间最多执行的写操作数
+	AcquireTimeout time.Duration `yaml:"acquire_timeout"` // 等待空闲页面的最长时间
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`    // 空闲多久后关闭浏览器，0 表示不关闭
+}
+
+// PathsConfig 数据文件与目录
+type PathsConfig struct {
+	CookiesFile     string `yaml:"cookies_file"`      // 为空时沿用旧的查找规则（/tmp/cookies.json 或当前目录）
+	ImagesDir       string `yaml:"images_dir"`        // 下载网络图片的目录
+	DebugDir        string `yaml:"debug_dir"`         // 调试包目录，为空表示不保存
+	DebugMaxBundles int    `yaml:"debug_max_bundles"` // 最多保留的调试包数量，超出时删除最旧的，0 表示不限制
+	SelectorsFile   string `yaml:"selectors_file"`    // 选择器文件，为空时使用内置选择器
+	JobsDir         string `yaml:"jobs_dir"`          // 后台任务记录目录，为空表示只保存在内存中
+	RateLimitFile   string `yaml:"rate_limit_file"`   // 写操作计数的保存文件，为空表示只保存在内存中
+	IdempotencyFile string `yaml:"idempotency_file"`  // 幂等请求记录的保存文件，为空表示只保存在内存中
+}
+
+// SiteConfig 小红书站点地址
+type SiteConfig struct {
+	Origin        string `yaml:"origin"`
+	CreatorOrigin string `yaml:"creator_origin"`
+}
+
+// TimeoutsConfig 页面操作超时
+type TimeoutsConfig struct {
+	Action      time.Duration `yaml:"action"`       // 读取、点赞、收藏、评论等普通操作
+	Publish     time.Duration `yaml:"publish"`      // 图文发布（含打开发布页与提交）
+	ImageUpload time.Duration `yaml:"image_upload"` // 等待图片上传完成
+	VideoUpload time.Duration `yaml:"video_upload"` // 等待视频上传与处理完成
+}
+
+// IdempotencyConfig 发布、评论请求的 idempotency_key
+type IdempotencyConfig struct {
+	Window time.Duration `yaml:"window"` // 记录的有效期，有效期内相同 key 的请求返回首次执行的结果
+}
+
+// ResourcesConfig MCP 资源（xhs://note/...、xhs://user/...、xhs://feed/home）
+type ResourcesConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl"` // 读取结果的缓存时间，有效期内重复读取不再打开页面，0 表示不缓存
+}
+
+// ConfirmConfig 发布前的用户确认
+type ConfirmConfig struct {
+	Publish bool          `yaml:"publish"` // MCP 调用发布工具时，点击发布前通过 elicitation 请求用户确认
+	Timeout time.Duration `yaml:"timeout"` // 等待确认的最长时间，超时视为未确认
+}
+
+// BrowseConfig 推荐页浏览的默认参数，请求中未指定的字段使用这里的值
+type BrowseConfig struct {
+	DurationMinutes     int  `yaml:"duration_minutes"`
+	MinScrolls          int  `yaml:"min_scrolls"`
+	MaxScrolls          int  `yaml:"max_scrolls"`
+	ClickProbability    int  `yaml:"click_probability"`
+	InteractProbability int  `yaml:"interact_probability"`
+	LikeOnlyProbability int  `yaml:"like_only_probability"`
+	TenTimesForceAll    bool `yaml:"ten_times_force_all"` // 对所有可见笔记执行十次互动
+}
+
+// Default 返回内置默认配置
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Transport:       TransportHTTP,
+			Port:            ":18060",
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Browser: BrowserConfig{
+			Headless:       true,
+			MaxPages:       4,
+			MaxReadPages:   3,
+			MaxWritePages:  2,
+			AcquireTimeout: 3 * time.Minute,
+			IdleTimeout:    10 * time.Minute,
+		},
+		Paths: PathsConfig{
+			ImagesDir:       defaultImagesPath,
+			DebugDir:        defaultDebugDir,
+			DebugMaxBundles: DefaultDebugMaxBundles,
+			JobsDir:         defaultJobsDir,
+			RateLimitFile:   defaultRateLimitFile,
+			IdempotencyFile: defaultIdempotencyFile,
+		},
+		Site: SiteConfig{
+			Origin:        DefaultSiteOrigin,
+			CreatorOrigin: DefaultCreatorOrigin,
+		},
+		Timeouts: TimeoutsConfig{
+			Action:      60 * time.Second,
+			Publish:     180 * time.Second,
+			ImageUpload: 60 * time.Second,
+			VideoUpload: 5 * time.Minute,
+		},
+		Browse: BrowseConfig{
+			DurationMinutes:     DefaultBrowseDurationMinutes,
+			MinScrolls:          DefaultMinScrollsPerRound,
+			MaxScrolls:          DefaultMaxScrollsPerRound,
+			ClickProbability:    DefaultClickProbability,
+			InteractProbability: DefaultInteractProbability,
+			LikeOnlyProbability: DefaultLikeOnlyProbability,
+		},
+		RateLimits: defaultRateLimits(),
+		Idempotency: IdempotencyConfig{
+			Window: 24 * time.Hour,
+		},
+		Resources: ResourcesConfig{
+			CacheTTL: 2 * time.Minute,
+		},
+		Confirm: ConfirmConfig{
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// Load 依次应用默认值、配置文件（path 为空时跳过）、环境变量和命令行参数（flags 为 nil 时跳过），
+// 全部覆盖之后再校验结果
+func Load(path string, flags *Flags) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	if flags != nil {
+		if err := flags.Apply(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFile 读取 YAML 配置文件，文件中出现的字段覆盖当前值，未知字段视为错误
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// EnvAPIKeyName 通过 XHS_API_KEY 环境变量添加的 API Key 的名称，拥有全部权限
+const EnvAPIKeyName = "env"
+
+// ApplyEnv 用环境变量覆盖配置，兼容旧的 ROD_BROWSER_BIN、COOKIES_PATH、TEN_TIMES_FORCE_ALL
+func (c *Config) ApplyEnv() error {
+	var errs []error
+
+	envString("XHS_TRANSPORT", &c.Server.Transport)
+	envString("XHS_PORT", &c.Server.Port)
+	envString("XHS_LOG_LEVEL", &c.Log.Level)
+	envString("XHS_LOG_FORMAT", &c.Log.Format)
+	envString("XHS_LOG_FILE", &c.Log.File)
+	errs = append(errs, envBool("XHS_HEADLESS", &c.Browser.Headless))
+	envString("ROD_BROWSER_BIN", &c.Browser.BinPath)
+	envString("XHS_BROWSER_BIN", &c.Browser.BinPath)
+	envString("COOKIES_PATH", &c.Paths.CookiesFile)
+	envString("XHS_IMAGES_DIR", &c.Paths.ImagesDir)
+	envString("XHS_DEBUG_DIR", &c.Paths.DebugDir)
+	envString("XHS_SELECTORS_FILE", &c.Paths.SelectorsFile)
+	envString("XHS_JOBS_DIR", &c.Paths.JobsDir)
+	envString("XHS_RATE_LIMIT_FILE", &c.Paths.RateLimitFile)
+	envString("XHS_IDEMPOTENCY_FILE", &c.Paths.IdempotencyFile)
+	envString("XHS_SITE_ORIGIN", &c.Site.Origin)
+	envString("XHS_CREATOR_ORIGIN", &c.Site.CreatorOrigin)
+	errs = append(errs, envBool("TEN_TIMES_FORCE_ALL", &c.Browse.TenTimesForceAll))
+	errs = append(errs, envBool("XHS_CONFIRM_PUBLISH", &c.Confirm.Publish))
+
+	if v := strings.TrimSpace(os.Getenv("XHS_CORS_ORIGINS")); v != "" {
+		c.Server.CORSOrigins = splitList(v)
+	}
+	if v := strings.TrimSpace(os.Getenv("XHS_API_KEY")); v != "" {
+		c.Auth.APIKeys = append(c.Auth.APIKeys, APIKey{Name: EnvAPIKeyName, Key: v, Scopes: AllScopes})
+	}
+
+	return errors.Join(errs...)
+}
+
+func envString(name string, dst *string) {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		*dst = v
+	}
+}
+
+// splitList 按逗号拆分列表并去掉空白项
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envBool(name string, dst *bool) error {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
+	switch v {
+	case "":
+		return nil
+	case "1", "true", "yes", "on":
+		*dst = true
+	case "0", "false", "no", "off":
+		*dst = false
+	default:
+		return fmt.Errorf("环境变量 %s 的值无效: %q", name, v)
+	}
+	return nil
+}
+
+// Validate 校验配置，返回所有不合法的字段
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(slices.Contains(Transports, c.Server.Transport), "server.transport 必须是 %s 之一: %q", strings.Join(Transports, "、"), c.Server.Transport)
+	if _, _, err := net.SplitHostPort(c.Server.Port); err != nil {
+		errs = append(errs, fmt.Errorf("server.port 无效: %q", c.Server.Port))
+	}
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout 必须大于 0")
+	for _, origin := range c.Server.CORSOrigins {
+		check(origin == "*" || isHTTPOrigin(origin), "server.cors_origins 必须是 \"*\" 或 http(s) 地址: %q", origin)
+	}
+
+	check(slices.Contains(LogLevels, c.Log.Level), "log.level 必须是 %s 之一: %q", strings.Join(LogLevels, "、"), c.Log.Level)
+	check(slices.Contains(LogFormats, c.Log.Format), "log.format 必须是 %s 之一: %q", strings.Join(LogFormats, "、"), c.Log.Format)
+
+	b := c.Browser
+	check(b.MaxPages > 0, "browser.max_pages 必须大于 0")
+	check(b.MaxReadPages > 0 && b.MaxReadPages <= b.MaxPages, "browser.max_read_pages 必须在 1 到 max_pages 之间")
+	check(b.MaxWritePages > 0 && b.MaxWritePages <= b.MaxPages, "browser.max_write_pages 必须在 1 到 max_pages 之间")
+	check(b.AcquireTimeout > 0, "browser.acquire_timeout 必须大于 0")
+	check(b.IdleTimeout >= 0, "browser.idle_timeout 不能为负数")
+
+	check(isHTTPOrigin(c.Site.Origin), "site.origin 必须是 http(s) 地址: %q", c.Site.Origin)
+	check(isHTTPOrigin(c.Site.CreatorOrigin), "site.creator_origin 必须是 http(s) 地址: %q", c.Site.CreatorOrigin)
+
+	t := c.Timeouts
+	check(t.Action > 0, "timeouts.action 必须大于 0")
+	check(t.Publish > 0, "timeouts.publish 必须大于 0")
+	check(t.ImageUpload > 0, "timeouts.image_upload 必须大于 0")
+	check(t.VideoUpload > 0, "timeouts.video_upload 必须大于 0")
+
+	br := c.Browse
+	check(br.DurationMinutes > 0, "browse.duration_minutes 必须大于 0")
+	check(br.MinScrolls > 0 && br.MinScrolls <= br.MaxScrolls, "browse.min_scrolls 必须大于 0 且不超过 max_scrolls")
+	check(isProbability(br.ClickProbability), "browse.click_probability 必须在 0 到 100 之间")
+	check(isProbability(br.InteractProbability), "browse.interact_probability 必须在 0 到 100 之间")
+	check(isProbability(br.LikeOnlyProbability), "browse.like_only_probability 必须在 0 到 100 之间")
+
+	check(c.Paths.DebugMaxBundles >= 0, "paths.debug_max_bundles 不能为负数")
+	check(c.Idempotency.Window > 0, "idempotency.window 必须大于 0")
+	check(c.Resources.CacheTTL >= 0, "resources.cache_ttl 不能为负数")
+	check(c.Confirm.Timeout > 0, "confirm.timeout 必须大于 0")
+
+	errs = append(errs, c.Auth.validate()...)
+	errs = append(errs, c.RateLimits.validate()...)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("配置无效: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func isHTTPOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isProbability(p int) bool {
+	return p >= 0 && p <= 100
+}
+
+var (
+	currentMu sync.RWMutex
+	current   = Default()
+)
+
+// Set 设置当前生效的配置，并同步到 headless、浏览器路径、图片与调试包目录等全局设置
+func Set(cfg *Config) {
+	currentMu.Lock()
+	current = cfg
+	currentMu.Unlock()
+
+	InitHeadless(cfg.Browser.Headless)
+	SetBinPath(cfg.Browser.BinPath)
+	SetImagesPath(cfg.Paths.ImagesDir)
+	SetDebugDir(cfg.Paths.DebugDir)
+}
+
+// Get 返回当前生效的配置副本
+func Get() Config {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return *current
+}
@@ -0,0 +1,176 @@
+package configs
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	assert.NoError(t, Default().Validate())
+}
+
+func TestLoadFileEnvAndFlags(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  port: ":8080"
+browser:
+  headless: false
+  max_pages: 6
+  acquire_timeout: 30s
+paths:
+  cookies_file: /data/cookies.json
+timeouts:
+  publish: 5m
+browse:
+  duration_minutes: 15
+`)
+	t.Setenv("XHS_PORT", ":9090")
+	t.Setenv("TEN_TIMES_FORCE_ALL", "1")
+
+	cfg, err := Load(path, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9090", cfg.Server.Port, "环境变量覆盖配置文件")
+	assert.False(t, cfg.Browser.Headless)
+	assert.Equal(t, 6, cfg.Browser.MaxPages)
+	assert.Equal(t, 3, cfg.Browser.MaxReadPages, "未出现的字段保持默认值")
+	assert.Equal(t, 30*time.Second, cfg.Browser.AcquireTimeout)
+	assert.Equal(t, "/data/cookies.json", cfg.Paths.CookiesFile)
+	assert.Equal(t, 5*time.Minute, cfg.Timeouts.Publish)
+	assert.Equal(t, 15, cfg.Browse.DurationMinutes)
+	assert.True(t, cfg.Browse.TenTimesForceAll)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	BindFlags(fs, cfg)
+	require.NoError(t, fs.Parse([]string{"-port", ":7070", "-headless"}))
+
+	assert.Equal(t, ":7070", cfg.Server.Port, "命令行参数优先级最高")
+	assert.True(t, cfg.Browser.Headless)
+	assert.Equal(t, 6, cfg.Browser.MaxPages, "未指定的参数不覆盖配置")
+}
+
+func TestParseFlagsApply(t *testing.T) {
+	path := writeConfigFile(t, "server:\n  port: \":9090\"\nbrowser:\n  max_pages: 6\n")
+	t.Setenv(ConfigEnv, "")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := ParseFlags(fs, []string{"-config", path, "-max-read-pages", "5", "-cors-origins", "http://a.com, http://b.com", "-headless=false"})
+	require.NoError(t, err)
+	assert.Equal(t, path, flags.ConfigPath)
+
+	cfg, err := Load(flags.ConfigPath, flags)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9090", cfg.Server.Port, "未指定的参数不覆盖配置文件")
+	assert.Equal(t, 6, cfg.Browser.MaxPages)
+	assert.Equal(t, 5, cfg.Browser.MaxReadPages)
+	assert.False(t, cfg.Browser.Headless)
+	assert.Equal(t, []string{"http://a.com", "http://b.com"}, cfg.Server.CORSOrigins)
+}
+
+func TestLoadValidatesAfterFlags(t *testing.T) {
+	path := writeConfigFile(t, "browser:\n  max_pages: 2\n  max_read_pages: 3\n")
+	t.Setenv(ConfigEnv, "")
+
+	flags, err := ParseFlags(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path, "-max-pages", "4"})
+	require.NoError(t, err)
+	cfg, err := Load(flags.ConfigPath, flags)
+	require.NoError(t, err, "命令行参数修正配置文件之后再校验")
+	assert.Equal(t, 4, cfg.Browser.MaxPages)
+
+	flags, err = ParseFlags(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-max-read-pages", "9"})
+	require.NoError(t, err)
+	_, err = Load("", flags)
+	assert.Error(t, err, "命令行参数同样需要校验")
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown field", "browser:\n  headles: false\n"},
+		{"bad duration", "browser:\n  acquire_timeout: soon\n"},
+		{"read pages exceed total", "browser:\n  max_pages: 2\n  max_read_pages: 3\n"},
+		{"bad origin", "site:\n  origin: www.xiaohongshu.com\n"},
+		{"bad probability", "browse:\n  click_probability: 120\n"},
+		{"bad port", "server:\n  port: \"18060\"\n"},
+		{"bad transport", "server:\n  transport: sse\n"},
+		{"bad cors origin", "server:\n  cors_origins: [example.com]\n"},
+		{"zero idempotency window", "idempotency:\n  window: 0s\n"},
+		{"negative resource cache ttl", "resources:\n  cache_ttl: -1s\n"},
+		{"zero confirm timeout", "confirm:\n  timeout: 0s\n"},
+		{"bad log level", "log:\n  level: verbose\n"},
+		{"bad log format", "log:\n  format: xml\n"},
+		{"short api key", "auth:\n  api_keys:\n    - {name: bot, key: short, scopes: [read]}\n"},
+		{"unknown scope", "auth:\n  api_keys:\n    - {name: bot, key: 0123456789abcdef, scopes: [write]}\n"},
+		{"missing scopes", "auth:\n  api_keys:\n    - {name: bot, key: 0123456789abcdef}\n"},
+		{"negative rate limit", "rate_limits:\n  like: {per_day: -1}\n"},
+		{"rate limit without window", "rate_limits:\n  comment: {window: 0s, per_window: 10}\n"},
+		{"duplicate name", "auth:\n  api_keys:\n    - {name: bot, key: 0123456789abcdef, scopes: [read]}\n    - {name: bot, key: fedcba9876543210, scopes: [read]}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfigFile(t, tt.content), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadRejectsInvalidEnv(t *testing.T) {
+	t.Setenv("XHS_HEADLESS", "maybe")
+
+	_, err := Load("", nil)
+	assert.Error(t, err)
+}
+
+func TestLoadAuthAndCORS(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  cors_origins: ["https://app.example.com"]
+auth:
+  api_keys:
+    - name: reader
+      key: 0123456789abcdef
+      scopes: [read]
+`)
+	t.Setenv("XHS_API_KEY", "env-key-0123456789")
+	t.Setenv("XHS_CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := Load(path, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.Server.CORSOrigins)
+	require.Len(t, cfg.Auth.APIKeys, 2)
+	assert.True(t, cfg.Auth.APIKeys[0].HasScope(ScopeRead))
+	assert.False(t, cfg.Auth.APIKeys[0].HasScope(ScopePublish))
+	assert.Equal(t, EnvAPIKeyName, cfg.Auth.APIKeys[1].Name)
+	assert.Equal(t, AllScopes, cfg.Auth.APIKeys[1].Scopes, "环境变量中的 Key 拥有全部权限")
+}
+
+func TestLoadRateLimitsKeepsDefaults(t *testing.T) {
+	cfg, err := Load(writeConfigFile(t, "rate_limits:\n  comment:\n    per_day: 10\n"), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, cfg.RateLimits.Comment.PerDay)
+	assert.Equal(t, Default().RateLimits.Comment.PerWindow, cfg.RateLimits.Comment.PerWindow, "未指定的字段保留默认值")
+	assert.Equal(t, Default().RateLimits.Publish, cfg.RateLimits.Publish)
+}
+
+func TestExampleConfigIsValid(t *testing.T) {
+	cfg := Default()
+	require.NoError(t, cfg.LoadFile("config.example.yaml"))
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,28 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	DebugDir = "xiaohongshu_debug"
+
+	// DefaultDebugMaxBundles 默认最多保留的调试包数量
+	DefaultDebugMaxBundles = 50
+)
+
+var (
+	defaultDebugDir = filepath.Join(os.TempDir(), DebugDir)
+	debugDir        = defaultDebugDir
+)
+
+// SetDebugDir 设置操作失败时保存调试包的目录，为空时不保存。
+func SetDebugDir(dir string) {
+	debugDir = dir
+}
+
+// GetDebugDir 返回调试包目录，默认位于系统临时目录下。
+func GetDebugDir() string {
+	return debugDir
+}
@@ -0,0 +1,93 @@
+package configs
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// Flags 解析后的命令行参数
+type Flags struct {
+	ConfigPath string // -config 指定的配置文件路径，未指定时取环境变量 XHS_CONFIG
+
+	fs *flag.FlagSet
+}
+
+// ParseFlags 解析命令行参数（只解析一次），参数先写入一份默认配置用于校验格式，
+// 加载配置文件与环境变量后再通过 Apply 覆盖到最终的配置上
+func ParseFlags(fs *flag.FlagSet, args []string) (*Flags, error) {
+	f := &Flags{ConfigPath: os.Getenv(ConfigEnv), fs: fs}
+	fs.StringVar(&f.ConfigPath, "config", f.ConfigPath, "配置文件路径（YAML），也可通过环境变量 XHS_CONFIG 指定")
+	BindFlags(fs, Default())
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Apply 将命令行中显式出现的参数覆盖到 cfg 上
+func (f *Flags) Apply(cfg *Config) error {
+	target := flag.NewFlagSet(f.fs.Name(), flag.ContinueOnError)
+	BindFlags(target, cfg)
+
+	var err error
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "config" || err != nil {
+			return
+		}
+		err = target.Set(fl.Name, fl.Value.String())
+	})
+	return err
+}
+
+// listValue 逗号分隔的列表参数
+type listValue []string
+
+func (l *listValue) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(v string) error {
+	*l = splitList(v)
+	return nil
+}
+
+// BindFlags 将命令行参数绑定到 cfg 的字段上，参数的默认值取自 cfg 当前的值。
+// 只有命令行中显式出现的参数才会覆盖 cfg
+func BindFlags(fs *flag.FlagSet, cfg *Config) {
+	fs.StringVar(&cfg.Server.Transport, "transport", cfg.Server.Transport, "MCP 传输方式：http（Streamable HTTP 与 REST API）或 stdio（由 MCP 客户端启动，通过标准输入输出通信）")
+	fs.StringVar(&cfg.Server.Port, "port", cfg.Server.Port, "端口")
+	fs.Var((*listValue)(&cfg.Server.CORSOrigins), "cors-origins", "允许跨域访问的来源，逗号分隔，\"*\" 表示任意来源")
+
+	fs.StringVar(&cfg.Log.Level, "log-level", cfg.Log.Level, "日志级别：trace、debug、info、warn、error")
+	fs.StringVar(&cfg.Log.Format, "log-format", cfg.Log.Format, "日志格式：text 或 json")
+	fs.StringVar(&cfg.Log.File, "log-file", cfg.Log.File, "日志文件路径（追加写入），为空时输出到标准错误")
+
+	fs.BoolVar(&cfg.Browser.Headless, "headless", cfg.Browser.Headless, "是否无头模式")
+	fs.StringVar(&cfg.Browser.BinPath, "bin", cfg.Browser.BinPath, "浏览器二进制文件路径")
+	fs.IntVar(&cfg.Browser.MaxPages, "max-pages", cfg.Browser.MaxPages, "同一时间最多打开的浏览器页面数")
+	fs.IntVar(&cfg.Browser.MaxReadPages, "max-read-pages", cfg.Browser.MaxReadPages, "同一时间最多执行的只读操作数")
+	fs.IntVar(&cfg.Browser.MaxWritePages, "max-write-pages", cfg.Browser.MaxWritePages, "同一时间最多执行的写操作数（发布、评论、点赞等）")
+	fs.DurationVar(&cfg.Browser.AcquireTimeout, "acquire-timeout", cfg.Browser.AcquireTimeout, "等待空闲浏览器页面的最长时间，超时返回繁忙错误")
+	fs.DurationVar(&cfg.Browser.IdleTimeout, "idle-timeout", cfg.Browser.IdleTimeout, "浏览器空闲多久后自动关闭（下次请求时重新启动），0 表示不自动关闭")
+
+	fs.StringVar(&cfg.Paths.CookiesFile, "cookies", cfg.Paths.CookiesFile, "cookies 文件路径，为空时使用 /tmp/cookies.json（如存在）或当前目录下的 cookies.json")
+	fs.StringVar(&cfg.Paths.ImagesDir, "images-dir", cfg.Paths.ImagesDir, "下载网络图片的目录")
+	fs.StringVar(&cfg.Paths.DebugDir, "debug-dir", cfg.Paths.DebugDir, "操作失败时保存调试包（截图、HTML、控制台输出等）的目录，为空表示不保存")
+	fs.IntVar(&cfg.Paths.DebugMaxBundles, "debug-max-bundles", cfg.Paths.DebugMaxBundles, "最多保留的调试包数量，超出时删除最旧的，0 表示不限制")
+	fs.StringVar(&cfg.Paths.SelectorsFile, "selectors", cfg.Paths.SelectorsFile, "选择器文件路径（.json/.yaml），用于覆盖内置的页面选择器，可通过 POST /api/v1/selectors/reload 重新加载")
+	fs.StringVar(&cfg.Paths.JobsDir, "jobs-dir", cfg.Paths.JobsDir, "后台任务记录目录，服务重启后仍可查询任务状态，为空表示只保存在内存中")
+	fs.StringVar(&cfg.Paths.RateLimitFile, "rate-limit-file", cfg.Paths.RateLimitFile, "写操作（发布、评论、点赞、收藏）计数的保存文件，服务重启后频率限制继续生效，为空表示只保存在内存中")
+	fs.StringVar(&cfg.Paths.IdempotencyFile, "idempotency-file", cfg.Paths.IdempotencyFile, "发布、评论请求的 idempotency_key 及其结果的保存文件，服务重启后重复的请求仍返回首次执行的结果，为空表示只保存在内存中")
+	fs.DurationVar(&cfg.Idempotency.Window, "idempotency-window", cfg.Idempotency.Window, "idempotency_key 的有效期，有效期内相同 key 的请求返回首次执行的结果")
+	fs.BoolVar(&cfg.Confirm.Publish, "confirm-publish", cfg.Confirm.Publish, "MCP 调用发布工具时，点击发布前通过 elicitation 向用户展示标题、正文、标签与图片并请求确认")
+	fs.DurationVar(&cfg.Confirm.Timeout, "confirm-timeout", cfg.Confirm.Timeout, "等待用户确认发布的最长时间，超时视为未确认")
+	fs.DurationVar(&cfg.Resources.CacheTTL, "resource-cache-ttl", cfg.Resources.CacheTTL, "MCP 资源读取结果的缓存时间，有效期内重复读取同一资源不再打开页面，0 表示不缓存")
+
+	fs.StringVar(&cfg.Site.Origin, "site-origin", cfg.Site.Origin, "小红书主站地址，测试时可指向本地 fixture server")
+	fs.StringVar(&cfg.Site.CreatorOrigin, "creator-origin", cfg.Site.CreatorOrigin, "小红书创作者中心地址")
+}
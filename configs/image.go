@@ -9,6 +9,19 @@ const (
 	ImagesDir = "xiaohongshu_images"
 )
 
+var (
+	defaultImagesPath = filepath.Join(os.TempDir(), ImagesDir)
+	imagesPath        = defaultImagesPath
+)
+
+// SetImagesPath 设置下载网络图片的目录，为空时使用系统临时目录下的默认目录。
+func SetImagesPath(dir string) {
+	if dir == "" {
+		dir = defaultImagesPath
+	}
+	imagesPath = dir
+}
+
 func GetImagesPath() string {
-	return filepath.Join(os.TempDir(), ImagesDir)
+	return imagesPath
 }
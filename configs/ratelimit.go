@@ -0,0 +1,63 @@
+package configs
+
+import (
+	"fmt"
+	"time"
+)
+
+// 受频率限制的写操作
+const (
+	ActionPublish  = "publish"  // 发布图文或视频
+	ActionComment  = "comment"  // 发表评论
+	ActionLike     = "like"     // 点赞或取消点赞
+	ActionFavorite = "favorite" // 收藏或取消收藏
+)
+
+// ActionLimit 一类写操作的额度，各项为 0 表示不限制
+type ActionLimit struct {
+	Window      time.Duration `yaml:"window"`       // 滚动窗口的长度
+	PerWindow   int           `yaml:"per_window"`   // 滚动窗口内最多执行的次数
+	PerDay      int           `yaml:"per_day"`      // 每个自然日最多执行的次数
+	MinInterval time.Duration `yaml:"min_interval"` // 两次操作之间的最小间隔
+}
+
+// RateLimitConfig 写操作的频率限制，按 API Key 分别计数（未启用鉴权时所有调用共用一份额度）
+type RateLimitConfig struct {
+	Publish  ActionLimit `yaml:"publish"`
+	Comment  ActionLimit `yaml:"comment"`
+	Like     ActionLimit `yaml:"like"`
+	Favorite ActionLimit `yaml:"favorite"`
+}
+
+// Limits 按操作类型返回额度
+func (c RateLimitConfig) Limits() map[string]ActionLimit {
+	return map[string]ActionLimit{
+		ActionPublish:  c.Publish,
+		ActionComment:  c.Comment,
+		ActionLike:     c.Like,
+		ActionFavorite: c.Favorite,
+	}
+}
+
+// defaultRateLimits 默认额度，按普通用户的操作频率设置
+func defaultRateLimits() RateLimitConfig {
+	return RateLimitConfig{
+		Publish:  ActionLimit{Window: time.Hour, PerWindow: 5, PerDay: 20, MinInterval: time.Minute},
+		Comment:  ActionLimit{Window: time.Hour, PerWindow: 20, PerDay: 100, MinInterval: 30 * time.Second},
+		Like:     ActionLimit{Window: time.Hour, PerWindow: 60, PerDay: 300, MinInterval: 5 * time.Second},
+		Favorite: ActionLimit{Window: time.Hour, PerWindow: 60, PerDay: 300, MinInterval: 5 * time.Second},
+	}
+}
+
+func (c RateLimitConfig) validate() []error {
+	var errs []error
+	for action, l := range c.Limits() {
+		if l.Window < 0 || l.PerWindow < 0 || l.PerDay < 0 || l.MinInterval < 0 {
+			errs = append(errs, fmt.Errorf("rate_limits.%s 的各项不能为负数", action))
+		}
+		if l.PerWindow > 0 && l.Window <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limits.%s.window 必须大于 0（已设置 per_window）", action))
+		}
+	}
+	return errs
+}
@@ -1,6 +1,7 @@
 package cookies
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -63,10 +64,23 @@ func GetInstanceCookiesFilePath(instance string) string {
 	return filepath.Join(dir, fmt.Sprintf("%s_%s%s", name, instance, ext))
 }
 
+// cookiesFilePath 通过配置文件显式指定的 cookies 文件路径
+var cookiesFilePath string
+
+// SetCookiesFilePath 指定 cookies 文件路径，为空时恢复默认的查找规则
+func SetCookiesFilePath(path string) {
+	cookiesFilePath = path
+}
+
 // GetCookiesFilePath 获取 cookies 文件路径。
-// 为了向后兼容，如果旧路径 /tmp/cookies.json 存在，则继续使用；
+// 通过 SetCookiesFilePath 指定时直接使用；
+// 否则为了向后兼容，如果旧路径 /tmp/cookies.json 存在，则继续使用；
 // 否则使用当前目录下的 cookies.json
 func GetCookiesFilePath() string {
+	if cookiesFilePath != "" {
+		return cookiesFilePath
+	}
+
 	// 旧路径：/tmp/cookies.json
 	tmpDir := os.TempDir()
 	oldPath := filepath.Join(tmpDir, "cookies.json")
@@ -85,3 +99,19 @@ func GetCookiesFilePath() string {
 	// 文件不存在，使用新路径（当前目录）
 	return path
 }
+
+// CheckFile 检查 cookies 文件是否存在且为合法的 cookies JSON（浏览器导出的 cookie 数组），返回其中的 cookie 数量
+func CheckFile(path string) (int, error) {
+	data, err := NewLoadCookie(path).LoadCookies()
+	if err != nil {
+		return 0, err
+	}
+
+	var list []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return 0, errors.Wrap(err, "failed to parse cookies file")
+	}
+	return len(list), nil
+}
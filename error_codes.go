@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/idempotency"
+	"github.com/xpzouying/xiaohongshu-mcp/ratelimit"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// 通用错误码，业务错误的错误码见 serviceErrorMappings
+const (
+	errCodeBrowserBusy   = "BROWSER_BUSY"
+	errCodeInternalError = "INTERNAL_ERROR"
+)
+
+// statusClientClosedRequest 调用方取消了请求（沿用 nginx 的 499）
+const statusClientClosedRequest = 499
+
+// errInvalidArgs 参数缺失或不合法，MCP 与 REST 的参数校验失败时都包装该错误
+var errInvalidArgs = errors.New("请求参数错误")
+
+// errConfirmUnavailable 开启发布前确认时，REST 与后台任务无法向用户请求确认，不执行需要确认的操作
+var errConfirmUnavailable = errors.New("已开启发布前确认，只能通过 MCP 客户端发布")
+
+// serviceErrorMapping 业务错误与 HTTP 状态码、错误码的对应关系
+type serviceErrorMapping struct {
+	target  error
+	status  int
+	code    string
+	message string
+}
+
+// serviceErrorMappings 按顺序匹配，REST 与 MCP 共用同一套错误码
+var serviceErrorMappings = []serviceErrorMapping{
+	{errInvalidArgs, http.StatusBadRequest, "INVALID_REQUEST", "请求参数错误"},
+	{errForbidden, http.StatusForbidden, errCodeForbidden, "权限不足"},
+	{idempotency.ErrKeyReused, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "idempotency_key 已用于参数不同的请求"},
+	{idempotency.ErrInFlight, http.StatusConflict, "IDEMPOTENCY_IN_FLIGHT", "相同 idempotency_key 的请求正在执行，请稍后查询结果"},
+	{idempotency.ErrInterrupted, http.StatusConflict, "IDEMPOTENCY_INTERRUPTED", "相同 idempotency_key 的请求执行中断（服务重启或操作中途失败），请确认操作结果后使用新的 key"},
+	{ratelimit.ErrRateLimited, http.StatusTooManyRequests, errCodeRateLimited, "操作过于频繁，请稍后重试"},
+	{xiaohongshu.ErrRiskControl, http.StatusForbidden, "RISK_CONTROL", "触发验证码或风控，请人工处理后重试"},
+	{xiaohongshu.ErrNotLoggedIn, http.StatusUnauthorized, "NOT_LOGGED_IN", "未登录或登录已失效，请重新扫码登录"},
+	{xiaohongshu.ErrXsecTokenExpired, http.StatusGone, "XSEC_TOKEN_EXPIRED", "xsec_token 已失效，请重新获取笔记列表"},
+	{xiaohongshu.ErrNoteNotFound, http.StatusNotFound, "NOTE_NOT_FOUND", "笔记不存在或已被删除"},
+	{xiaohongshu.ErrContentRejected, http.StatusUnprocessableEntity, "CONTENT_REJECTED", "内容被平台拒绝"},
+	{xiaohongshu.ErrNotConfirmed, http.StatusForbidden, "NOT_CONFIRMED", "发布未获用户确认"},
+	{errConfirmUnavailable, http.StatusForbidden, "CONFIRMATION_REQUIRED", "已开启发布前确认，请通过 MCP 客户端发布"},
+	{xiaohongshu.ErrUploadTimeout, http.StatusGatewayTimeout, "UPLOAD_TIMEOUT", "上传超时"},
+	{xiaohongshu.ErrSelectorMissing, http.StatusBadGateway, "SELECTOR_MISSING", "页面元素缺失，小红书页面结构可能已变更"},
+	{context.Canceled, statusClientClosedRequest, "CANCELED", "请求已被调用方取消"},
+}
+
+// classifyError 返回业务错误对应的 HTTP 状态码、错误码与提示，无法识别时 ok 为 false
+func classifyError(err error) (status int, code, message string, ok bool) {
+	for _, m := range serviceErrorMappings {
+		if errors.Is(err, m.target) {
+			return m.status, m.code, m.message, true
+		}
+	}
+	return 0, "", "", false
+}
+
+// errorCode 返回错误对应的错误码，用于 MCP 工具结果
+func errorCode(err error) string {
+	if browser.IsBusyError(err) {
+		return errCodeBrowserBusy
+	}
+	if _, code, _, ok := classifyError(err); ok {
+		return code
+	}
+	return errCodeInternalError
+}
+
+// newMCPErrorResult 构造带错误码的 MCP 错误结果，文本形如 "[NOT_LOGGED_IN] 发布失败: ..."，
+// 保存了调试包时错误文本末尾带有 "(调试包: <id>)"
+func newMCPErrorResult(prefix string, err error) *MCPToolResult {
+	code := errorCode(err)
+	bundleID, _ := xiaohongshu.BundleIDOf(err)
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: fmt.Sprintf("[%s] %s: %v", code, prefix, err),
+		}},
+		IsError:     true,
+		ErrorCode:   code,
+		DebugBundle: bundleID,
+	}
+}
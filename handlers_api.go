@@ -1,13 +1,23 @@
 package main
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/ratelimit"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
+// busyRetryAfterSeconds 浏览器繁忙时建议客户端的重试间隔（秒）
+const busyRetryAfterSeconds = 30
+
 // respondError 返回错误响应
 func respondError(c *gin.Context, statusCode int, code, message string, details any) {
 	response := ErrorResponse{
@@ -16,232 +26,131 @@ func respondError(c *gin.Context, statusCode int, code, message string, details
 		Details: details,
 	}
 
-	logrus.Errorf("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
+	logging.From(c.Request.Context()).Errorf("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
 		c.GetString("account"), statusCode)
 
 	c.JSON(statusCode, response)
 }
 
-// respondSuccess 返回成功响应
-func respondSuccess(c *gin.Context, data any, message string) {
-	response := SuccessResponse{
-		Success: true,
-		Data:    data,
-		Message: message,
+// respondServiceError 返回服务调用失败的错误响应。
+// 浏览器页面池繁忙（等待超时）时返回 503 并带上 Retry-After 与排队信息；
+// 写操作超出额度时返回 429 并带上 Retry-After 与下次可执行的时间；
+// 可识别的业务错误（未登录、笔记不存在、风控等）按 classifyError 返回对应状态码与错误码，其余错误返回 500
+func respondServiceError(c *gin.Context, code, message string, err error) {
+	var busyErr *browser.BusyError
+	if errors.As(err, &busyErr) {
+		c.Header("Retry-After", strconv.Itoa(busyRetryAfterSeconds))
+		respondError(c, http.StatusServiceUnavailable, errCodeBrowserBusy,
+			"浏览器繁忙，请稍后重试", gin.H{
+				"error":       err.Error(),
+				"queue_depth": busyErr.QueueDepth,
+				"running":     busyErr.Running,
+			})
+		return
 	}
 
-	logrus.Infof("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
-		c.GetString("account"), http.StatusOK)
-
-	c.JSON(http.StatusOK, response)
-}
-
-// checkLoginStatusHandler 检查登录状态
-func (s *AppServer) checkLoginStatusHandler(c *gin.Context) {
-	status, err := s.xiaohongshuService.CheckLoginStatus(c.Request.Context())
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "STATUS_CHECK_FAILED",
-			"检查登录状态失败", err.Error())
+	var limitErr *ratelimit.Error
+	if errors.As(err, &limitErr) {
+		retryAfter := int(math.Ceil(limitErr.RetryAfter().Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		respondError(c, http.StatusTooManyRequests, errCodeRateLimited,
+			"操作过于频繁，请稍后重试", gin.H{
+				"error":               err.Error(),
+				"action":              limitErr.Action,
+				"reason":              limitErr.Reason,
+				"retry_at":            limitErr.RetryAt,
+				"retry_after_seconds": retryAfter,
+			})
 		return
 	}
 
-	c.Set("account", "ai-report")
-	respondSuccess(c, status, "检查登录状态成功")
-}
-
-// getLoginQrcodeHandler 处理 [GET /api/login/qrcode] 请求。
-// 用于生成并返回登录二维码（Base64 图片 + 超时时间），供前端展示给用户扫码登录。
-func (s *AppServer) getLoginQrcodeHandler(c *gin.Context) {
-	result, err := s.xiaohongshuService.GetLoginQrcode(c.Request.Context())
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "STATUS_CHECK_FAILED",
-			"获取登录二维码失败", err.Error())
-		return
+	status := http.StatusInternalServerError
+	if s, errCode, errMessage, ok := classifyError(err); ok {
+		status, code, message = s, errCode, errMessage
 	}
 
-	respondSuccess(c, result, "获取登录二维码成功")
+	respondError(c, status, code, message, serviceErrorDetails(err))
 }
 
-// publishHandler 发布内容
-func (s *AppServer) publishHandler(c *gin.Context) {
-	var req PublishRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
-			"请求参数错误", err.Error())
-		return
+// serviceErrorDetails 返回错误详情；保存了调试包时附带调试包 ID，
+// 可通过 GET /api/v1/debug/bundles/{id} 查看
+func serviceErrorDetails(err error) any {
+	id, ok := xiaohongshu.BundleIDOf(err)
+	if !ok {
+		return err.Error()
 	}
-
-	// 执行发布
-	result, err := s.xiaohongshuService.PublishContent(c.Request.Context(), &req)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "PUBLISH_FAILED",
-			"发布失败", err.Error())
-		return
+	return gin.H{
+		"error":        err.Error(),
+		"debug_bundle": id,
 	}
-
-	respondSuccess(c, result, "发布成功")
 }
 
-// publishVideoHandler 发布视频内容
-func (s *AppServer) publishVideoHandler(c *gin.Context) {
-	var req PublishVideoRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
-			"请求参数错误", err.Error())
-		return
+// respondSuccess 返回成功响应
+func respondSuccess(c *gin.Context, data any, message string) {
+	response := SuccessResponse{
+		Success: true,
+		Data:    data,
+		Message: message,
 	}
 
-	// 执行视频发布
-	result, err := s.xiaohongshuService.PublishVideo(c.Request.Context(), &req)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "PUBLISH_VIDEO_FAILED",
-			"视频发布失败", err.Error())
-		return
-	}
+	logging.From(c.Request.Context()).Infof("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
+		c.GetString("account"), http.StatusOK)
 
-	respondSuccess(c, result, "视频发布成功")
+	c.JSON(http.StatusOK, response)
 }
 
-// listFeedsHandler 获取Feeds列表
-func (s *AppServer) listFeedsHandler(c *gin.Context) {
-	// 获取 Feeds 列表
-	result, err := s.xiaohongshuService.ListFeeds(c.Request.Context())
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "LIST_FEEDS_FAILED",
-			"获取Feeds列表失败", err.Error())
-		return
-	}
-
-	c.Set("account", "ai-report")
-	respondSuccess(c, result, "获取Feeds列表成功")
+// browserStatusHandler 返回浏览器页面池状态（排队数量、正在执行与等待中的操作）
+func browserStatusHandler(c *gin.Context) {
+	respondSuccess(c, browser.GetGlobalManager().Status(), "获取浏览器状态成功")
 }
 
-// searchFeedsHandler 搜索Feeds
-func (s *AppServer) searchFeedsHandler(c *gin.Context) {
-	keyword := c.Query("keyword")
-	if keyword == "" {
-		respondError(c, http.StatusBadRequest, "MISSING_KEYWORD",
-			"缺少关键词参数", "keyword parameter is required")
-		return
-	}
-
-	// 搜索 Feeds
-	result, err := s.xiaohongshuService.SearchFeeds(c.Request.Context(), keyword)
-	if err != nil {
-		respondError(c, http.StatusInternalServerError, "SEARCH_FEEDS_FAILED",
-			"搜索Feeds失败", err.Error())
-		return
-	}
-
-	c.Set("account", "ai-report")
-	respondSuccess(c, result, "搜索Feeds成功")
+// selectorsHandler 查看当前生效的页面选择器及其版本
+func selectorsHandler(c *gin.Context) {
+	respondSuccess(c, xiaohongshu.CurrentSelectors(), "获取选择器成功")
 }
 
-// getFeedDetailHandler 获取Feed详情
-func (s *AppServer) getFeedDetailHandler(c *gin.Context) {
-	var req FeedDetailRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
-			"请求参数错误", err.Error())
-		return
-	}
-
-	// 获取 Feed 详情
-	result, err := s.xiaohongshuService.GetFeedDetail(c.Request.Context(), req.FeedID, req.XsecToken)
+// reloadSelectorsHandler 重新加载选择器文件，文件有误时继续使用原有选择器
+func reloadSelectorsHandler(c *gin.Context) {
+	info, err := xiaohongshu.ReloadSelectors()
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "GET_FEED_DETAIL_FAILED",
-			"获取Feed详情失败", err.Error())
+		respondError(c, http.StatusBadRequest, "SELECTORS_RELOAD_FAILED",
+			"重新加载选择器失败", err.Error())
 		return
 	}
 
-	c.Set("account", "ai-report")
-	respondSuccess(c, result, "获取Feed详情成功")
+	logrus.Infof("选择器已重新加载: version=%s, overrides=%v", info.Version, info.Overrides)
+	respondSuccess(c, info, "重新加载选择器成功")
 }
 
-// userProfileHandler 用户主页
-func (s *AppServer) userProfileHandler(c *gin.Context) {
-	var req UserProfileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
-			"请求参数错误", err.Error())
-		return
-	}
-
-	// 获取用户信息
-	result, err := s.xiaohongshuService.UserProfile(c.Request.Context(), req.UserID, req.XsecToken)
+// debugBundleHandler 查看调试包的步骤记录与文件列表
+func debugBundleHandler(c *gin.Context) {
+	trace, err := xiaohongshu.ReadBundleTrace(c.Param("id"))
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "GET_USER_PROFILE_FAILED",
-			"获取用户主页失败", err.Error())
+		respondError(c, http.StatusNotFound, "BUNDLE_NOT_FOUND",
+			"调试包不存在", err.Error())
 		return
 	}
 
-	c.Set("account", "ai-report")
-	respondSuccess(c, map[string]any{"data": result}, "result.Message")
+	respondSuccess(c, trace, "获取调试包成功")
 }
 
-// postCommentHandler 发表评论到Feed
-func (s *AppServer) postCommentHandler(c *gin.Context) {
-	var req PostCommentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
-			"请求参数错误", err.Error())
-		return
-	}
-
-	// 发表评论
-	result, err := s.xiaohongshuService.PostCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.Content)
+// debugBundleFileHandler 下载调试包中的文件（截图、HTML 等）
+func debugBundleFileHandler(c *gin.Context) {
+	path, err := xiaohongshu.BundleFilePath(c.Param("id"), c.Param("file"))
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "POST_COMMENT_FAILED",
-			"发表评论失败", err.Error())
-		return
-	}
-
-	c.Set("account", "ai-report")
-	respondSuccess(c, result, result.Message)
-}
-
-// healthHandler 健康检查
-func healthHandler(c *gin.Context) {
-	respondSuccess(c, map[string]any{
-		"status":    "healthy",
-		"service":   "xiaohongshu-mcp",
-		"account":   "ai-report",
-		"timestamp": "now",
-	}, "服务正常")
-}
-
-// browseRecommendationsHandler 模拟人类浏览推荐页
-func (s *AppServer) browseRecommendationsHandler(c *gin.Context) {
-	var req BrowseRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
 		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
 			"请求参数错误", err.Error())
 		return
 	}
-
-	// 转换为内部配置类型
-	config := toBrowseConfig(req)
-
-	// 执行浏览
-	stats, err := s.xiaohongshuService.BrowseRecommendations(c.Request.Context(), config)
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		err = errors.New(c.Param("file") + " 不是文件")
+	}
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "BROWSE_FAILED",
-			"浏览推荐页失败", err.Error())
+		respondError(c, http.StatusNotFound, "BUNDLE_NOT_FOUND",
+			"调试包文件不存在", err.Error())
 		return
 	}
 
-	c.Set("account", "ai-report")
-	respondSuccess(c, stats, "浏览推荐页完成")
-}
-
-// toBrowseConfig 转换请求为浏览配置
-func toBrowseConfig(req BrowseRequest) xiaohongshu.BrowseConfig {
-	return xiaohongshu.BrowseConfig{
-		Duration:            req.Duration,
-		MinScrolls:          req.MinScrolls,
-		MaxScrolls:          req.MaxScrolls,
-		ClickProbability:    req.ClickProbability,
-		InteractProbability: req.InteractProbability,
-		Comments:            req.Comments,
-	}
+	c.File(path)
 }
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/metrics"
+)
+
+// serviceVersion 服务版本，用于 MCP 服务信息、OpenAPI 文档与健康检查
+const serviceVersion = "2.0.0"
+
+// readyCheckTimeout 就绪检查的最长耗时，需小于探针的超时时间
+const readyCheckTimeout = 8 * time.Second
+
+// errCodeNotReady 就绪检查未通过时的错误码
+const errCodeNotReady = "NOT_READY"
+
+// startedAt 服务启动时间
+var startedAt = time.Now()
+
+// loginCheck 最近一次登录状态检查的结果，就绪检查直接返回它而不是每次打开页面检查
+type loginCheck struct {
+	mu        sync.Mutex
+	loggedIn  bool
+	checkedAt time.Time
+}
+
+var lastLoginCheck loginCheck
+
+// recordLoginState 记录登录状态检查的结果
+func recordLoginState(loggedIn bool) {
+	metrics.SetLoggedIn(loggedIn)
+
+	lastLoginCheck.mu.Lock()
+	defer lastLoginCheck.mu.Unlock()
+	lastLoginCheck.loggedIn = loggedIn
+	lastLoginCheck.checkedAt = time.Now()
+}
+
+// LoginCheckStatus 就绪检查中返回的登录状态
+type LoginCheckStatus struct {
+	Checked    bool       `json:"checked"` // 启动后是否检查过登录状态
+	LoggedIn   bool       `json:"logged_in"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+	AgeSeconds int        `json:"age_seconds"` // 距上次检查的时间
+}
+
+// CheckResult 单项就绪检查的结果
+type CheckResult struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// ReadyResponse 就绪检查响应
+type ReadyResponse struct {
+	Ready   bool             `json:"ready"`
+	Browser CheckResult      `json:"browser"`
+	Cookies CheckResult      `json:"cookies"`
+	Login   LoginCheckStatus `json:"login"` // 只用于展示，未登录不影响就绪状态
+}
+
+// HealthResponse 存活检查响应
+type HealthResponse struct {
+	Status        string    `json:"status"`
+	Service       string    `json:"service"`
+	Version       string    `json:"version"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int       `json:"uptime_seconds"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// healthHandler 存活检查，进程能够处理请求即返回 200
+func healthHandler(c *gin.Context) {
+	now := time.Now()
+	respondSuccess(c, HealthResponse{
+		Status:        "healthy",
+		Service:       "xiaohongshu-mcp",
+		Version:       serviceVersion,
+		StartedAt:     startedAt,
+		UptimeSeconds: int(now.Sub(startedAt).Seconds()),
+		Timestamp:     now,
+	}, "服务正常")
+}
+
+// readyHandler 就绪检查：浏览器可以使用且 cookies 文件存在并可解析时返回 200，否则返回 503。
+// 同时返回最近一次登录检查的结果及其距今的时间
+func readyHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyCheckTimeout)
+	defer cancel()
+
+	resp := ReadyResponse{
+		Browser: checkBrowser(ctx),
+		Cookies: checkCookies(),
+		Login:   loginStatus(),
+	}
+	resp.Ready = resp.Browser.OK && resp.Cookies.OK
+
+	if !resp.Ready {
+		respondError(c, http.StatusServiceUnavailable, errCodeNotReady, "服务未就绪", resp)
+		return
+	}
+	respondSuccess(c, resp, "服务已就绪")
+}
+
+// checkBrowser 检查浏览器能否使用，见 browser.Manager.CheckReady
+func checkBrowser(ctx context.Context) CheckResult {
+	manager := browser.GetGlobalManager()
+	if err := manager.CheckReady(ctx); err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+
+	if status := manager.Status(); status.BrowserRunning {
+		return CheckResult{OK: true, Message: "浏览器运行中"}
+	}
+	return CheckResult{OK: true, Message: "浏览器未启动，将在首次使用时启动"}
+}
+
+// checkCookies 检查 cookies 文件是否存在且可以解析
+func checkCookies() CheckResult {
+	path := cookies.GetCookiesFilePath()
+	n, err := cookies.CheckFile(path)
+	if err != nil {
+		return CheckResult{Message: err.Error()}
+	}
+	return CheckResult{OK: true, Message: fmt.Sprintf("%s 中有 %d 个 cookie", path, n)}
+}
+
+// loginStatus 返回最近一次登录检查的结果
+func loginStatus() LoginCheckStatus {
+	lastLoginCheck.mu.Lock()
+	defer lastLoginCheck.mu.Unlock()
+
+	if lastLoginCheck.checkedAt.IsZero() {
+		return LoginCheckStatus{}
+	}
+	checkedAt := lastLoginCheck.checkedAt
+	return LoginCheckStatus{
+		Checked:    true,
+		LoggedIn:   lastLoginCheck.loggedIn,
+		CheckedAt:  &checkedAt,
+		AgeSeconds: int(time.Since(checkedAt).Seconds()),
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/jobs"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// sseKeepAliveInterval SSE 连接的心跳间隔，避免代理因长时间没有数据而断开连接
+const sseKeepAliveInterval = 15 * time.Second
+
+// 后台任务相关的错误码
+const (
+	errCodeJobNotFound = "JOB_NOT_FOUND"
+	errCodeJobFinished = "JOB_FINISHED"
+)
+
+// SubmitJobRequest 提交后台任务的请求，arguments 与同名 MCP 工具 / REST 接口的参数相同
+type SubmitJobRequest struct {
+	Tool      string          `json:"tool" binding:"required"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// newJobManager 创建后台任务管理器，任务目录不可用时退回到只保存在内存中
+func newJobManager(s *AppServer) *jobs.Manager {
+	dir := configs.Get().Paths.JobsDir
+	m, err := jobs.NewManager(dir, s.describeJobError)
+	if err != nil {
+		logrus.Errorf("任务目录 %s 不可用，任务记录将只保存在内存中: %v", dir, err)
+		m, _ = jobs.NewManager("", s.describeJobError)
+	}
+	return m
+}
+
+// describeJobError 将任务错误转换为与 REST 接口相同的错误码，无法识别时使用工具自身的错误码
+func (s *AppServer) describeJobError(job *jobs.Job, err error) *jobs.Error {
+	code := errorCode(err)
+	if t := s.findTool(job.Tool); t != nil {
+		code = t.spec().errorCode(err)
+	}
+
+	bundleID, _ := xiaohongshu.BundleIDOf(err)
+	return &jobs.Error{Code: code, Message: err.Error(), DebugBundle: bundleID}
+}
+
+// findTool 按 MCP 工具名查找操作
+func (s *AppServer) findTool(name string) tool {
+	for _, t := range s.tools {
+		if t.spec().Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// jobOwnerFilter 返回当前请求只能查看的任务所有者。拥有 admin 权限或未启用鉴权时可查看所有任务
+func (s *AppServer) jobOwnerFilter(c *gin.Context) string {
+	if s.auth.allowed(c, configs.ScopeAdmin) {
+		return ""
+	}
+	return c.GetString(apiKeyContextKey)
+}
+
+// lookupJob 查找当前请求可以访问的任务，其他 API Key 的任务视为不存在
+func (s *AppServer) lookupJob(c *gin.Context) (*jobs.Job, bool) {
+	job, err := s.jobs.Get(c.Param("id"))
+	if err == nil {
+		if owner := s.jobOwnerFilter(c); owner == "" || job.Owner == owner {
+			return job, true
+		}
+	}
+
+	respondError(c, http.StatusNotFound, errCodeJobNotFound, "任务不存在", c.Param("id"))
+	return nil, false
+}
+
+// submitJobHandler 提交后台任务，立即返回任务 ID，所需权限与同名工具相同
+func (s *AppServer) submitJobHandler(c *gin.Context) {
+	var req SubmitJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondServiceError(c, "SUBMIT_JOB_FAILED", "提交任务失败", describeArgsError(req, err))
+		return
+	}
+
+	t := s.findTool(req.Tool)
+	if t == nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "请求参数错误", "未知的工具: "+req.Tool)
+		return
+	}
+	spec := t.spec()
+	if !s.auth.allowed(c, spec.Scope) {
+		s.auth.rejectScope(c, spec.Scope)
+		return
+	}
+
+	run, err := t.prepareJob(req.Arguments)
+	if err != nil {
+		respondServiceError(c, "SUBMIT_JOB_FAILED", "提交任务失败", err)
+		return
+	}
+
+	// 任务的日志同时带有任务 ID 与提交任务的请求 ID
+	owner := c.GetString(apiKeyContextKey)
+	requestID := logging.RequestID(c.Request.Context())
+	job, err := s.jobs.Submit(spec.Name, owner, req.Arguments, func(ctx context.Context, report func(any)) (any, error) {
+		return run(withAccount(logging.WithRequestID(ctx, requestID), owner), report)
+	})
+	if err != nil {
+		respondError(c, http.StatusServiceUnavailable, "SUBMIT_JOB_FAILED", "提交任务失败", err.Error())
+		return
+	}
+
+	respondSuccess(c, job, "任务已提交")
+}
+
+// listJobsHandler 按提交时间倒序列出任务
+func (s *AppServer) listJobsHandler(c *gin.Context) {
+	respondSuccess(c, s.jobs.List(s.jobOwnerFilter(c)), "获取任务列表成功")
+}
+
+// getJobHandler 查询任务状态与结果
+func (s *AppServer) getJobHandler(c *gin.Context) {
+	job, ok := s.lookupJob(c)
+	if !ok {
+		return
+	}
+	respondSuccess(c, job, "获取任务成功")
+}
+
+// cancelJobHandler 取消未结束的任务
+func (s *AppServer) cancelJobHandler(c *gin.Context) {
+	job, ok := s.lookupJob(c)
+	if !ok {
+		return
+	}
+
+	canceled, err := s.jobs.Cancel(job.ID)
+	if errors.Is(err, jobs.ErrFinished) {
+		respondError(c, http.StatusConflict, errCodeJobFinished, "任务已结束，无法取消", job)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusNotFound, errCodeJobNotFound, "任务不存在", err.Error())
+		return
+	}
+
+	respondSuccess(c, canceled, "已请求取消任务")
+}
+
+// jobEventsHandler 以 Server-Sent Events 推送任务的状态与进度，任务结束后关闭连接。
+// 先发送一次当前的任务快照，再补发内存中保留的事件（支持 Last-Event-ID 断点续传），之后实时推送
+func (s *AppServer) jobEventsHandler(c *gin.Context) {
+	job, ok := s.lookupJob(c)
+	if !ok {
+		return
+	}
+
+	snapshot, history, events, unsubscribe, err := s.jobs.Subscribe(job.ID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, errCodeJobNotFound, "任务不存在", err.Error())
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	lastStatus := snapshot.Status
+	c.Render(-1, sse.Event{Event: jobs.EventStatus, Data: snapshot})
+	c.Writer.Flush()
+
+	lastID, _ := strconv.Atoi(c.GetHeader("Last-Event-ID"))
+	send := func(e jobs.Event) {
+		if e.ID <= lastID {
+			return
+		}
+		if e.Type == jobs.EventStatus {
+			var j jobs.Job
+			if json.Unmarshal(e.Data, &j) == nil {
+				lastStatus = j.Status
+			}
+		}
+		c.Render(-1, sse.Event{Id: strconv.Itoa(e.ID), Event: e.Type, Data: e.Data})
+		c.Writer.Flush()
+	}
+	for _, e := range history {
+		send(e)
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				// 任务已结束。客户端读取过慢时最终状态可能被丢弃，此时补发一次
+				if final, err := s.jobs.Get(job.ID); err == nil && final.Status != lastStatus {
+					c.Render(-1, sse.Event{Event: jobs.EventStatus, Data: final})
+					c.Writer.Flush()
+				}
+				return
+			}
+			send(e)
+		case <-keepAlive.C:
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
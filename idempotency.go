@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/idempotency"
+)
+
+// idempotentRequest 支持 idempotency_key 的请求参数
+type idempotentRequest interface {
+	idempotencyKey() string
+}
+
+func (r PublishRequest) idempotencyKey() string      { return r.IdempotencyKey }
+func (r PublishVideoRequest) idempotencyKey() string { return r.IdempotencyKey }
+func (r PostCommentArgs) idempotencyKey() string     { return r.IdempotencyKey }
+
+// newIdempotencyStore 按配置创建幂等请求记录，记录文件不可用时退回到只保存在内存中
+func newIdempotencyStore() *idempotency.Store {
+	cfg := configs.Get()
+	path := cfg.Paths.IdempotencyFile
+	s, err := idempotency.New(path, cfg.Idempotency.Window)
+	if err != nil {
+		logrus.Errorf("幂等记录文件 %s 不可用，idempotency_key 将只保存在内存中: %v", path, err)
+		s, _ = idempotency.New("", cfg.Idempotency.Window)
+	}
+	return s
+}
@@ -0,0 +1,207 @@
+// Package idempotency 记录带 idempotency_key 的写操作（发布、评论）及其结果。
+// 客户端超时重试时使用同一个 key，有效期内只会执行一次，重复的请求直接返回首次执行的结果
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 记录的状态
+const (
+	StatusInFlight    = "in_flight"   // 正在执行
+	StatusDone        = "done"        // 执行成功，保存了结果
+	StatusInterrupted = "interrupted" // 执行时服务退出或操作中途失败，结果未知
+)
+
+var (
+	// ErrInFlight 相同 key 的请求正在执行
+	ErrInFlight = errors.New("相同 idempotency_key 的请求正在执行")
+	// ErrInterrupted 相同 key 的请求执行时服务退出或在操作中途失败，无法确定操作是否已完成
+	ErrInterrupted = errors.New("相同 idempotency_key 的请求执行中断，结果未知")
+	// ErrKeyReused 相同 key 已用于参数不同的请求
+	ErrKeyReused = errors.New("idempotency_key 已用于参数不同的请求")
+)
+
+// record 一个 key 的执行记录
+type record struct {
+	Fingerprint string          `json:"fingerprint"` // 请求参数的摘要
+	Status      string          `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+}
+
+// Store 保存 key 的执行记录，可并发使用
+type Store struct {
+	path   string
+	window time.Duration
+
+	mu      sync.Mutex
+	records map[string]*record
+	now     func() time.Time
+}
+
+// New 创建记录存储并加载 path 中的记录，path 为空时只保存在内存中。
+// 记录在首次请求 window 之后过期，过期后相同的 key 会重新执行。
+// 上次退出时仍在执行的记录标记为中断
+func New(path string, window time.Duration) (*Store, error) {
+	s := &Store{
+		path:    path,
+		window:  window,
+		records: map[string]*record{},
+		now:     time.Now,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取幂等记录失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, fmt.Errorf("解析幂等记录 %s 失败: %w", path, err)
+	}
+	if s.records == nil {
+		s.records = map[string]*record{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, r := range s.records {
+		if r.Status == StatusInFlight {
+			logrus.Warnf("幂等请求 %s 在上次退出时仍在执行，标记为中断", id)
+			r.Status = StatusInterrupted
+		}
+	}
+	s.persistLocked()
+	return s, nil
+}
+
+// Fingerprint 计算请求参数的摘要，用于识别同一个 key 是否被用于不同的请求
+func Fingerprint(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin 登记 scope 下 key 的一次执行。
+// key 已有成功的记录时返回首次执行的结果 replay；正在执行、执行中断或参数不同时返回对应的错误。
+// 否则登记为执行中并返回 finish，操作结束后调用：err 为 nil 时保存结果；
+// 失败且 retryable 为 true（操作没有作用到小红书）时删除记录，相同的 key 可以重试；
+// 失败且 retryable 为 false 时操作可能已经生效，记录标记为中断，相同的 key 不再执行
+func (s *Store) Begin(scope, key, fingerprint string) (replay json.RawMessage, finish func(result any, err error, retryable bool), err error) {
+	id := scope + "/" + key
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+
+	if r, ok := s.records[id]; ok {
+		switch {
+		case r.Fingerprint != fingerprint:
+			return nil, nil, fmt.Errorf("%w: %s", ErrKeyReused, key)
+		case r.Status == StatusInFlight:
+			return nil, nil, fmt.Errorf("%w: %s", ErrInFlight, key)
+		case r.Status == StatusInterrupted:
+			return nil, nil, fmt.Errorf("%w: %s", ErrInterrupted, key)
+		default:
+			logrus.Infof("重复的幂等请求 %s，返回首次执行的结果", id)
+			return r.Result, nil, nil
+		}
+	}
+
+	r := &record{Fingerprint: fingerprint, Status: StatusInFlight, StartedAt: s.now()}
+	s.records[id] = r
+	s.persistLocked()
+
+	var once sync.Once
+	finish = func(result any, err error, retryable bool) {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.finishLocked(id, r, result, err, retryable)
+		})
+	}
+	return nil, finish, nil
+}
+
+// finishLocked 保存执行结果，调用方需持有 s.mu
+func (s *Store) finishLocked(id string, r *record, result any, err error, retryable bool) {
+	if s.records[id] != r {
+		return
+	}
+	if err != nil && retryable {
+		delete(s.records, id)
+		s.persistLocked()
+		return
+	}
+	if err != nil {
+		logrus.Warnf("幂等请求 %s 在操作中途失败，标记为中断: %v", id, err)
+		r.Status = StatusInterrupted
+		s.persistLocked()
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logrus.Errorf("序列化幂等请求 %s 的结果失败: %v", id, err)
+		delete(s.records, id)
+		s.persistLocked()
+		return
+	}
+	r.Status = StatusDone
+	r.Result = data
+	s.persistLocked()
+}
+
+// pruneLocked 删除过期的记录，正在执行的记录不会过期。调用方需持有 s.mu
+func (s *Store) pruneLocked() {
+	cutoff := s.now().Add(-s.window)
+	for id, r := range s.records {
+		if r.Status != StatusInFlight && r.StartedAt.Before(cutoff) {
+			delete(s.records, id)
+		}
+	}
+}
+
+// persistLocked 清理过期记录后写入文件，先写临时文件再重命名。调用方需持有 s.mu
+func (s *Store) persistLocked() {
+	s.pruneLocked()
+	if s.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(s.records)
+	if err != nil {
+		logrus.Errorf("序列化幂等记录失败: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		logrus.Errorf("保存幂等记录失败: %v", err)
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		logrus.Errorf("保存幂等记录失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		logrus.Errorf("保存幂等记录失败: %v", err)
+	}
+}
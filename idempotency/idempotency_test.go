@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type result struct {
+	PostID string `json:"post_id"`
+}
+
+func TestBeginReplaysSuccessfulResult(t *testing.T) {
+	s, err := New("", time.Hour)
+	require.NoError(t, err)
+	fp := Fingerprint(map[string]string{"title": "hello"})
+
+	replay, finish, err := s.Begin("bot/publish_content", "k1", fp)
+	require.NoError(t, err)
+	assert.Nil(t, replay)
+
+	_, _, err = s.Begin("bot/publish_content", "k1", fp)
+	assert.ErrorIs(t, err, ErrInFlight, "执行中的请求不会重复执行")
+
+	finish(result{PostID: "p1"}, nil, false)
+
+	replay, finish, err = s.Begin("bot/publish_content", "k1", fp)
+	require.NoError(t, err)
+	assert.Nil(t, finish)
+	var got result
+	require.NoError(t, json.Unmarshal(replay, &got))
+	assert.Equal(t, "p1", got.PostID)
+
+	_, _, err = s.Begin("bot/publish_content", "k1", Fingerprint(map[string]string{"title": "other"}))
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	_, finish, err = s.Begin("other/publish_content", "k1", fp)
+	require.NoError(t, err, "不同账号的 key 互不影响")
+	finish(nil, errors.New("failed"), true)
+}
+
+func TestBeginAllowsRetryAfterFailureAndExpiry(t *testing.T) {
+	s, err := New("", time.Hour)
+	require.NoError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	_, finish, err := s.Begin("bot/post_comment_to_feed", "k1", "fp")
+	require.NoError(t, err)
+	finish(nil, errors.New("failed"), true)
+
+	_, finish, err = s.Begin("bot/post_comment_to_feed", "k1", "fp")
+	require.NoError(t, err, "失败的请求可以用相同的 key 重试")
+	require.NotNil(t, finish)
+	finish(result{PostID: "c1"}, nil, false)
+
+	now = now.Add(2 * time.Hour)
+	replay, finish, err := s.Begin("bot/post_comment_to_feed", "k1", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, replay, "过期后重新执行")
+	assert.NotNil(t, finish)
+}
+
+func TestNewMarksInFlightAsInterrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	s, err := New(path, time.Hour)
+	require.NoError(t, err)
+
+	_, finish, err := s.Begin("bot/publish_content", "done", "fp")
+	require.NoError(t, err)
+	finish(result{PostID: "p1"}, nil, false)
+	_, _, err = s.Begin("bot/publish_content", "running", "fp")
+	require.NoError(t, err)
+
+	reloaded, err := New(path, time.Hour)
+	require.NoError(t, err)
+
+	replay, _, err := reloaded.Begin("bot/publish_content", "done", "fp")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"post_id":"p1"}`, string(replay))
+
+	_, _, err = reloaded.Begin("bot/publish_content", "running", "fp")
+	assert.ErrorIs(t, err, ErrInterrupted)
+}
+
+func TestFailureAfterSubmitKeepsRecord(t *testing.T) {
+	s, err := New("", time.Hour)
+	require.NoError(t, err)
+
+	_, finish, err := s.Begin("bot/publish_content", "k1", "fp")
+	require.NoError(t, err)
+	finish(nil, errors.New("发布后检查失败"), false)
+
+	_, _, err = s.Begin("bot/publish_content", "k1", "fp")
+	assert.ErrorIs(t, err, ErrInterrupted, "操作可能已生效，相同的 key 不再执行")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/idempotency"
+)
+
+func TestIdempotentRunKeepsKeyAfterPageAcquired(t *testing.T) {
+	keys, err := idempotency.New("", time.Hour)
+	require.NoError(t, err)
+
+	runs := 0
+	var run func(ctx context.Context) (string, error)
+	tool := &typedTool[PublishRequest, string]{
+		toolSpec: toolSpec{Name: "publish_content"},
+		run: func(ctx context.Context, _ PublishRequest) (string, error) {
+			runs++
+			return run(ctx)
+		},
+		keys: keys,
+	}
+	call := func(key string) error {
+		_, err := tool.idempotentRun(context.Background(), PublishRequest{Title: "t", IdempotencyKey: key})
+		return err
+	}
+
+	run = func(context.Context) (string, error) { return "", errors.New("浏览器繁忙") }
+	require.Error(t, call("before-page"))
+	run = func(context.Context) (string, error) { return "ok", nil }
+	require.NoError(t, call("before-page"), "拿到页面之前失败可以用相同的 key 重试")
+	assert.Equal(t, 2, runs)
+
+	run = func(ctx context.Context) (string, error) {
+		markPageAcquired(ctx)
+		return "", context.Canceled
+	}
+	require.Error(t, call("after-page"))
+	assert.ErrorIs(t, call("after-page"), idempotency.ErrInterrupted, "拿到页面之后失败不再重复执行")
+	assert.Equal(t, 3, runs)
+
+	run = func(context.Context) (string, error) { panic("boom") }
+	assert.Panics(t, func() { _ = call("panic") })
+	run = func(context.Context) (string, error) { return "ok", nil }
+	assert.NoError(t, call("panic"), "panic 后 key 不会一直处于执行中")
+}
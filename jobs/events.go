@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 事件类型
+const (
+	EventStatus   = "status"   // 任务状态变化，data 为任务快照
+	EventProgress = "progress" // 执行进度，data 为上报的进度
+)
+
+const (
+	maxEventHistory  = 100 // 每个任务在内存中保留的最近事件数，供新的订阅者补发
+	subscriberBuffer = 64  // 订阅者的缓冲区，读取过慢时丢弃进度事件
+)
+
+// Event 任务事件
+type Event struct {
+	ID   int             `json:"id"` // 同一任务内递增
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// reportProgress 记录最近一次进度并通知订阅者。进度只保存在内存中，随下一次状态变化写入任务记录
+func (m *Manager) reportProgress(job *Job, progress any) {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		logrus.Warnf("序列化任务 %s 的进度失败: %v", job.ID, err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.Progress = data
+	m.publish(job.ID, EventProgress, progress)
+}
+
+// publish 记录事件并发送给订阅者，调用方需持有 m.mu
+func (m *Manager) publish(id, typ string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		logrus.Warnf("序列化任务 %s 的事件失败: %v", id, err)
+		return
+	}
+
+	history := m.events[id]
+	event := Event{ID: 1, Type: typ, Time: time.Now(), Data: raw}
+	if n := len(history); n > 0 {
+		event.ID = history[n-1].ID + 1
+	}
+	if len(history) >= maxEventHistory {
+		history = history[1:]
+	}
+	m.events[id] = append(history, event)
+
+	for ch := range m.subscribers[id] {
+		select {
+		case ch <- event:
+		default:
+			logrus.Debugf("任务 %s 的订阅者读取过慢，丢弃事件 %d", id, event.ID)
+		}
+	}
+}
+
+// closeSubscribers 任务结束后关闭所有订阅并释放事件记录，调用方需持有 m.mu
+func (m *Manager) closeSubscribers(id string) {
+	for ch := range m.subscribers[id] {
+		close(ch)
+	}
+	delete(m.subscribers, id)
+	delete(m.events, id)
+}
+
+// Subscribe 订阅任务事件。返回当前的任务快照、内存中保留的历史事件，以及后续事件的 channel；
+// 任务结束后 channel 被关闭（已结束的任务返回已关闭的 channel）。不再读取时需调用 unsubscribe
+func (m *Manager) Subscribe(id string) (job *Job, history []Event, events <-chan Event, unsubscribe func(), err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, nil, nil, ErrNotFound
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	if j.Status.Done() {
+		close(ch)
+		return j.clone(), nil, ch, func() {}, nil
+	}
+
+	if m.subscribers[id] == nil {
+		m.subscribers[id] = map[chan Event]struct{}{}
+	}
+	m.subscribers[id][ch] = struct{}{}
+
+	unsubscribe = func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, ok := m.subscribers[id][ch]; ok {
+			delete(m.subscribers[id], ch)
+			close(ch)
+		}
+	}
+	return j.clone(), append([]Event(nil), m.events[id]...), ch, unsubscribe, nil
+}
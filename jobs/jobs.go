@@ -0,0 +1,429 @@
+// Package jobs 在后台执行耗时操作（发布视频、浏览推荐页等），客户端提交后通过任务 ID 查询状态与结果。
+// 任务记录保存在本地目录中，服务重启后仍可查询
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusQueued    Status = "queued"    // 已提交，尚未开始执行
+	StatusRunning   Status = "running"   // 执行中（包括等待浏览器页面）
+	StatusSucceeded Status = "succeeded" // 执行成功，result 中为结果
+	StatusFailed    Status = "failed"    // 执行失败，error 中为错误信息
+	StatusCanceled  Status = "canceled"  // 被客户端取消
+)
+
+// Done 任务是否已结束
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// ErrCodeInterrupted 服务关闭或重启导致任务中断时使用的错误码
+const ErrCodeInterrupted = "JOB_INTERRUPTED"
+
+// DefaultRetention 已结束的任务记录保留的时间，超过后在提交、列出任务或下次启动时删除
+const DefaultRetention = 7 * 24 * time.Hour
+
+var (
+	ErrNotFound = errors.New("任务不存在")
+	ErrFinished = errors.New("任务已结束")
+	ErrClosed   = errors.New("任务服务已关闭")
+)
+
+// idPattern 任务 ID 只包含字母、数字、下划线和短横线，防止路径穿越
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Error 任务失败的原因
+type Error struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	DebugBundle string `json:"debug_bundle,omitempty"`
+}
+
+// Job 一个后台任务
+type Job struct {
+	ID         string          `json:"id"`
+	Tool       string          `json:"tool"`
+	Owner      string          `json:"owner,omitempty"` // 提交任务的 API Key 名称
+	Status     Status          `json:"status"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Progress   json.RawMessage `json:"progress,omitempty"` // 最近一次上报的进度
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      *Error          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+func (j *Job) clone() *Job {
+	c := *j
+	if j.Error != nil {
+		e := *j.Error
+		c.Error = &e
+	}
+	return &c
+}
+
+// Func 任务的执行函数，ctx 在任务被取消或服务关闭时取消，通过 report 上报进度
+type Func func(ctx context.Context, report func(progress any)) (any, error)
+
+// DescribeFunc 将任务的执行错误转换为错误码与提示
+type DescribeFunc func(job *Job, err error) *Error
+
+// Manager 管理后台任务的执行与持久化
+type Manager struct {
+	dir      string
+	describe DescribeFunc
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	cancels  map[string]context.CancelFunc
+	canceled map[string]bool // 客户端请求取消的任务
+	closed   bool
+
+	writeMu sync.Mutex // 保证任务记录按顺序写入，写入时不持有 mu
+
+	events      map[string][]Event // 执行中与最近结束的任务的事件，只保存在内存中
+	subscribers map[string]map[chan Event]struct{}
+	wg          sync.WaitGroup
+}
+
+// NewManager 创建任务管理器并加载 dir 中的任务记录，dir 为空时只保存在内存中。
+// 上次退出时未结束的任务标记为失败（JOB_INTERRUPTED），超过 DefaultRetention 的记录被删除
+func NewManager(dir string, describe DescribeFunc) (*Manager, error) {
+	m := &Manager{
+		dir:      dir,
+		describe: describe,
+		jobs:     map[string]*Job{},
+		cancels:  map[string]context.CancelFunc{},
+		canceled: map[string]bool{},
+
+		events:      map[string][]Event{},
+		subscribers: map[string]map[chan Event]struct{}{},
+	}
+	if dir == "" {
+		return m, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建任务目录失败: %w", err)
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manager) load() error {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("读取任务目录失败: %w", err)
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(m.dir, e.Name())
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logrus.Warnf("读取任务记录 %s 失败: %v", path, err)
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || !idPattern.MatchString(job.ID) {
+			logrus.Warnf("任务记录 %s 无效，已跳过: %v", path, err)
+			continue
+		}
+
+		if expired(&job, now) {
+			m.removeRecords([]string{job.ID})
+			continue
+		}
+		m.jobs[job.ID] = &job
+		if !job.Status.Done() {
+			job.Status = StatusFailed
+			job.Error = &Error{Code: ErrCodeInterrupted, Message: "服务重启，任务被中断"}
+			job.FinishedAt = &now
+			m.persist(job.ID)
+		}
+	}
+
+	logrus.Infof("已加载 %d 个任务记录: %s", len(m.jobs), m.dir)
+	return nil
+}
+
+// Submit 提交任务并立即在后台执行
+func (m *Manager) Submit(tool, owner string, args json.RawMessage, fn Func) (*Job, error) {
+	id := newID(tool)
+	ctx, cancel := context.WithCancel(logging.With(context.Background(), logrus.Fields{logging.FieldJobID: id}))
+	job := &Job{
+		ID:        id,
+		Tool:      tool,
+		Owner:     owner,
+		Status:    StatusQueued,
+		Arguments: args,
+		CreatedAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		cancel()
+		return nil, ErrClosed
+	}
+	m.jobs[job.ID] = job
+	m.cancels[job.ID] = cancel
+	stale := m.prune(job.CreatedAt)
+	snapshot := job.clone()
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	m.removeRecords(stale)
+	m.persist(job.ID)
+	logging.From(ctx).Infof("提交任务 %s (%s)", job.ID, tool)
+	go m.run(ctx, job, fn)
+	return snapshot, nil
+}
+
+func (m *Manager) run(ctx context.Context, job *Job, fn Func) {
+	defer m.wg.Done()
+
+	m.update(job, func() {
+		now := time.Now()
+		job.Status = StatusRunning
+		job.StartedAt = &now
+		m.publish(job.ID, EventStatus, job)
+	})
+
+	result, err := fn(ctx, func(progress any) {
+		m.reportProgress(job, progress)
+	})
+
+	var data json.RawMessage
+	if err == nil {
+		if data, err = json.Marshal(result); err != nil {
+			err = fmt.Errorf("序列化任务结果失败: %w", err)
+		}
+	}
+
+	m.update(job, func() {
+		now := time.Now()
+		job.FinishedAt = &now
+		switch {
+		case err == nil:
+			job.Status = StatusSucceeded
+			job.Result = data
+		case m.canceled[job.ID]:
+			job.Status = StatusCanceled
+		case m.closed:
+			job.Status = StatusFailed
+			job.Error = &Error{Code: ErrCodeInterrupted, Message: "服务关闭，任务被中断"}
+		default:
+			job.Status = StatusFailed
+			job.Error = m.describe(job, err)
+		}
+
+		m.cancels[job.ID]()
+		delete(m.cancels, job.ID)
+		delete(m.canceled, job.ID)
+
+		m.publish(job.ID, EventStatus, job)
+		m.closeSubscribers(job.ID)
+	})
+
+	if err != nil {
+		logging.From(ctx).Warnf("任务 %s (%s) 结束: %s, %v", job.ID, job.Tool, job.Status, err)
+	} else {
+		logging.From(ctx).Infof("任务 %s (%s) 执行成功", job.ID, job.Tool)
+	}
+}
+
+// update 在锁内修改任务状态，然后在锁外保存
+func (m *Manager) update(job *Job, fn func()) {
+	m.mu.Lock()
+	fn()
+	m.mu.Unlock()
+
+	m.persist(job.ID)
+}
+
+// persist 将任务的最新快照写入 <dir>/<id>.json，先写临时文件再重命名，避免留下不完整的记录。
+// 调用方不能持有 m.mu；写入时再读取快照，即使多次保存交错执行，文件中也总是最新的状态
+func (m *Manager) persist(id string) {
+	if m.dir == "" {
+		return
+	}
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	var data []byte
+	var err error
+	if ok {
+		data, err = json.MarshalIndent(job, "", "  ")
+	}
+	m.mu.Unlock()
+	if !ok {
+		return // 已被清理
+	}
+	if err != nil {
+		logrus.Errorf("序列化任务 %s 失败: %v", id, err)
+		return
+	}
+
+	path := filepath.Join(m.dir, id+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		logrus.Errorf("保存任务 %s 失败: %v", id, err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		logrus.Errorf("保存任务 %s 失败: %v", id, err)
+	}
+}
+
+// expired 任务是否已结束超过 DefaultRetention
+func expired(job *Job, now time.Time) bool {
+	return job.Status.Done() && job.FinishedAt != nil && now.Sub(*job.FinishedAt) > DefaultRetention
+}
+
+// prune 从内存中移除过期的任务，返回它们的 ID，调用方需持有 m.mu 并在锁外调用 removeRecords
+func (m *Manager) prune(now time.Time) []string {
+	var ids []string
+	for id, job := range m.jobs {
+		if expired(job, now) {
+			delete(m.jobs, id)
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// removeRecords 删除任务记录文件
+func (m *Manager) removeRecords(ids []string) {
+	if m.dir == "" {
+		return
+	}
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	for _, id := range ids {
+		path := filepath.Join(m.dir, id+".json")
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("删除过期任务记录 %s 失败: %v", path, err)
+		}
+	}
+}
+
+// Get 返回任务的快照
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return job.clone(), nil
+}
+
+// List 按提交时间倒序返回任务快照，owner 不为空时只返回该 API Key 提交的任务
+func (m *Manager) List(owner string) []*Job {
+	m.mu.Lock()
+	stale := m.prune(time.Now())
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if owner == "" || job.Owner == owner {
+			jobs = append(jobs, job.clone())
+		}
+	}
+	m.mu.Unlock()
+
+	m.removeRecords(stale)
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].CreatedAt.After(jobs[k].CreatedAt)
+	})
+	return jobs
+}
+
+// Cancel 取消未结束的任务。任务在执行函数返回后才变为 canceled
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cancel, running := m.cancels[id]
+	if !running {
+		return nil, ErrFinished
+	}
+
+	logrus.Infof("取消任务 %s (%s)", job.ID, job.Tool)
+	m.canceled[id] = true
+	cancel()
+	return job.clone(), nil
+}
+
+// Close 拒绝新任务并取消所有执行中的任务，等待它们保存结果，最多等待到 ctx 结束
+func (m *Manager) Close(ctx context.Context) {
+	m.mu.Lock()
+	m.closed = true
+	for _, cancel := range m.cancels {
+		cancel()
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logrus.Warnf("等待任务结束超时，未结束的任务将在下次启动时标记为中断")
+	}
+}
+
+// newID 生成任务 ID，形如 20251016-150405-publish_with_video-1a2b3c4d
+func newID(tool string) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, tool)
+
+	return fmt.Sprintf("%s-%s-%s", time.Now().Format("20060102-150405"), name, hex.EncodeToString(suffix))
+}
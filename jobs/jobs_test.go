@@ -0,0 +1,221 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func describeAsInternal(_ *Job, err error) *Error {
+	return &Error{Code: "INTERNAL_ERROR", Message: err.Error()}
+}
+
+func waitDone(t *testing.T, m *Manager, id string) *Job {
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(id)
+		require.NoError(t, err)
+		return job.Status.Done()
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestSubmitSucceededAndFailed(t *testing.T) {
+	m, err := NewManager(t.TempDir(), describeAsInternal)
+	require.NoError(t, err)
+
+	ok, err := m.Submit("list_feeds", "reader", nil, func(ctx context.Context, _ func(any)) (any, error) {
+		return map[string]int{"count": 3}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, ok.Status)
+
+	job := waitDone(t, m, ok.ID)
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.JSONEq(t, `{"count":3}`, string(job.Result))
+	assert.NotNil(t, job.StartedAt)
+
+	bad, err := m.Submit("publish_with_video", "", nil, func(ctx context.Context, _ func(any)) (any, error) {
+		return nil, errors.New("上传失败")
+	})
+	require.NoError(t, err)
+
+	job = waitDone(t, m, bad.ID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, &Error{Code: "INTERNAL_ERROR", Message: "上传失败"}, job.Error)
+
+	assert.Len(t, m.List(""), 2)
+	assert.Len(t, m.List("reader"), 1)
+}
+
+func TestCancel(t *testing.T) {
+	m, err := NewManager("", describeAsInternal)
+	require.NoError(t, err)
+
+	job, err := m.Submit("browse_recommendations", "", nil, func(ctx context.Context, _ func(any)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+
+	_, err = m.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, waitDone(t, m, job.ID).Status)
+
+	_, err = m.Cancel(job.ID)
+	assert.ErrorIs(t, err, ErrFinished)
+	_, err = m.Cancel("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRestartMarksUnfinishedJobsInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir, describeAsInternal)
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	running, err := m.Submit("publish_with_video", "", json.RawMessage(`{"title":"t"}`), func(ctx context.Context, _ func(any)) (any, error) {
+		close(started)
+		select {} // 模拟进程在任务执行中退出
+	})
+	require.NoError(t, err)
+	<-started
+
+	old := &Job{ID: "old", Tool: "list_feeds", Status: StatusSucceeded}
+	finished := time.Now().Add(-DefaultRetention - time.Hour)
+	old.FinishedAt = &finished
+	data, err := json.Marshal(old)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.json"), data, 0o644))
+
+	reloaded, err := NewManager(dir, describeAsInternal)
+	require.NoError(t, err)
+
+	job, err := reloaded.Get(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, ErrCodeInterrupted, job.Error.Code)
+	assert.JSONEq(t, `{"title":"t"}`, string(job.Arguments))
+
+	_, err = reloaded.Get("old")
+	assert.ErrorIs(t, err, ErrNotFound, "过期的任务记录被删除")
+	assert.NoFileExists(t, filepath.Join(dir, "old.json"))
+}
+
+func TestCloseInterruptsRunningJobs(t *testing.T) {
+	m, err := NewManager("", describeAsInternal)
+	require.NoError(t, err)
+
+	job, err := m.Submit("browse_recommendations", "", nil, func(ctx context.Context, _ func(any)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m.Close(ctx)
+
+	got, err := m.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, got.Status)
+	assert.Equal(t, ErrCodeInterrupted, got.Error.Code)
+
+	_, err = m.Submit("list_feeds", "", nil, nil)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestSubscribeReceivesProgress(t *testing.T) {
+	m, err := NewManager("", describeAsInternal)
+	require.NoError(t, err)
+
+	step := make(chan struct{})
+	job, err := m.Submit("publish_with_video", "", nil, func(ctx context.Context, report func(any)) (any, error) {
+		report(map[string]any{"stage": "open_publish_page"})
+		<-step
+		report(map[string]any{"stage": "process_video", "elapsed_seconds": 5})
+		<-step
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(job.ID)
+		return j.Progress != nil
+	}, time.Second, 5*time.Millisecond)
+
+	snapshot, history, events, unsubscribe, err := m.Subscribe(job.ID)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	assert.Equal(t, StatusRunning, snapshot.Status)
+	require.Len(t, history, 2, "订阅前的事件被补发")
+	assert.Equal(t, EventStatus, history[0].Type)
+	assert.Equal(t, EventProgress, history[1].Type)
+	assert.JSONEq(t, `{"stage":"open_publish_page"}`, string(history[1].Data))
+
+	step <- struct{}{}
+	event := <-events
+	assert.Equal(t, 3, event.ID)
+	assert.JSONEq(t, `{"stage":"process_video","elapsed_seconds":5}`, string(event.Data))
+
+	step <- struct{}{}
+	event = <-events
+	assert.Equal(t, EventStatus, event.Type)
+	var final Job
+	require.NoError(t, json.Unmarshal(event.Data, &final))
+	assert.Equal(t, StatusSucceeded, final.Status)
+	assert.JSONEq(t, `"ok"`, string(final.Result))
+
+	_, ok := <-events
+	assert.False(t, ok, "任务结束后关闭 channel")
+
+	_, _, events, _, err = m.Subscribe(job.ID)
+	require.NoError(t, err)
+	_, ok = <-events
+	assert.False(t, ok, "已结束的任务返回已关闭的 channel")
+}
+
+func TestListPrunesExpiredJobs(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir, describeAsInternal)
+	require.NoError(t, err)
+
+	step := make(chan struct{})
+	job, err := m.Submit("publish_with_video", "", nil, func(ctx context.Context, report func(any)) (any, error) {
+		report(map[string]any{"stage": "upload_video"})
+		<-step
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(job.ID)
+		return j.Progress != nil
+	}, time.Second, 5*time.Millisecond)
+
+	data, err := os.ReadFile(filepath.Join(dir, job.ID+".json"))
+	require.NoError(t, err)
+	var saved Job
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, StatusRunning, saved.Status)
+	assert.Nil(t, saved.Progress, "进度不单独写入任务记录")
+
+	close(step)
+	waitDone(t, m, job.ID)
+
+	m.mu.Lock()
+	finished := time.Now().Add(-DefaultRetention - time.Hour)
+	m.jobs[job.ID].FinishedAt = &finished
+	m.mu.Unlock()
+
+	assert.Empty(t, m.List(""))
+	assert.NoFileExists(t, filepath.Join(dir, job.ID+".json"))
+}
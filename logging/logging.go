@@ -0,0 +1,101 @@
+// Package logging 统一的日志输出。所有日志通过 logrus 输出，支持文本与 JSON 两种格式；
+// 请求 ID、任务 ID 等字段通过 context 传递到服务层与页面操作，同一个请求的日志可以据此串联起来
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// 串联日志的字段
+const (
+	FieldRequestID = "request_id" // HTTP 或 MCP 请求 ID
+	FieldJobID     = "job_id"     // 后台任务 ID
+	FieldTool      = "tool"       // 工具名
+	FieldAccount   = "account"    // API Key 名称
+)
+
+// RequestIDHeader 携带请求 ID 的 HTTP 头，请求中带有时沿用，否则由服务生成
+const RequestIDHeader = "X-Request-ID"
+
+// Setup 设置日志级别、格式与输出位置，out 为 nil 时输出到标准错误
+func Setup(level, format string, out io.Writer) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("日志级别无效: %q", level)
+	}
+
+	var formatter logrus.Formatter
+	switch format {
+	case FormatText, "":
+		formatter = &logrus.TextFormatter{FullTimestamp: true, TimestampFormat: time.DateTime}
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime: "time",
+				logrus.FieldKeyMsg:  "msg",
+			},
+		}
+	default:
+		return fmt.Errorf("日志格式无效: %q", format)
+	}
+
+	if out == nil {
+		out = os.Stderr
+	}
+	logrus.SetLevel(lvl)
+	logrus.SetFormatter(formatter)
+	logrus.SetOutput(out)
+	return nil
+}
+
+type entryKey struct{}
+
+// With 返回携带附加字段的 ctx，之后通过 From(ctx) 输出的日志都带有这些字段
+func With(ctx context.Context, fields logrus.Fields) context.Context {
+	return context.WithValue(ctx, entryKey{}, From(ctx).WithFields(fields))
+}
+
+// WithRequestID 返回携带请求 ID 的 ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return With(ctx, logrus.Fields{FieldRequestID: id})
+}
+
+// RequestID 返回 ctx 中的请求 ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	id, _ := From(ctx).Data[FieldRequestID].(string)
+	return id
+}
+
+// From 返回带有 ctx 中字段的日志记录器，ctx 为 nil 或没有字段时返回全局记录器
+func From(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// NewID 生成随机的请求 ID
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
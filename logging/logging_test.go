@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromCarriesContextFields(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = With(ctx, logrus.Fields{FieldJobID: "job-1"})
+
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, logrus.Fields{FieldRequestID: "req-1", FieldJobID: "job-1"}, From(ctx).Data)
+}
+
+func TestSetupJSONOutput(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, Setup("info", FormatText, nil))
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, Setup("warn", FormatJSON, &buf))
+
+	ctx := WithRequestID(context.Background(), "req-2")
+	From(ctx).Info("不输出")
+	From(ctx).Warn("发布失败")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line), buf.String())
+	assert.Equal(t, "发布失败", line["msg"])
+	assert.Equal(t, "warning", line["level"])
+	assert.Equal(t, "req-2", line[FieldRequestID])
+
+	assert.Error(t, Setup("verbose", FormatText, nil))
+	assert.Error(t, Setup("info", "xml", nil))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"io"
 	"math/rand"
 	"os"
 	"time"
@@ -9,37 +10,82 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 func main() {
 	rand.Seed(time.Now().UnixNano())
 
-	var (
-		headless bool
-		binPath  string // 浏览器二进制文件路径
-		port     string
-	)
-	flag.BoolVar(&headless, "headless", true, "是否无头模式")
-	flag.StringVar(&binPath, "bin", "", "浏览器二进制文件路径")
-	flag.StringVar(&port, "port", ":18060", "端口")
-	flag.Parse()
-
-	if len(binPath) == 0 {
-		binPath = os.Getenv("ROD_BROWSER_BIN")
-	}
-
-	configs.InitHeadless(headless)
-	configs.SetBinPath(binPath)
-
-	// 初始化全局浏览器管理器配置
-	browser.GetGlobalManager().SetConfig(headless, binPath)
+	cfg := loadConfig()
+	applyConfig(cfg)
 
 	// 初始化服务
 	xiaohongshuService := NewXiaohongshuService()
 
 	// 创建并启动应用服务器
 	appServer := NewAppServer(xiaohongshuService)
-	if err := appServer.Start(port); err != nil {
+	run := func() error { return appServer.Start(cfg.Server.Port) }
+	if cfg.Server.Transport == configs.TransportStdio {
+		run = appServer.ServeStdio
+	}
+	if err := run(); err != nil {
 		logrus.Fatalf("failed to run server: %v", err)
 	}
 }
+
+// loadConfig 按 默认值 < 配置文件 < 环境变量 < 命令行参数 的顺序加载配置并校验
+func loadConfig() *configs.Config {
+	flags, err := configs.ParseFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		logrus.Fatalf("failed to parse flags: %v", err)
+	}
+	configPath := flags.ConfigPath
+
+	// 只有显式指定的参数会覆盖配置文件与环境变量
+	cfg, err := configs.Load(configPath, flags)
+	if err != nil {
+		logrus.Fatalf("failed to load config: %v", err)
+	}
+	if configPath != "" {
+		logrus.Infof("已加载配置文件: %s", configPath)
+	}
+	return cfg
+}
+
+// applyConfig 将配置同步到各个包
+func applyConfig(cfg *configs.Config) {
+	var logOut io.Writer
+	if cfg.Log.File != "" {
+		f, err := os.OpenFile(cfg.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			logrus.Fatalf("failed to open log file: %v", err)
+		}
+		logOut = f
+	}
+	if err := logging.Setup(cfg.Log.Level, cfg.Log.Format, logOut); err != nil {
+		logrus.Fatalf("failed to setup logging: %v", err)
+	}
+
+	configs.Set(cfg)
+	cookies.SetCookiesFilePath(cfg.Paths.CookiesFile)
+	xiaohongshu.SetOrigins(cfg.Site.Origin, cfg.Site.CreatorOrigin)
+
+	if len(cfg.Paths.SelectorsFile) > 0 {
+		if _, err := xiaohongshu.LoadSelectors(cfg.Paths.SelectorsFile); err != nil {
+			logrus.Fatalf("failed to load selectors: %v", err)
+		}
+	}
+
+	// 初始化全局浏览器管理器配置
+	manager := browser.GetGlobalManager()
+	manager.SetConfig(cfg.Browser.Headless, cfg.Browser.BinPath)
+	manager.SetPoolConfig(browser.PoolConfig{
+		MaxPages:      cfg.Browser.MaxPages,
+		MaxReadPages:  cfg.Browser.MaxReadPages,
+		MaxWritePages: cfg.Browser.MaxWritePages,
+	})
+	manager.SetAcquireTimeout(cfg.Browser.AcquireTimeout)
+	manager.SetIdleTimeout(cfg.Browser.IdleTimeout)
+}
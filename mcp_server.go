@@ -3,78 +3,72 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
-// MCP 工具参数结构体定义
-
-// PublishContentArgs 发布内容的参数
-type PublishContentArgs struct {
-	Title   string   `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
-	Content string   `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
-	Images  []string `json:"images" jsonschema:"图片路径列表（至少需要1张图片）。支持两种方式：1. HTTP/HTTPS图片链接（自动下载）；2. 本地图片绝对路径（推荐，如:/Users/user/image.jpg）"`
-	Tags    []string `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
-}
-
-// PublishVideoArgs 发布视频的参数（仅支持本地单个视频文件）
-type PublishVideoArgs struct {
-	Title   string   `json:"title" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
-	Content string   `json:"content" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
-	Video   string   `json:"video" jsonschema:"本地视频绝对路径（仅支持单个视频文件，如:/Users/user/video.mp4）"`
-	Tags    []string `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
-}
+// 工具参数结构体定义，MCP 与 REST（/api/v1）共用。
+// jsonschema 标签生成 MCP 的参数说明，binding 标签用于两端统一的参数校验
 
 // SearchFeedsArgs 搜索内容的参数
 type SearchFeedsArgs struct {
-	Keyword string `json:"keyword" jsonschema:"搜索关键词"`
+	Keyword string `json:"keyword" form:"keyword" binding:"required" jsonschema:"搜索关键词"`
 }
 
 // FeedDetailArgs 获取Feed详情的参数
 type FeedDetailArgs struct {
-	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
-	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	FeedID    string `json:"feed_id" binding:"required" jsonschema:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
 }
 
 // UserProfileArgs 获取用户主页的参数
 type UserProfileArgs struct {
-	UserID    string `json:"user_id" jsonschema:"小红书用户ID，从Feed列表获取"`
-	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	UserID    string `json:"user_id" binding:"required" jsonschema:"小红书用户ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
 }
 
 // PostCommentArgs 发表评论的参数
 type PostCommentArgs struct {
-	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
-	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
-	Content   string `json:"content" jsonschema:"评论内容"`
+	FeedID    string `json:"feed_id" binding:"required" jsonschema:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	Content   string `json:"content" binding:"required" jsonschema:"评论内容"`
+
+	IdempotencyKey string `json:"idempotency_key,omitempty" binding:"omitempty,max=128" jsonschema:"幂等键（可选参数），超时重试时使用同一个值，有效期内（默认24小时）相同幂等键的请求只评论一次并返回首次评论的结果"`
 }
 
 // LikeFeedArgs 点赞参数
 type LikeFeedArgs struct {
-	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
-	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	FeedID    string `json:"feed_id" binding:"required" jsonschema:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
 	Unlike    bool   `json:"unlike,omitempty" jsonschema:"是否取消点赞，true为取消点赞，false或未设置则为点赞"`
 }
 
 // FavoriteFeedArgs 收藏参数
 type FavoriteFeedArgs struct {
-	FeedID    string `json:"feed_id" jsonschema:"小红书笔记ID，从Feed列表获取"`
-	XsecToken string `json:"xsec_token" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
+	FeedID     string `json:"feed_id" binding:"required" jsonschema:"小红书笔记ID，从Feed列表获取"`
+	XsecToken  string `json:"xsec_token" binding:"required" jsonschema:"访问令牌，从Feed列表的xsecToken字段获取"`
 	Unfavorite bool   `json:"unfavorite,omitempty" jsonschema:"是否取消收藏，true为取消收藏，false或未设置则为收藏"`
 }
 
-// BrowseRecommendationsArgs 浏览推荐页参数
+// BrowseRecommendationsArgs 浏览推荐页参数，未设置的字段使用配置文件 browse 段的默认值
 type BrowseRecommendationsArgs struct {
-	Duration            int      `json:"duration,omitempty" jsonschema:"浏览时长（分钟），默认10分钟"`
-	MinScrolls          int      `json:"min_scrolls,omitempty" jsonschema:"每轮最小滚动次数，默认2次"`
-	MaxScrolls          int      `json:"max_scrolls,omitempty" jsonschema:"每轮最大滚动次数，默认6次"`
-	ClickProbability    int      `json:"click_probability,omitempty" jsonschema:"点击笔记的概率(0-100)，默认65%"`
-	InteractProbability int      `json:"interact_probability,omitempty" jsonschema:"在笔记中互动的概率(0-100)，默认60%。互动包括点赞、收藏和评论"`
-	LikeOnlyProbability int      `json:"like_only_probability,omitempty" jsonschema:"在触发互动时，仅点赞不收藏的概率(0-100)，默认30%"`
+	Duration            int      `json:"duration,omitempty" binding:"min=0" jsonschema:"浏览时长（分钟），默认60分钟"`
+	MinScrolls          int      `json:"min_scrolls,omitempty" binding:"min=0" jsonschema:"每轮最小滚动次数，默认1次"`
+	MaxScrolls          int      `json:"max_scrolls,omitempty" binding:"min=0" jsonschema:"每轮最大滚动次数，默认3次"`
+	ClickProbability    int      `json:"click_probability,omitempty" binding:"min=0,max=100" jsonschema:"点击笔记的概率(0-100)，默认100%"`
+	InteractProbability int      `json:"interact_probability,omitempty" binding:"min=0,max=100" jsonschema:"在笔记中互动的概率(0-100)，默认60%。互动包括点赞、收藏和评论"`
+	LikeOnlyProbability int      `json:"like_only_probability,omitempty" binding:"min=0,max=100" jsonschema:"在触发互动时，仅点赞不收藏的概率(0-100)，默认30%"`
 	EnableComment       *bool    `json:"enable_comment,omitempty" jsonschema:"是否启用评论功能，默认true。如果为false则不会评论"`
 	Comments            []string `json:"comments,omitempty" jsonschema:"评论内容列表（可选）。如果提供则使用提供的内容，否则自动从评论区获取"`
-	Instances           int      `json:"instances,omitempty" jsonschema:"并行浏览时使用的浏览器实例数量，默认3个"`
+	Instances           int      `json:"instances,omitempty" binding:"min=0" jsonschema:"并行浏览时使用的浏览器实例数量，默认3个"`
 }
 
 // InitMCPServer 初始化 MCP Server
@@ -83,7 +77,7 @@ func InitMCPServer(appServer *AppServer) *mcp.Server {
 	server := mcp.NewServer(
 		&mcp.Implementation{
 			Name:    "xiaohongshu-mcp",
-			Version: "2.0.0",
+			Version: serviceVersion,
 		},
 		nil,
 	)
@@ -91,231 +85,109 @@ func InitMCPServer(appServer *AppServer) *mcp.Server {
 	// 注册所有工具
 	registerTools(server, appServer)
 
+	// 注册资源
+	registerResources(server, appServer)
+
 	logrus.Info("MCP Server initialized with official SDK")
 
 	return server
 }
 
-// registerTools 注册所有 MCP 工具
+// registerTools 将 registry 中的操作注册为 MCP 工具
 func registerTools(server *mcp.Server, appServer *AppServer) {
-	// 工具 1: 检查登录状态
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "check_login_status",
-			Description: "检查小红书登录状态",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
-			result := appServer.handleCheckLoginStatus(ctx)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 2: 获取登录二维码
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "get_login_qrcode",
-			Description: "获取登录二维码（返回 Base64 图片和超时时间）",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
-			result := appServer.handleGetLoginQrcode(ctx)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 3: 发布内容
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "publish_content",
-			Description: "发布小红书图文内容",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args PublishContentArgs) (*mcp.CallToolResult, any, error) {
-			// 转换参数格式到现有的 handler
-			argsMap := map[string]interface{}{
-				"title":   args.Title,
-				"content": args.Content,
-				"images":  convertStringsToInterfaces(args.Images),
-				"tags":    convertStringsToInterfaces(args.Tags),
-			}
-			result := appServer.handlePublishContent(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 4: 获取Feed列表
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "list_feeds",
-			Description: "获取用户发布的内容列表",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
-			result := appServer.handleListFeeds(ctx)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 5: 搜索内容
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "search_feeds",
-			Description: "搜索小红书内容（需要已登录）",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args SearchFeedsArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"keyword": args.Keyword,
-			}
-			result := appServer.handleSearchFeeds(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 6: 获取Feed详情
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "get_feed_detail",
-			Description: "获取小红书笔记详情，返回笔记内容、图片、作者信息、互动数据（点赞/收藏/分享数）及评论列表",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args FeedDetailArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"feed_id":    args.FeedID,
-				"xsec_token": args.XsecToken,
-			}
-			result := appServer.handleGetFeedDetail(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 7: 获取用户主页
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "user_profile",
-			Description: "获取小红书用户主页，返回用户基本信息，关注、粉丝、获赞量及其笔记内容",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args UserProfileArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"user_id":    args.UserID,
-				"xsec_token": args.XsecToken,
-			}
-			result := appServer.handleUserProfile(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
-
-	// 工具 8: 发表评论
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "post_comment_to_feed",
-			Description: "发表评论到小红书笔记",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args PostCommentArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"feed_id":    args.FeedID,
-				"xsec_token": args.XsecToken,
-				"content":    args.Content,
-			}
-			result := appServer.handlePostComment(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+	for _, t := range appServer.tools {
+		t.registerMCP(server, appServer.auth)
+	}
 
-	// 工具 9: 发布视频（仅本地文件）
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "publish_with_video",
-			Description: "发布小红书视频内容（仅支持本地单个视频文件）",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args PublishVideoArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"title":   args.Title,
-				"content": args.Content,
-				"video":   args.Video,
-				"tags":    convertStringsToInterfaces(args.Tags),
-			}
-			result := appServer.handlePublishVideo(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+	logrus.Infof("Registered %d MCP tools", len(appServer.tools))
+}
 
-	// 工具 10: 点赞笔记
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "like_feed",
-			Description: "为指定笔记点赞或取消点赞（如已点赞将跳过点赞，如未点赞将跳过取消点赞）",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args LikeFeedArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"feed_id":    args.FeedID,
-				"xsec_token": args.XsecToken,
-				"unlike":     args.Unlike,
-			}
-			result := appServer.handleLikeFeed(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+// mcpRequestID 返回 MCP 请求（工具调用、资源读取）的请求 ID：HTTP 传输沿用中间件写入请求头的 X-Request-ID，
+// 其他情况生成新的 ID
+func mcpRequestID(extra *mcp.RequestExtra) string {
+	if extra != nil && extra.Header != nil {
+		if id := extra.Header.Get(logging.RequestIDHeader); id != "" {
+			return id
+		}
+	}
+	return logging.NewID()
+}
 
-	// 工具 11: 收藏笔记
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "favorite_feed",
-			Description: "收藏指定笔记或取消收藏（如已收藏将跳过收藏，如未收藏将跳过取消收藏）",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args FavoriteFeedArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := map[string]interface{}{
-				"feed_id":    args.FeedID,
-				"xsec_token": args.XsecToken,
-				"unfavorite": args.Unfavorite,
-			}
-			result := appServer.handleFavoriteFeed(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+// withMCPProgress 调用方在请求中提供了 progressToken 时，将页面操作的进度转发为 MCP 进度通知。
+// 通知的 progress 为序号，保证递增；阶段、已上传数量、浏览统计等详情位于 _meta.progress，与后台任务的进度事件相同
+func withMCPProgress(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return ctx
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return ctx
+	}
 
-	// 工具 12: 模拟浏览推荐页
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "browse_recommendations",
-			Description: "模拟人类浏览小红书推荐页，包括滚动、随机点击笔记、浏览评论区，并有概率进行点赞、收藏、评论等互动操作",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args BrowseRecommendationsArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := buildBrowseArgsMap(args, false)
-			result := appServer.handleBrowseRecommendations(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+	var mu sync.Mutex
+	var seq float64
+	return xiaohongshu.WithProgress(ctx, func(p xiaohongshu.Progress) {
+		message := p.Message
+		if p.Browse != nil && p.Browse.InstanceID != "" {
+			message = p.Browse.InstanceID + ": " + message
+		}
 
-	// 工具 13: 模拟浏览推荐页（不进行评论）
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "browse_recommendations_without_comment",
-			Description: "模拟人类浏览小红书推荐页，包括滚动、随机点击笔记、浏览评论区，并有概率进行点赞、收藏等互动操作，但不会进行评论",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args BrowseRecommendationsArgs) (*mcp.CallToolResult, any, error) {
-			argsMap := buildBrowseArgsMap(args, true)
-			result := appServer.handleBrowseRecommendationsWithoutComment(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+		// 并行浏览的多个实例会同时上报，加锁保证序号与发送顺序一致
+		mu.Lock()
+		defer mu.Unlock()
+		seq++
+		err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      seq,
+			Message:       message,
+			Meta:          mcp.Meta{"progress": p},
+		})
+		if err != nil {
+			logging.From(ctx).Debugf("发送 MCP 进度通知失败: %v", err)
+		}
+	})
+}
 
-	// 工具 14: 并行模拟浏览推荐页（多浏览器实例）
-	mcp.AddTool(server,
-		&mcp.Tool{
-			Name:        "parallel_browse_recommendations",
-			Description: "使用多个独立浏览器实例并行模拟人类浏览小红书推荐页，每个实例拥有独立的登录状态和 cookies",
-		},
-		func(ctx context.Context, req *mcp.CallToolRequest, args BrowseRecommendationsArgs) (*mcp.CallToolResult, any, error) {
-			instances := args.Instances
-			if instances <= 0 {
-				instances = 3
-			}
+// confirmSchema 发布确认的表单，只有一个勾选项
+var confirmSchema = &jsonschema.Schema{
+	Type: "object",
+	Properties: map[string]*jsonschema.Schema{
+		"confirm": {Type: "boolean", Title: "确认发布", Description: "勾选后立即发布到小红书，发布后无法通过本服务撤回"},
+	},
+	Required: []string{"confirm"},
+}
 
-			argsMap := buildBrowseArgsMap(args, false)
-			argsMap["instances"] = float64(instances)
+// withMCPConfirm 发布前通过 elicitation 向 MCP 客户端的用户展示最终内容并请求确认，
+// 用户拒绝、取消、未勾选或 timeout 内没有回应时放弃发布。客户端不支持 elicitation 时返回错误
+func withMCPConfirm(ctx context.Context, req *mcp.CallToolRequest, timeout time.Duration) (context.Context, error) {
+	if params := req.Session.InitializeParams(); params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return ctx, fmt.Errorf("服务端要求发布前确认，但客户端不支持 elicitation: %w", xiaohongshu.ErrNotConfirmed)
+	}
 
-			result := appServer.handleParallelBrowseRecommendations(ctx, argsMap)
-			return convertToMCPResult(result), nil, nil
-		},
-	)
+	return xiaohongshu.WithConfirm(ctx, func(ctx context.Context, preview xiaohongshu.PublishPreview) error {
+		elicitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		res, err := req.Session.Elicit(elicitCtx, &mcp.ElicitParams{
+			Message:         formatPublishPreview(preview),
+			RequestedSchema: confirmSchema,
+		})
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(elicitCtx.Err(), context.DeadlineExceeded):
+			return fmt.Errorf("%v 内未确认: %w", timeout, xiaohongshu.ErrNotConfirmed)
+		case err != nil:
+			return fmt.Errorf("请求用户确认失败: %v: %w", err, xiaohongshu.ErrNotConfirmed)
+		case res.Action != "accept":
+			return fmt.Errorf("用户未确认（%s）: %w", res.Action, xiaohongshu.ErrNotConfirmed)
+		case res.Content["confirm"] != true:
+			return fmt.Errorf("用户未勾选确认发布: %w", xiaohongshu.ErrNotConfirmed)
+		}
 
-	logrus.Infof("Registered %d MCP tools", 14)
+		logging.From(ctx).Info("用户已确认发布")
+		return nil
+	}), nil
 }
 
 // convertToMCPResult 将自定义的 MCPToolResult 转换为官方 SDK 的格式
@@ -343,39 +215,16 @@ func convertToMCPResult(result *MCPToolResult) *mcp.CallToolResult {
 		}
 	}
 
-	return &mcp.CallToolResult{
-		Content: contents,
-		IsError: result.IsError,
+	callResult := &mcp.CallToolResult{
+		Content:           contents,
+		IsError:           result.IsError,
+		StructuredContent: result.StructuredContent,
 	}
-}
-
-// buildBrowseArgsMap 将 BrowseRecommendationsArgs 转换为 handler 使用的通用参数 map
-func buildBrowseArgsMap(args BrowseRecommendationsArgs, forceDisableComment bool) map[string]interface{} {
-	argsMap := map[string]interface{}{
-		"duration":              float64(args.Duration),
-		"min_scrolls":           float64(args.MinScrolls),
-		"max_scrolls":           float64(args.MaxScrolls),
-		"click_probability":     float64(args.ClickProbability),
-		"interact_probability":  float64(args.InteractProbability),
-		"like_only_probability": float64(args.LikeOnlyProbability),
-		"comments":              convertStringsToInterfaces(args.Comments),
-	}
-
-	if forceDisableComment {
-		argsMap["enable_comment"] = false
-	} else if args.EnableComment != nil {
-		argsMap["enable_comment"] = *args.EnableComment
-	}
-
-	return argsMap
-}
-
-
-// convertStringsToInterfaces 辅助函数：将 []string 转换为 []interface{}
-func convertStringsToInterfaces(strs []string) []interface{} {
-	result := make([]interface{}, len(strs))
-	for i, s := range strs {
-		result[i] = s
+	if result.ErrorCode != "" {
+		callResult.Meta = mcp.Meta{"error_code": result.ErrorCode}
+		if result.DebugBundle != "" {
+			callResult.Meta["debug_bundle"] = result.DebugBundle
+		}
 	}
-	return result
+	return callResult
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// newTestMCPServer 创建只注册了一个 work 工具的 MCP 服务端，run 为工具的实现
+func newTestMCPServer(run func(ctx context.Context, in noArgs) (*echoOut, error)) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	work := &typedTool[noArgs, *echoOut]{
+		toolSpec: toolSpec{Name: "work", Description: "work", Method: http.MethodPost, Path: "/work", FailMessage: "work 失败"},
+		run:      run,
+	}
+	work.registerMCP(server, nil)
+	return server
+}
+
+func TestMCPProgressNotifications(t *testing.T) {
+	newTestAppServer(t, nil)
+	server := newTestMCPServer(func(ctx context.Context, _ noArgs) (*echoOut, error) {
+		for i := 1; i <= 3; i++ {
+			xiaohongshu.ReportProgress(ctx, xiaohongshu.Progress{
+				Stage:   xiaohongshu.StageUploadImages,
+				Message: fmt.Sprintf("已上传 %d/3 张图片", i),
+				Current: i,
+				Total:   3,
+			})
+		}
+
+		// 并行浏览的实例同时上报进度
+		var wg sync.WaitGroup
+		for _, id := range []string{"instance-1", "instance-2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				xiaohongshu.ReportProgress(ctx, xiaohongshu.Progress{
+					Stage:   xiaohongshu.StageBrowse,
+					Message: "浏览中",
+					Browse:  &xiaohongshu.BrowseProgress{InstanceID: id},
+				})
+			}()
+		}
+		wg.Wait()
+		return &echoOut{}, nil
+	})
+
+	var mu sync.Mutex
+	var received []*mcp.ProgressNotificationParams
+	session := connectMCP(t, server, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, req.Params)
+		},
+	})
+
+	// SetProgressToken 在 Meta 为 nil 时不生效，直接设置 _meta
+	params := &mcp.CallToolParams{Meta: mcp.Meta{"progressToken": "progress-1"}, Name: "work", Arguments: map[string]any{}}
+	res, err := session.CallTool(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 5
+	}, 2*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var messages []string
+	for i, p := range received {
+		assert.Equal(t, "progress-1", p.ProgressToken)
+		assert.Equal(t, float64(i+1), p.Progress, "progress 为递增的序号")
+		assert.Contains(t, p.Meta, "progress", "进度详情位于 _meta.progress")
+		messages = append(messages, p.Message)
+	}
+	assert.Equal(t, []string{"已上传 1/3 张图片", "已上传 2/3 张图片", "已上传 3/3 张图片"}, messages[:3])
+	assert.ElementsMatch(t, []string{"instance-1: 浏览中", "instance-2: 浏览中"}, messages[3:], "并行浏览的进度带有实例 ID")
+}
+
+func TestMCPProgressWithoutToken(t *testing.T) {
+	newTestAppServer(t, nil)
+	server := newTestMCPServer(func(ctx context.Context, _ noArgs) (*echoOut, error) {
+		xiaohongshu.ReportProgress(ctx, xiaohongshu.Progress{Stage: xiaohongshu.StageSubmit, Message: "发布中"})
+		return &echoOut{}, nil
+	})
+
+	notified := make(chan struct{}, 1)
+	session := connectMCP(t, server, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(context.Context, *mcp.ProgressNotificationClientRequest) {
+			notified <- struct{}{}
+		},
+	})
+
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "work", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	select {
+	case <-notified:
+		t.Fatal("请求未提供 progressToken 时不应发送进度通知")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMCPCancelAbortsWork(t *testing.T) {
+	newTestAppServer(t, nil)
+	started := make(chan struct{})
+	aborted := make(chan error, 1)
+	server := newTestMCPServer(func(ctx context.Context, _ noArgs) (*echoOut, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			aborted <- ctx.Err()
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			aborted <- nil
+			return &echoOut{}, nil
+		}
+	})
+	session := connectMCP(t, server, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "work", Arguments: map[string]any{}})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case err := <-aborted:
+		assert.ErrorIs(t, err, context.Canceled, "客户端取消调用后操作的 ctx 随之取消")
+	case <-time.After(2 * time.Second):
+		t.Fatal("客户端取消调用后操作没有中止")
+	}
+}
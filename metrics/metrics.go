@@ -0,0 +1,131 @@
+// Package metrics 以 Prometheus 文本格式暴露服务的运行指标（操作耗时与结果、浏览器页面池、登录状态等）
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "xhs"
+
+// CodeOK 操作成功时 code 标签的值
+const CodeOK = "OK"
+
+// 等待浏览器页面的结果
+const (
+	WaitAcquired = "acquired" // 获得页面
+	WaitTimeout  = "timeout"  // 等待超时（BROWSER_BUSY）
+	WaitCanceled = "canceled" // 调用方取消
+)
+
+var (
+	operationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "operation_duration_seconds",
+		Help:      "操作（MCP 工具 / REST 接口 / 后台任务）的执行耗时，包括等待浏览器页面的时间",
+		Buckets:   []float64{0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"operation", "transport"})
+
+	operationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "操作的执行次数，code 为错误码，成功时为 OK",
+	}, []string{"operation", "transport", "code"})
+
+	browserQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "browser_queue_depth",
+		Help:      "正在排队等待浏览器页面的操作数",
+	})
+
+	browserActivePages = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "browser_active_pages",
+		Help:      "当前占用的浏览器页面数",
+	})
+
+	browserWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "browser_wait_seconds",
+		Help:      "操作等待浏览器页面的时间，kind 为操作类型，result 为 acquired / timeout / canceled",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 180, 300},
+	}, []string{"kind", "result"})
+
+	browserLaunches = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "browser_launches_total",
+		Help:      "启动浏览器实例的次数",
+	})
+
+	browserRestarts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "browser_restarts_total",
+		Help:      "因崩溃或断开而丢弃浏览器实例的次数",
+	})
+
+	loggedIn = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "logged_in",
+		Help:      "最近一次检查到的小红书登录状态，1 为已登录，0 为未登录",
+	})
+
+	accountActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "account_actions_total",
+		Help:      "按 API Key 统计的发布与互动操作次数，result 为 success / error",
+	}, []string{"account", "operation", "result"})
+)
+
+// Handler 返回 /metrics 的 HTTP handler
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveOperation 记录一次操作的耗时与结果，transport 为 mcp / rest / job
+func ObserveOperation(operation, transport, code string, elapsed time.Duration) {
+	operationDuration.WithLabelValues(operation, transport).Observe(elapsed.Seconds())
+	operationsTotal.WithLabelValues(operation, transport, code).Inc()
+}
+
+// CountAccountAction 记录 API Key 发起的一次发布或互动操作
+func CountAccountAction(account, operation string, ok bool) {
+	result := "success"
+	if !ok {
+		result = "error"
+	}
+	accountActions.WithLabelValues(account, operation, result).Inc()
+}
+
+// SetBrowserPool 更新浏览器页面池的排队与占用情况
+func SetBrowserPool(queueDepth, activePages int) {
+	browserQueueDepth.Set(float64(queueDepth))
+	browserActivePages.Set(float64(activePages))
+}
+
+// ObserveBrowserWait 记录一次等待浏览器页面的时间与结果
+func ObserveBrowserWait(kind, result string, waited time.Duration) {
+	browserWait.WithLabelValues(kind, result).Observe(waited.Seconds())
+}
+
+// BrowserLaunched 记录一次浏览器启动
+func BrowserLaunched() {
+	browserLaunches.Inc()
+}
+
+// BrowserRestarted 记录一次因浏览器失效导致的重启
+func BrowserRestarted() {
+	browserRestarts.Inc()
+}
+
+// SetLoggedIn 更新登录状态
+func SetLoggedIn(ok bool) {
+	if ok {
+		loggedIn.Set(1)
+	} else {
+		loggedIn.Set(0)
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveOperation(t *testing.T) {
+	ObserveOperation("publish_content", "rest", CodeOK, 2*time.Second)
+	ObserveOperation("publish_content", "mcp", "NOT_LOGGED_IN", time.Second)
+	ObserveOperation("publish_content", "mcp", "NOT_LOGGED_IN", time.Second)
+
+	expected := `
+# HELP xhs_operations_total 操作的执行次数，code 为错误码，成功时为 OK
+# TYPE xhs_operations_total counter
+xhs_operations_total{code="NOT_LOGGED_IN",operation="publish_content",transport="mcp"} 2
+xhs_operations_total{code="OK",operation="publish_content",transport="rest"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(operationsTotal, strings.NewReader(expected)))
+	assert.Equal(t, 2, testutil.CollectAndCount(operationDuration), "耗时按 operation 与 transport 区分，不含 code")
+}
+
+func TestCountAccountAction(t *testing.T) {
+	CountAccountAction("bot", "like_feed", true)
+	CountAccountAction("bot", "like_feed", false)
+	CountAccountAction("anonymous", "like_feed", true)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(accountActions.WithLabelValues("bot", "like_feed", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(accountActions.WithLabelValues("bot", "like_feed", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(accountActions.WithLabelValues("anonymous", "like_feed", "success")))
+}
+
+func TestBrowserPoolAndLogin(t *testing.T) {
+	SetBrowserPool(2, 3)
+	assert.Equal(t, 2.0, testutil.ToFloat64(browserQueueDepth))
+	assert.Equal(t, 3.0, testutil.ToFloat64(browserActivePages))
+	SetBrowserPool(0, 1)
+	assert.Equal(t, 0.0, testutil.ToFloat64(browserQueueDepth))
+	assert.Equal(t, 1.0, testutil.ToFloat64(browserActivePages))
+
+	ObserveBrowserWait("write", WaitAcquired, 10*time.Millisecond)
+	ObserveBrowserWait("write", WaitTimeout, time.Minute)
+	assert.Equal(t, 2, testutil.CollectAndCount(browserWait, "xhs_browser_wait_seconds"))
+
+	SetLoggedIn(true)
+	assert.Equal(t, 1.0, testutil.ToFloat64(loggedIn))
+	SetLoggedIn(false)
+	assert.Equal(t, 0.0, testutil.ToFloat64(loggedIn))
+}
@@ -2,17 +2,79 @@ package main
 
 import (
 	"net/http"
+	"runtime/debug"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
 )
 
-// corsMiddleware CORS 中间件
-func corsMiddleware() gin.HandlerFunc {
+// maxRequestIDLength 沿用客户端请求 ID 时允许的最大长度
+const maxRequestIDLength = 128
+
+// requestLogMiddleware 为每个请求分配请求 ID 并输出访问日志。
+// 请求头中带有 X-Request-ID 时沿用，否则生成新的 ID；ID 写入响应头，并通过 ctx 传递给服务层，
+// MCP 请求经由请求头传递给工具调用
+func requestLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		start := time.Now()
+
+		id := c.GetHeader(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = logging.NewID()
+			c.Request.Header.Set(logging.RequestIDHeader, id)
+		}
+		c.Header(logging.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+
+		logging.From(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+		}).Info("HTTP 请求")
+	}
+}
+
+// validRequestID 检查客户端传入的请求 ID，只接受长度有限的可见 ASCII 字符
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// corsMiddleware CORS 中间件，只允许配置中列出的来源跨域访问，"*" 表示任意来源。
+// 预检请求在鉴权之前直接返回
+func corsMiddleware(origins []string) gin.HandlerFunc {
+	allowAll := slices.Contains(origins, "*")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin != "" {
+			c.Header("Vary", "Origin")
+			switch {
+			case allowAll:
+				c.Header("Access-Control-Allow-Origin", "*")
+			case slices.Contains(origins, origin):
+				c.Header("Access-Control-Allow-Origin", origin)
+			default:
+				logging.From(c.Request.Context()).Warnf("拒绝跨域请求 %s %s from origin %s", c.Request.Method, c.Request.URL.Path, origin)
+			}
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Mcp-Session-Id, Mcp-Protocol-Version, X-Request-ID")
+			c.Header("Access-Control-Expose-Headers", "Mcp-Session-Id, X-Request-ID")
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -23,10 +85,10 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-// errorHandlingMiddleware 错误处理中间件
+// errorHandlingMiddleware 错误处理中间件，panic 与调用栈通过请求的日志记录器输出
 func errorHandlingMiddleware() gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
-		logrus.Errorf("服务器内部错误: %v, path: %s", recovered, c.Request.URL.Path)
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logging.From(c.Request.Context()).Errorf("服务器内部错误: %v, path: %s\n%s", recovered, c.Request.URL.Path, debug.Stack())
 
 		respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR",
 			"服务器内部错误", recovered)
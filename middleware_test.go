@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+func TestCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		method  string
+		origin  string
+		status  int
+		allowed string
+	}{
+		{"允许的 origin", []string{"https://a.example.com"}, http.MethodGet, "https://a.example.com", http.StatusOK, "https://a.example.com"},
+		{"未允许的 origin", []string{"https://a.example.com"}, http.MethodGet, "https://b.example.com", http.StatusOK, ""},
+		{"origin 需要完全一致", []string{"https://a.example.com"}, http.MethodGet, "https://a.example.com:8443", http.StatusOK, ""},
+		{"允许所有 origin", []string{"*"}, http.MethodGet, "https://b.example.com", http.StatusOK, "*"},
+		{"预检请求", []string{"https://a.example.com"}, http.MethodOptions, "https://a.example.com", http.StatusNoContent, "https://a.example.com"},
+		{"未允许 origin 的预检请求", []string{"https://a.example.com"}, http.MethodOptions, "https://b.example.com", http.StatusNoContent, ""},
+		{"未配置时不允许跨域", nil, http.MethodOptions, "https://a.example.com", http.StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRoutes(newTestAppServer(t, func(cfg *configs.Config) { cfg.Server.CORSOrigins = tt.origins }))
+
+			req := httptest.NewRequest(tt.method, "/healthz", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.allowed, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "Origin", w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestCORSPreflightSkipsAuth(t *testing.T) {
+	router := setupRoutes(newTestAppServer(t, func(cfg *configs.Config) {
+		cfg.Server.CORSOrigins = []string{"https://app.example.com"}
+		cfg.Auth.APIKeys = []configs.APIKey{{Name: "reader", Key: testReaderKey, Scopes: []string{configs.ScopeRead}}}
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/publish", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "预检请求不携带 API Key")
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+
+	req = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "没有 Origin 时不返回跨域头")
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/ratelimit"
+)
+
+// openAPIVersion 生成的文档遵循的 OpenAPI 版本，3.1 的 schema 与 JSON Schema 兼容
+const openAPIVersion = "3.1.0"
+
+// pathParamPattern 匹配 gin 路由中的路径参数，如 :id
+var pathParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// schemaOptions 生成 schema 时对特殊类型的处理
+var schemaOptions = &jsonschema.ForOptions{
+	TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+		reflect.TypeFor[json.RawMessage](): {}, // 任意 JSON
+	},
+}
+
+// openAPIOperation 一个接口的文档来源
+type openAPIOperation struct {
+	Method      string
+	Path        string // gin 路由，相对 /api/v1
+	ID          string
+	Summary     string
+	Scope       string
+	Request     reflect.Type
+	Response    reflect.Type
+	ContentType string
+	Tool        bool
+	Action      string // 受频率限制的写操作类型
+	Confirm     bool   // 开启发布前确认时需要用户确认
+}
+
+// operations 汇总工具注册表与 apiRoutes 中的接口，与 setupRoutes 注册的路由一致
+func (s *AppServer) operations() []openAPIOperation {
+	var ops []openAPIOperation
+	for _, t := range s.tools {
+		spec := t.spec()
+		in, out := t.docTypes()
+		ops = append(ops, openAPIOperation{
+			Method:   spec.Method,
+			Path:     spec.Path,
+			ID:       spec.Name,
+			Summary:  spec.Description,
+			Scope:    spec.Scope,
+			Request:  in,
+			Response: out,
+			Tool:     true,
+			Action:   spec.Action,
+			Confirm:  spec.Confirm,
+		})
+	}
+
+	for _, r := range s.apiRoutes() {
+		ops = append(ops, openAPIOperation{
+			Method:      r.Method,
+			Path:        r.Path,
+			ID:          operationID(r.Method, r.Path),
+			Summary:     r.Summary,
+			Scope:       r.Scope,
+			Request:     r.Request,
+			Response:    r.Response,
+			ContentType: r.ContentType,
+		})
+	}
+	return ops
+}
+
+// operationID 为非工具接口生成 operationId，如 GET /jobs/:id -> get_jobs_id
+func operationID(method, path string) string {
+	id := strings.NewReplacer("/", "_", ":", "", ".", "_").Replace(strings.Trim(path, "/"))
+	return strings.ToLower(method) + "_" + id
+}
+
+// buildOpenAPI 根据请求与响应的 Go 类型生成 OpenAPI 文档
+func (s *AppServer) buildOpenAPI() map[string]any {
+	paths := map[string]map[string]any{}
+	for _, op := range s.operations() {
+		path := pathParamPattern.ReplaceAllString(op.Path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(op.Method)] = op.document()
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "xiaohongshu-mcp REST API",
+			"version":     serviceVersion,
+			"description": "小红书 MCP 服务的 REST 接口，与 MCP 工具一一对应。由服务根据请求与响应的结构体生成",
+		},
+		"servers": []map[string]any{{"url": "/api/v1"}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"SuccessResponse": schemaFor(reflect.TypeFor[SuccessResponse]()),
+				"ErrorResponse":   schemaFor(reflect.TypeFor[ErrorResponse]()),
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "错误响应，code 为错误码",
+					"content": map[string]any{
+						"application/json": map[string]any{
+							"schema": map[string]any{"$ref": "#/components/schemas/ErrorResponse"},
+						},
+					},
+				},
+			},
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "配置了 API Key 时需要，见 docs/API.md 的认证一节",
+				},
+			},
+		},
+		"security": []map[string][]string{{"bearerAuth": {}}},
+	}
+}
+
+// document 生成单个接口的 Operation Object
+func (op openAPIOperation) document() map[string]any {
+	doc := map[string]any{
+		"operationId": op.ID,
+		"summary":     op.Summary,
+		"tags":        []string{strings.SplitN(strings.Trim(op.Path, "/"), "/", 2)[0]},
+		"responses":   op.responses(),
+	}
+	if op.Scope != "" {
+		doc["x-required-scope"] = op.Scope
+	}
+	if op.Action != "" {
+		doc["x-rate-limit-action"] = op.Action
+	}
+
+	params := pathParams(op.Path)
+	if op.Request != nil {
+		if op.Method == http.MethodGet {
+			params = append(params, queryParams(op.Request)...)
+		} else {
+			doc["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": requestSchema(op.Request)},
+				},
+			}
+		}
+	}
+	if len(params) > 0 {
+		doc["parameters"] = params
+	}
+	return doc
+}
+
+// responses 生成成功响应与错误响应，工具接口的错误响应由 serviceErrorMappings 生成
+func (op openAPIOperation) responses() map[string]any {
+	var ok map[string]any
+	switch {
+	case op.ContentType != "":
+		ok = map[string]any{
+			"description": "成功",
+			"content":     map[string]any{op.ContentType: map[string]any{}},
+		}
+	default:
+		data := map[string]any{}
+		if op.Response != nil {
+			data = schemaFor(op.Response)
+		}
+		ok = map[string]any{
+			"description": "成功，data 为返回数据",
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"success": map[string]any{"type": "boolean"},
+							"data":    data,
+							"message": map[string]any{"type": "string"},
+						},
+						"required": []string{"success", "data", "message"},
+					},
+				},
+			},
+		}
+	}
+
+	errRef := map[string]any{"$ref": "#/components/responses/Error"}
+	responses := map[string]any{
+		"200": ok,
+		"400": errRef,
+		"401": errRef,
+		"403": errRef,
+		"500": errRef,
+	}
+	if strings.Contains(op.Path, ":") {
+		responses["404"] = errRef
+	}
+	if op.Tool {
+		for status, codes := range op.toolErrorCodes() {
+			responses[strconv.Itoa(status)] = errorResponse("错误码: " + strings.Join(codes, "、"))
+		}
+	}
+	return responses
+}
+
+// toolErrorCodes 按 serviceErrorMappings 汇总工具接口可能返回的 HTTP 状态码及其错误码。
+// 只有受频率限制的操作返回 RATE_LIMITED，只有支持 idempotency_key 的操作返回 IDEMPOTENCY_*，
+// 只有需要发布前确认的操作返回 CONFIRMATION_REQUIRED
+func (op openAPIOperation) toolErrorCodes() map[int][]string {
+	idempotent := op.Request != nil && op.Request.Implements(reflect.TypeFor[idempotentRequest]())
+
+	codes := map[int][]string{
+		http.StatusUnauthorized:        {errCodeUnauthorized},
+		http.StatusInternalServerError: {errCodeInternalError},
+		http.StatusServiceUnavailable:  {errCodeBrowserBusy},
+	}
+	for _, m := range serviceErrorMappings {
+		switch {
+		case errors.Is(m.target, ratelimit.ErrRateLimited) && op.Action == "":
+			continue
+		case strings.HasPrefix(m.code, "IDEMPOTENCY_") && !idempotent:
+			continue
+		case errors.Is(m.target, errConfirmUnavailable) && !op.Confirm:
+			continue
+		}
+		if !slices.Contains(codes[m.status], m.code) {
+			codes[m.status] = append(codes[m.status], m.code)
+		}
+	}
+	return codes
+}
+
+// errorResponse 生成使用 ErrorResponse 结构的错误响应
+func errorResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/ErrorResponse"},
+			},
+		},
+	}
+}
+
+// schemaFor 生成 Go 类型的 JSON Schema，无法生成时退回到任意 JSON
+func schemaFor(t reflect.Type) map[string]any {
+	schema, err := jsonschema.ForType(t, schemaOptions)
+	if err != nil {
+		logrus.Warnf("生成 %s 的 schema 失败: %v", t, err)
+		return map[string]any{}
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		logrus.Warnf("序列化 %s 的 schema 失败: %v", t, err)
+		return map[string]any{}
+	}
+
+	var doc map[string]any
+	_ = json.Unmarshal(data, &doc)
+	return doc
+}
+
+// requestSchema 生成请求体的 schema。必填字段以 binding:"required" 为准，与 REST 的参数校验一致
+func requestSchema(t reflect.Type) map[string]any {
+	schema := schemaFor(t)
+	if required := requiredFields(t); len(required) > 0 {
+		schema["required"] = required
+	} else {
+		delete(schema, "required")
+	}
+	return schema
+}
+
+// requiredFields 返回带有 binding:"required" 的字段的 JSON 名称
+func requiredFields(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if slices.Contains(strings.Split(f.Tag.Get("binding"), ","), "required") {
+			names = append(names, jsonName(f, "json"))
+		}
+	}
+	return names
+}
+
+// queryParams 将 GET 接口的参数结构体展开为 query 参数
+func queryParams(t reflect.Type) []map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	required := requiredFields(t)
+	props, _ := schemaFor(t)["properties"].(map[string]any)
+
+	var params []map[string]any
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		jsonKey := jsonName(f, "json")
+		param := map[string]any{
+			"name":     jsonName(f, "form"),
+			"in":       "query",
+			"required": slices.Contains(required, jsonKey),
+			"schema":   props[jsonKey],
+		}
+		if desc := f.Tag.Get("jsonschema"); desc != "" {
+			param["description"] = desc
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// pathParams 将 gin 路由中的 :name 转换为路径参数
+func pathParams(path string) []map[string]any {
+	var params []map[string]any
+	for _, m := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, map[string]any{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+	return params
+}
+
+// jsonName 返回字段在指定标签（json 或 form）中的名称，form 标签缺省时使用 json 标签
+func jsonName(f reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+	if name == "" && tag != "json" {
+		return jsonName(f, "json")
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+)
+
+// openAPIHandler 返回 OpenAPI 文档，文档在首次请求时生成
+func (s *AppServer) openAPIHandler(c *gin.Context) {
+	openAPIOnce.Do(func() {
+		doc := s.buildOpenAPI()
+		data, err := json.Marshal(doc)
+		if err != nil {
+			logrus.Errorf("生成 OpenAPI 文档失败: %v", err)
+			return
+		}
+		openAPIDoc = data
+	})
+
+	if openAPIDoc == nil {
+		respondError(c, http.StatusInternalServerError, errCodeInternalError, "生成 OpenAPI 文档失败", nil)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPIDoc)
+}
+
+// swaggerUIAssets 嵌入的 Swagger UI 静态资源（swagger-ui-dist），离线环境下也能查看文档
+//
+//go:embed assets/swagger-ui/swagger-ui-bundle.js assets/swagger-ui/swagger-ui.css
+var swaggerUIAssets embed.FS
+
+// swaggerUIFiles /api/v1/docs 下提供的静态资源
+var swaggerUIFiles = []string{"swagger-ui-bundle.js", "swagger-ui.css"}
+
+// swaggerUIHandler 返回加载 /api/v1/openapi.json 的 Swagger UI 页面
+func swaggerUIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// swaggerUIPage Swagger UI 页面，静态资源由服务在 /api/v1/docs/ 下提供
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>xiaohongshu-mcp API</title>
+  <link rel="stylesheet" href="docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="docs/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "openapi.json",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true
+    });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPICoversRegisteredRoutes(t *testing.T) {
+	s := newTestAppServer(t, nil)
+	router := setupRoutes(s)
+	paths, ok := s.buildOpenAPI()["paths"].(map[string]map[string]any)
+	require.True(t, ok)
+
+	for _, r := range router.Routes() {
+		path, ok := strings.CutPrefix(r.Path, "/api/v1")
+		if !ok || path == "/openapi.json" || strings.HasPrefix(path, "/docs") {
+			continue
+		}
+		path = pathParamPattern.ReplaceAllString(path, "{$1}")
+		assert.Contains(t, paths[path], strings.ToLower(r.Method), "%s %s 不在 OpenAPI 文档中", r.Method, r.Path)
+	}
+}
+
+func TestOpenAPIToolErrorResponses(t *testing.T) {
+	paths := newTestAppServer(t, nil).buildOpenAPI()["paths"].(map[string]map[string]any)
+	responses := func(path, method string) map[string]any {
+		return paths[path][method].(map[string]any)["responses"].(map[string]any)
+	}
+
+	publish := responses("/publish", "post")
+	for _, status := range []string{"404", "409", "410", "422", "429", "499", "502", "503", "504"} {
+		assert.Contains(t, publish, status)
+	}
+	assert.Contains(t, publish["409"].(map[string]any)["description"], "IDEMPOTENCY_IN_FLIGHT")
+	assert.Contains(t, publish["422"].(map[string]any)["description"], "CONTENT_REJECTED")
+	assert.Contains(t, publish["403"].(map[string]any)["description"], "CONFIRMATION_REQUIRED")
+
+	search := responses("/feeds/search", "get")
+	assert.NotContains(t, search, "429", "只读操作不受频率限制")
+	assert.NotContains(t, search, "409", "只读操作不支持 idempotency_key")
+	assert.Contains(t, search, "410")
+	assert.NotContains(t, search["403"].(map[string]any)["description"], "CONFIRMATION_REQUIRED")
+}
+
+func TestSwaggerUIServesEmbeddedAssets(t *testing.T) {
+	router := setupRoutes(newTestAppServer(t, nil))
+
+	for _, path := range []string{"/api/v1/docs", "/api/v1/docs/swagger-ui-bundle.js", "/api/v1/docs/swagger-ui.css"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.NotContains(t, w.Body.String(), "cdn.jsdelivr.net", path)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/ratelimit"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// errCodeRateLimited 写操作超出额度时的错误码
+const errCodeRateLimited = "RATE_LIMITED"
+
+// newRateLimiter 按配置创建写操作的限流器，记录文件不可用时退回到只保存在内存中
+func newRateLimiter() *ratelimit.Limiter {
+	cfg := configs.Get()
+	limits := make(map[string]ratelimit.Limit)
+	for action, l := range cfg.RateLimits.Limits() {
+		limits[action] = ratelimit.Limit(l)
+	}
+
+	path := cfg.Paths.RateLimitFile
+	l, err := ratelimit.New(path, limits)
+	if err != nil {
+		logrus.Errorf("操作记录文件 %s 不可用，频率限制的计数将只保存在内存中: %v", path, err)
+		l, _ = ratelimit.New("", limits)
+	}
+	return l
+}
+
+// reserveInteractions 浏览推荐页时的点赞、收藏、评论逐次占用调用方对应操作的额度，超出时跳过该次互动
+func (s *AppServer) reserveInteractions(ctx context.Context) context.Context {
+	if s.limits == nil {
+		return ctx
+	}
+	account := accountFrom(ctx)
+	return xiaohongshu.WithReserve(ctx, func(action string) (func(), error) {
+		return s.limits.Reserve(account, action)
+	})
+}
@@ -0,0 +1,249 @@
+// Package ratelimit 按账号限制写操作（发布、评论、点赞、收藏）的频率，避免短时间内的大量操作导致小红书账号被风控。
+// 每次操作的时间记录在本地文件中，服务重启后额度继续生效
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Limit 一类操作的额度，各项为 0 表示不限制
+type Limit struct {
+	Window      time.Duration // 滚动窗口的长度
+	PerWindow   int           // 滚动窗口内最多执行的次数
+	PerDay      int           // 每个自然日（本地时区）最多执行的次数
+	MinInterval time.Duration // 两次操作之间的最小间隔
+}
+
+func (l Limit) enabled() bool {
+	return l.PerWindow > 0 || l.PerDay > 0 || l.MinInterval > 0
+}
+
+// retention 计算额度需要保留的操作记录时长
+func (l Limit) retention() time.Duration {
+	return max(l.Window, l.MinInterval, 24*time.Hour)
+}
+
+// 触发的限制
+const (
+	ReasonMinInterval = "min_interval"
+	ReasonWindow      = "window"
+	ReasonDay         = "day"
+)
+
+// ErrRateLimited 操作超出额度
+var ErrRateLimited = errors.New("操作过于频繁")
+
+// Error 操作超出额度时返回，RetryAt 为下一次可以执行的时间
+type Error struct {
+	Account string
+	Action  string
+	Reason  string // 触发的限制，见 Reason*
+	Limit   Limit
+	RetryAt time.Time
+}
+
+func (e *Error) Error() string {
+	var rule string
+	switch e.Reason {
+	case ReasonMinInterval:
+		rule = fmt.Sprintf("两次操作需间隔 %v", e.Limit.MinInterval)
+	case ReasonWindow:
+		rule = fmt.Sprintf("%v 内最多 %d 次", e.Limit.Window, e.Limit.PerWindow)
+	case ReasonDay:
+		rule = fmt.Sprintf("每天最多 %d 次", e.Limit.PerDay)
+	}
+	return fmt.Sprintf("%s 操作已达到上限（%s），下次可执行时间 %s（%v 后）",
+		e.Action, rule, e.RetryAt.Format(time.DateTime), e.RetryAfter().Round(time.Second))
+}
+
+func (e *Error) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RetryAfter 距离下一次可以执行还需等待的时间
+func (e *Error) RetryAfter() time.Duration {
+	return max(time.Until(e.RetryAt), 0)
+}
+
+// state 持久化的操作记录：账号 -> 操作类型 -> 执行时间（升序）
+type state map[string]map[string][]time.Time
+
+// Limiter 按账号与操作类型记录执行时间并检查额度，可并发使用
+type Limiter struct {
+	path   string
+	limits map[string]Limit
+
+	mu      sync.Mutex
+	history state
+	now     func() time.Time
+}
+
+// New 创建限流器并加载 path 中的操作记录，path 为空时只保存在内存中。没有配置额度的操作不受限制
+func New(path string, limits map[string]Limit) (*Limiter, error) {
+	l := &Limiter{
+		path:    path,
+		limits:  limits,
+		history: state{},
+		now:     time.Now,
+	}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取操作记录失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.history); err != nil {
+		return nil, fmt.Errorf("解析操作记录 %s 失败: %w", path, err)
+	}
+	if l.history == nil {
+		l.history = state{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pruneLocked()
+	return l, nil
+}
+
+// Reserve 检查 account 能否执行 action，可以时立即记录一次执行并返回 release；
+// 操作实际上没有执行（如等待浏览器超时）时调用 release 归还额度。超出额度时返回 *Error
+func (l *Limiter) Reserve(account, action string) (release func(), err error) {
+	limit, ok := l.limits[action]
+	if !ok || !limit.enabled() {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	times := l.history[account][action]
+	if retryAt, reason := check(limit, times, now); reason != "" {
+		logrus.Warnf("账号 %s 的 %s 操作超出额度（%s），下次可执行时间 %s",
+			account, action, reason, retryAt.Format(time.DateTime))
+		return nil, &Error{Account: account, Action: action, Reason: reason, Limit: limit, RetryAt: retryAt}
+	}
+
+	if l.history[account] == nil {
+		l.history[account] = map[string][]time.Time{}
+	}
+	l.history[account][action] = append(times, now)
+	l.persistLocked()
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.removeLocked(account, action, now)
+		})
+	}
+	return release, nil
+}
+
+// check 返回最早可以再次执行的时间与触发的限制，未超出额度时 reason 为空。
+// 同时触发多个限制时取最晚的时间
+func check(limit Limit, times []time.Time, now time.Time) (retryAt time.Time, reason string) {
+	exceed := func(at time.Time, r string) {
+		if at.After(retryAt) {
+			retryAt, reason = at, r
+		}
+	}
+
+	if n := len(times); n > 0 && limit.MinInterval > 0 {
+		if next := times[n-1].Add(limit.MinInterval); next.After(now) {
+			exceed(next, ReasonMinInterval)
+		}
+	}
+
+	if limit.PerWindow > 0 {
+		start := now.Add(-limit.Window)
+		inWindow := times[sort.Search(len(times), func(i int) bool { return times[i].After(start) }):]
+		if len(inWindow) >= limit.PerWindow {
+			// 窗口内较早的记录过期后才有空余额度
+			exceed(inWindow[len(inWindow)-limit.PerWindow].Add(limit.Window), ReasonWindow)
+		}
+	}
+
+	if limit.PerDay > 0 {
+		y, m, d := now.Date()
+		today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+		idx := sort.Search(len(times), func(i int) bool { return !times[i].Before(today) })
+		if len(times)-idx >= limit.PerDay {
+			exceed(today.AddDate(0, 0, 1), ReasonDay)
+		}
+	}
+	return retryAt, reason
+}
+
+// removeLocked 删除一次执行记录，调用方需持有 l.mu
+func (l *Limiter) removeLocked(account, action string, at time.Time) {
+	times := l.history[account][action]
+	for i := len(times) - 1; i >= 0; i-- {
+		if times[i].Equal(at) {
+			l.history[account][action] = append(times[:i:i], times[i+1:]...)
+			l.persistLocked()
+			return
+		}
+	}
+}
+
+// pruneLocked 删除不再影响额度的过期记录，调用方需持有 l.mu
+func (l *Limiter) pruneLocked() {
+	now := l.now()
+	for account, actions := range l.history {
+		for action, times := range actions {
+			limit := l.limits[action]
+			cutoff := now.Add(-limit.retention())
+			idx := sort.Search(len(times), func(i int) bool { return times[i].After(cutoff) })
+			if times = times[idx:]; len(times) == 0 || !limit.enabled() {
+				delete(actions, action)
+			} else {
+				actions[action] = times
+			}
+		}
+		if len(actions) == 0 {
+			delete(l.history, account)
+		}
+	}
+}
+
+// persistLocked 清理过期记录后写入文件，先写临时文件再重命名。调用方需持有 l.mu
+func (l *Limiter) persistLocked() {
+	l.pruneLocked()
+	if l.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(l.history)
+	if err != nil {
+		logrus.Errorf("序列化操作记录失败: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		logrus.Errorf("保存操作记录失败: %v", err)
+		return
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		logrus.Errorf("保存操作记录失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		logrus.Errorf("保存操作记录失败: %v", err)
+	}
+}
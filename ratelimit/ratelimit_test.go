@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newLimiter(t *testing.T, path string, limits map[string]Limit, clock *fakeClock) *Limiter {
+	l, err := New(path, limits)
+	require.NoError(t, err)
+	l.now = clock.now
+	return l
+}
+
+func TestReserveEnforcesIntervalAndWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 10, 16, 10, 0, 0, 0, time.Local)}
+	l := newLimiter(t, "", map[string]Limit{
+		"comment": {Window: time.Hour, PerWindow: 2, MinInterval: time.Minute},
+	}, clock)
+
+	_, err := l.Reserve("bot", "comment")
+	require.NoError(t, err)
+
+	_, err = l.Reserve("bot", "comment")
+	var limited *Error
+	require.ErrorAs(t, err, &limited)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, ReasonMinInterval, limited.Reason)
+	assert.Equal(t, clock.t.Add(time.Minute), limited.RetryAt)
+
+	_, err = l.Reserve("other", "comment")
+	assert.NoError(t, err, "不同账号分别计数")
+	_, err = l.Reserve("bot", "like")
+	assert.NoError(t, err, "未配置额度的操作不受限制")
+
+	clock.advance(10 * time.Minute)
+	_, err = l.Reserve("bot", "comment")
+	require.NoError(t, err)
+
+	clock.advance(10 * time.Minute)
+	_, err = l.Reserve("bot", "comment")
+	require.ErrorAs(t, err, &limited)
+	assert.Equal(t, ReasonWindow, limited.Reason)
+	assert.Equal(t, time.Date(2025, 10, 16, 11, 0, 0, 0, time.Local), limited.RetryAt, "第一次操作滑出窗口后可以继续")
+
+	clock.advance(40 * time.Minute)
+	_, err = l.Reserve("bot", "comment")
+	assert.NoError(t, err)
+}
+
+func TestReserveEnforcesDailyLimitAndRelease(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 10, 16, 23, 0, 0, 0, time.Local)}
+	l := newLimiter(t, "", map[string]Limit{"publish": {PerDay: 1}}, clock)
+
+	release, err := l.Reserve("", "publish")
+	require.NoError(t, err)
+	release()
+	release()
+
+	_, err = l.Reserve("", "publish")
+	require.NoError(t, err, "归还后额度可以再次使用")
+
+	_, err = l.Reserve("", "publish")
+	var limited *Error
+	require.ErrorAs(t, err, &limited)
+	assert.Equal(t, ReasonDay, limited.Reason)
+	assert.Equal(t, time.Date(2025, 10, 17, 0, 0, 0, 0, time.Local), limited.RetryAt)
+
+	clock.advance(time.Hour)
+	_, err = l.Reserve("", "publish")
+	assert.NoError(t, err, "第二天重新计数")
+}
+
+func TestReservePersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	clock := &fakeClock{t: time.Now()}
+	limits := map[string]Limit{"like": {Window: time.Hour, PerWindow: 1}}
+
+	l := newLimiter(t, path, limits, clock)
+	_, err := l.Reserve("bot", "like")
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "操作记录只允许服务自身读写")
+
+	reloaded := newLimiter(t, path, limits, clock)
+	_, err = reloaded.Reserve("bot", "like")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
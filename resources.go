@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/ttlcache"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// MCP 资源，客户端可以将其作为上下文附加到对话中。
+// 资源通过对应的工具读取（同样的参数校验、权限与监控指标），结果短时间缓存，重复读取不再打开页面
+const (
+	resourceHomeFeed     = "xhs://feed/home"
+	resourceNoteTemplate = "xhs://note/{feed_id}{?xsec_token}"
+	resourceUserTemplate = "xhs://user/{user_id}{?xsec_token}"
+
+	resourceMIMEType = "application/json"
+)
+
+// mcpResources 读取 MCP 资源
+type mcpResources struct {
+	app   *AppServer
+	cache *ttlcache.Cache[[]byte]
+
+	mu sync.Mutex
+	// tokens 最近一次读取首页推荐时笔记与作者对应的 xsec_token，
+	// 读取其中的笔记或用户时 URI 可以不带 xsec_token
+	tokens map[string]string
+}
+
+// registerResources 注册 MCP 资源与资源模板
+func registerResources(server *mcp.Server, appServer *AppServer) {
+	r := &mcpResources{
+		app:    appServer,
+		cache:  ttlcache.New[[]byte](configs.Get().Resources.CacheTTL),
+		tokens: map[string]string{},
+	}
+
+	server.AddResource(&mcp.Resource{
+		URI:         resourceHomeFeed,
+		Name:        "home_feed",
+		Title:       "小红书首页推荐",
+		Description: "当前首页推荐的笔记列表，包括标题、作者、互动数据以及读取笔记详情所需的 feed_id 与 xsecToken",
+		MIMEType:    resourceMIMEType,
+	}, r.handler("list_feeds", r.readHomeFeed))
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resourceNoteTemplate,
+		Name:        "note",
+		Title:       "小红书笔记",
+		Description: "笔记详情与评论列表。xsec_token 从 Feed 列表的 xsecToken 字段获取，笔记在最近读取的 xhs://feed/home 中时可以省略",
+		MIMEType:    resourceMIMEType,
+	}, r.handler("get_feed_detail", r.readNote))
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resourceUserTemplate,
+		Name:        "user",
+		Title:       "小红书用户主页",
+		Description: "用户基本信息，关注、粉丝、获赞量及其笔记。xsec_token 从 Feed 列表的 xsecToken 字段获取，用户的笔记在最近读取的 xhs://feed/home 中时可以省略",
+		MIMEType:    resourceMIMEType,
+	}, r.handler("user_profile", r.readUser))
+
+	logrus.Info("Registered MCP resources: xhs://feed/home, xhs://note/{feed_id}, xhs://user/{user_id}")
+}
+
+// resourceReader 解析资源 URI，返回缓存的 key 与工具参数
+type resourceReader func(u *url.URL) (key string, args any, err error)
+
+// handler 返回读取资源的处理函数：检查权限后通过工具 toolName 读取，结果按 key 缓存。
+// 工具在注册时查找，不存在时 panic，使服务在启动时而不是读取资源时暴露错误
+func (r *mcpResources) handler(toolName string, read resourceReader) mcp.ResourceHandler {
+	t := r.app.findTool(toolName)
+	if t == nil {
+		panic(fmt.Sprintf("MCP 资源引用了不存在的工具 %s", toolName))
+	}
+	spec := t.spec()
+
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		ctx = logging.WithRequestID(ctx, mcpRequestID(req.Extra))
+		logging.From(ctx).Infof("MCP 资源: %s", uri)
+
+		if err := r.app.auth.authorizeMCP(req.Extra, "资源 "+uri, spec.Scope); err != nil {
+			return nil, resourceError(spec, uri, err)
+		}
+		ctx = withAccount(ctx, mcpAccount(req.Extra))
+
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, resourceError(spec, uri, fmt.Errorf("%w: %v", errInvalidArgs, err))
+		}
+		key, args, err := read(u)
+		if err != nil {
+			return nil, resourceError(spec, uri, err)
+		}
+
+		data, err := r.cache.Get(ctx, key, func(ctx context.Context) ([]byte, error) {
+			raw, err := json.Marshal(args)
+			if err != nil {
+				return nil, err
+			}
+			out, err := t.invokeJSON(ctx, transportResource, raw)
+			if err != nil {
+				return nil, err
+			}
+			r.rememberTokens(out)
+			return json.MarshalIndent(out, "", "  ")
+		})
+		if err != nil {
+			return nil, resourceError(spec, uri, err)
+		}
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      uri,
+				MIMEType: resourceMIMEType,
+				Text:     string(data),
+			}},
+		}, nil
+	}
+}
+
+func (r *mcpResources) readHomeFeed(*url.URL) (string, any, error) {
+	return "feed/home", noArgs{}, nil
+}
+
+func (r *mcpResources) readNote(u *url.URL) (string, any, error) {
+	feedID, err := resourceID(u, "feed_id")
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := r.xsecToken(u, "note/"+feedID)
+	if err != nil {
+		return "", nil, err
+	}
+	return "note/" + feedID, FeedDetailArgs{FeedID: feedID, XsecToken: token}, nil
+}
+
+func (r *mcpResources) readUser(u *url.URL) (string, any, error) {
+	userID, err := resourceID(u, "user_id")
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := r.xsecToken(u, "user/"+userID)
+	if err != nil {
+		return "", nil, err
+	}
+	return "user/" + userID, UserProfileArgs{UserID: userID, XsecToken: token}, nil
+}
+
+// resourceID 返回 xhs://note/{id} 形式 URI 中的 id，name 为缺少时提示的参数名
+func resourceID(u *url.URL, name string) (string, error) {
+	id := strings.Trim(u.Path, "/")
+	if id == "" || strings.Contains(id, "/") {
+		return "", fmt.Errorf("%w: 缺少%s参数", errInvalidArgs, name)
+	}
+	return id, nil
+}
+
+// xsecToken 返回 URI 中的 xsec_token，没有时使用最近一次读取首页推荐时记录的值
+func (r *mcpResources) xsecToken(u *url.URL, key string) (string, error) {
+	if token := u.Query().Get("xsec_token"); token != "" {
+		return token, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.tokens[key]; ok {
+		return token, nil
+	}
+	return "", fmt.Errorf("%w: 缺少xsec_token参数，请在 URI 中携带 ?xsec_token=...，或先读取 %s", errInvalidArgs, resourceHomeFeed)
+}
+
+// rememberTokens 首页推荐读取成功后记录其中笔记与作者的 xsec_token，替换上一次的记录
+func (r *mcpResources) rememberTokens(out any) {
+	feeds, ok := out.(*FeedsListResponse)
+	if !ok || feeds == nil {
+		return
+	}
+
+	tokens := make(map[string]string, 2*len(feeds.Feeds))
+	for _, feed := range feeds.Feeds {
+		if feed.XsecToken == "" {
+			continue
+		}
+		tokens["note/"+feed.ID] = feed.XsecToken
+		if userID := feed.NoteCard.User.UserID; userID != "" {
+			tokens["user/"+userID] = feed.XsecToken
+		}
+	}
+
+	r.mu.Lock()
+	r.tokens = tokens
+	r.mu.Unlock()
+}
+
+// resourceError 转换读取资源的错误：笔记不存在时返回资源不存在（-32002），
+// 其余错误的文本以错误码开头，错误码与对应的工具相同
+func resourceError(spec toolSpec, uri string, err error) error {
+	if errors.Is(err, xiaohongshu.ErrNoteNotFound) {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	return fmt.Errorf("[%s] 读取资源 %s 失败: %w", spec.errorCode(err), uri, err)
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"net/http"
+	"reflect"
 
 	"github.com/gin-gonic/gin"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/jobs"
+	"github.com/xpzouying/xiaohongshu-mcp/metrics"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // setupRoutes 设置路由配置
@@ -13,15 +19,23 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
 
-	// 添加中间件
+	// 添加中间件：请求 ID 与访问日志最先执行，panic 也能记录到对应的请求
+	router.Use(requestLogMiddleware())
 	router.Use(errorHandlingMiddleware())
-	router.Use(corsMiddleware())
+	router.Use(corsMiddleware(configs.Get().Server.CORSOrigins))
 
-	// 健康检查
+	// 健康检查：/healthz 为存活检查（/health 为兼容旧版的别名），/readyz 为就绪检查
 	router.GET("/health", healthHandler)
+	router.GET("/healthz", healthHandler)
+	router.GET("/readyz", readyHandler)
+
+	// OpenAPI 文档与 Swagger UI，不需要鉴权
+	router.GET("/api/v1/openapi.json", appServer.openAPIHandler)
+	router.GET("/api/v1/docs", swaggerUIHandler)
+	for _, name := range swaggerUIFiles {
+		router.StaticFileFS("/api/v1/docs/"+name, "assets/swagger-ui/"+name, http.FS(swaggerUIAssets))
+	}
 
 	// MCP 端点 - 使用官方 SDK 的 Streamable HTTP Handler
 	mcpHandler := mcp.NewStreamableHTTPHandler(
@@ -32,23 +46,93 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 			JSONResponse: true, // 支持 JSON 响应
 		},
 	)
-	router.Any("/mcp", gin.WrapH(mcpHandler))
-	router.Any("/mcp/*path", gin.WrapH(mcpHandler))
+	// 启用鉴权时，连接需要有效的 API Key，每个工具调用再按工具所需权限检查
+	authz := appServer.auth
+	mcpEndpoint := gin.WrapH(authz.wrapMCP(mcpHandler))
+	router.Any("/mcp", authz.authenticate(), mcpEndpoint)
+	router.Any("/mcp/*path", authz.authenticate(), mcpEndpoint)
+
+	// Prometheus 指标，启用鉴权时需要 read 权限
+	router.GET("/metrics", authz.authenticate(), authz.requireScope(configs.ScopeRead), gin.WrapH(metrics.Handler()))
 
 	// API 路由组
-	api := router.Group("/api/v1")
+	api := router.Group("/api/v1", authz.authenticate())
 	{
-		api.GET("/login/status", appServer.checkLoginStatusHandler)
-		api.GET("/login/qrcode", appServer.getLoginQrcodeHandler)
-		api.POST("/publish", appServer.publishHandler)
-		api.POST("/publish_video", appServer.publishVideoHandler)
-		api.GET("/feeds/list", appServer.listFeedsHandler)
-		api.GET("/feeds/search", appServer.searchFeedsHandler)
-		api.POST("/feeds/detail", appServer.getFeedDetailHandler)
-		api.POST("/user/profile", appServer.userProfileHandler)
-		api.POST("/feeds/comment", appServer.postCommentHandler)
-		api.POST("/browse/recommendations", appServer.browseRecommendationsHandler)
+		// 与 MCP 工具一一对应的操作，见 tools.go
+		for _, t := range appServer.tools {
+			spec := t.spec()
+			api.Handle(spec.Method, spec.Path, authz.requireScope(spec.Scope), t.handleREST)
+		}
+
+		for _, r := range appServer.apiRoutes() {
+			handlers := []gin.HandlerFunc{r.Handler}
+			if r.Scope != "" {
+				handlers = append([]gin.HandlerFunc{authz.requireScope(r.Scope)}, handlers...)
+			}
+			api.Handle(r.Method, r.Path, handlers...)
+		}
 	}
 
 	return router
 }
+
+// apiRoute /api/v1 下不属于工具注册表的接口。setupRoutes 据此注册路由，openapi.go 据此生成文档
+type apiRoute struct {
+	Method      string
+	Path        string
+	Summary     string
+	Scope       string       // 所需权限，为空时由 handler 自行检查
+	Request     reflect.Type // JSON 请求体的类型，nil 表示没有请求体
+	Response    reflect.Type // 成功响应中 data 的类型
+	ContentType string       // 非 JSON 响应的类型，如 text/event-stream
+	Handler     gin.HandlerFunc
+}
+
+// apiRoutes 声明 /api/v1 下不属于工具注册表的接口
+func (s *AppServer) apiRoutes() []apiRoute {
+	return []apiRoute{
+		// 后台任务：提交时按工具检查权限，未拥有 admin 权限的 API Key 只能查看自己提交的任务
+		{
+			Method: http.MethodPost, Path: "/jobs", Summary: "提交后台任务，所需权限与 tool 相同",
+			Request: reflect.TypeFor[SubmitJobRequest](), Response: reflect.TypeFor[jobs.Job](),
+			Handler: s.submitJobHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/jobs", Summary: "按提交时间倒序列出任务",
+			Response: reflect.TypeFor[[]jobs.Job](), Handler: s.listJobsHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/jobs/:id", Summary: "查询任务状态与结果",
+			Response: reflect.TypeFor[jobs.Job](), Handler: s.getJobHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/jobs/:id/events", Summary: "以 Server-Sent Events 订阅任务状态与进度",
+			ContentType: "text/event-stream", Handler: s.jobEventsHandler,
+		},
+		{
+			Method: http.MethodPost, Path: "/jobs/:id/cancel", Summary: "取消未结束的任务",
+			Response: reflect.TypeFor[jobs.Job](), Handler: s.cancelJobHandler,
+		},
+
+		{
+			Method: http.MethodGet, Path: "/browser/status", Summary: "获取浏览器页面池状态",
+			Scope: configs.ScopeRead, Response: reflect.TypeFor[browser.Status](), Handler: browserStatusHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/selectors", Summary: "查看当前生效的页面选择器",
+			Scope: configs.ScopeRead, Response: reflect.TypeFor[xiaohongshu.SelectorsInfo](), Handler: selectorsHandler,
+		},
+		{
+			Method: http.MethodPost, Path: "/selectors/reload", Summary: "重新加载选择器文件",
+			Scope: configs.ScopeAdmin, Response: reflect.TypeFor[xiaohongshu.SelectorsInfo](), Handler: reloadSelectorsHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/debug/bundles/:id", Summary: "查看调试包的步骤记录与文件列表",
+			Scope: configs.ScopeAdmin, Response: reflect.TypeFor[xiaohongshu.BundleTrace](), Handler: debugBundleHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/debug/bundles/:id/:file", Summary: "下载调试包中的文件",
+			Scope: configs.ScopeAdmin, ContentType: "application/octet-stream", Handler: debugBundleFileHandler,
+		},
+	}
+}
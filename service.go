@@ -2,22 +2,41 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/mattn/go-runewidth"
-	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/headless_browser"
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
 	"github.com/xpzouying/xiaohongshu-mcp/recommendation"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
+// 各业务操作申请浏览器页面时使用的配额描述。
+// 只读操作可以并发执行；写操作受写配额限制；
+// 发布、登录、浏览推荐页共享页面状态或耗时很长，按 Serial 分组串行执行。
+var (
+	opCheckLoginStatus = browser.Op{Name: "check_login_status", Kind: browser.OpRead}
+	opLogin            = browser.Op{Name: "login", Kind: browser.OpWrite, Serial: "login"}
+	opListFeeds        = browser.Op{Name: "list_feeds", Kind: browser.OpRead}
+	opSearchFeeds      = browser.Op{Name: "search_feeds", Kind: browser.OpRead}
+	opFeedDetail       = browser.Op{Name: "get_feed_detail", Kind: browser.OpRead}
+	opUserProfile      = browser.Op{Name: "user_profile", Kind: browser.OpRead}
+	opPublish          = browser.Op{Name: "publish_content", Kind: browser.OpWrite, Serial: "publish"}
+	opPublishVideo     = browser.Op{Name: "publish_with_video", Kind: browser.OpWrite, Serial: "publish"}
+	opPostComment      = browser.Op{Name: "post_comment_to_feed", Kind: browser.OpWrite}
+	opLikeFeed         = browser.Op{Name: "like_feed", Kind: browser.OpWrite}
+	opFavoriteFeed     = browser.Op{Name: "favorite_feed", Kind: browser.OpWrite}
+	opBrowse           = browser.Op{Name: "browse_recommendations", Kind: browser.OpWrite, Serial: "browse"}
+)
+
 // XiaohongshuService 小红书业务服务
 type XiaohongshuService struct{}
 
@@ -26,12 +45,14 @@ func NewXiaohongshuService() *XiaohongshuService {
 	return &XiaohongshuService{}
 }
 
-// PublishRequest 发布请求
+// PublishRequest 发布请求，同时作为 publish_content 工具的参数
 type PublishRequest struct {
-	Title   string   `json:"title" binding:"required"`
-	Content string   `json:"content" binding:"required"`
-	Images  []string `json:"images" binding:"required,min=1"`
-	Tags    []string `json:"tags,omitempty"`
+	Title   string   `json:"title" binding:"required" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
+	Content string   `json:"content" binding:"required" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
+	Images  []string `json:"images" binding:"required,min=1" jsonschema:"图片路径列表（至少需要1张图片）。支持两种方式：1. HTTP/HTTPS图片链接（自动下载）；2. 本地图片绝对路径（推荐，如:/Users/user/image.jpg）"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
+
+	IdempotencyKey string `json:"idempotency_key,omitempty" binding:"omitempty,max=128" jsonschema:"幂等键（可选参数），超时重试时使用同一个值，有效期内（默认24小时）相同幂等键的请求只发布一次并返回首次发布的结果"`
 }
 
 // LoginStatusResponse 登录状态响应
@@ -56,12 +77,14 @@ type PublishResponse struct {
 	PostID  string `json:"post_id,omitempty"`
 }
 
-// PublishVideoRequest 发布视频请求（仅支持本地单个视频文件）
+// PublishVideoRequest 发布视频请求（仅支持本地单个视频文件），同时作为 publish_with_video 工具的参数
 type PublishVideoRequest struct {
-	Title   string   `json:"title" binding:"required"`
-	Content string   `json:"content" binding:"required"`
-	Video   string   `json:"video" binding:"required"`
-	Tags    []string `json:"tags,omitempty"`
+	Title   string   `json:"title" binding:"required" jsonschema:"内容标题（小红书限制：最多20个中文字或英文单词）"`
+	Content string   `json:"content" binding:"required" jsonschema:"正文内容，不包含以#开头的标签内容，所有话题标签都用tags参数来生成和提供即可"`
+	Video   string   `json:"video" binding:"required" jsonschema:"本地视频绝对路径（仅支持单个视频文件，如:/Users/user/video.mp4）"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"话题标签列表（可选参数），如 [美食, 旅行, 生活]"`
+
+	IdempotencyKey string `json:"idempotency_key,omitempty" binding:"omitempty,max=128" jsonschema:"幂等键（可选参数），超时重试时使用同一个值，有效期内（默认24小时）相同幂等键的请求只发布一次并返回首次发布的结果"`
 }
 
 // PublishVideoResponse 发布视频响应
@@ -88,16 +111,20 @@ type UserProfileResponse struct {
 
 // CheckLoginStatus 检查登录状态
 func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatusResponse, error) {
-	page, release := getPageWithRelease()
-	defer release()
+	var isLoggedIn bool
+	err := withPage(ctx, opCheckLoginStatus, func(page *rod.Page) error {
+		loginAction := xiaohongshu.NewLogin(page)
 
-	loginAction := xiaohongshu.NewLogin(page)
-
-	isLoggedIn, err := loginAction.CheckLoginStatus(ctx)
+		var err error
+		isLoggedIn, err = loginAction.CheckLoginStatus(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	recordLoginState(isLoggedIn)
+
 	response := &LoginStatusResponse{
 		IsLoggedIn: isLoggedIn,
 		Username:   configs.Username,
@@ -108,7 +135,10 @@ func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatus
 
 // GetLoginQrcode 获取登录的扫码二维码
 func (s *XiaohongshuService) GetLoginQrcode(ctx context.Context) (*LoginQrcodeResponse, error) {
-	page, release := getPageWithRelease()
+	page, release, err := getPageWithRelease(ctx, opLogin)
+	if err != nil {
+		return nil, err
+	}
 
 	deferFunc := func() {
 		release()
@@ -130,18 +160,27 @@ func (s *XiaohongshuService) GetLoginQrcode(ctx context.Context) (*LoginQrcodeRe
 	if err != nil {
 		return nil, err
 	}
+	recordLoginState(loggedIn)
 
 	timeout := 4 * time.Minute
 
 	if !loggedIn {
+		log := logging.From(ctx)
 		go func() {
 			ctxTimeout, cancel := context.WithTimeout(context.Background(), timeout)
 			defer cancel()
 			defer deferFunc()
+			defer func() {
+				// 后台等待扫码的 goroutine 没有上层 recover，panic 会导致整个服务退出
+				if r := recover(); r != nil {
+					log.Errorf("等待扫码登录时发生异常: %v", r)
+				}
+			}()
 
 			if loginAction.WaitForLogin(ctxTimeout) {
+				recordLoginState(true)
 				if er := saveCookies(page); er != nil {
-					logrus.Errorf("failed to save cookies: %v", er)
+					log.Errorf("failed to save cookies: %v", er)
 				}
 			}
 		}()
@@ -205,16 +244,16 @@ func (s *XiaohongshuService) processImages(images []string) ([]string, error) {
 
 // publishContent 执行内容发布
 func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohongshu.PublishImageContent) error {
-	page, release := getPageWithRelease()
-	defer release()
-
-	action, err := xiaohongshu.NewPublishImageAction(page)
-	if err != nil {
-		return err
-	}
+	return withPage(ctx, opPublish, func(page *rod.Page) error {
+		xiaohongshu.ReportProgress(ctx, xiaohongshu.Progress{Stage: xiaohongshu.StageOpenPublish, Message: "打开发布页"})
+		action, err := xiaohongshu.NewPublishImageAction(page)
+		if err != nil {
+			return err
+		}
 
-	// 执行发布
-	return action.Publish(ctx, content)
+		// 执行发布
+		return action.Publish(ctx, content)
+	})
 }
 
 // PublishVideo 发布视频（本地文件）
@@ -256,27 +295,29 @@ func (s *XiaohongshuService) PublishVideo(ctx context.Context, req *PublishVideo
 
 // publishVideo 执行视频发布
 func (s *XiaohongshuService) publishVideo(ctx context.Context, content xiaohongshu.PublishVideoContent) error {
-	page, release := getPageWithRelease()
-	defer release()
+	return withPage(ctx, opPublishVideo, func(page *rod.Page) error {
+		xiaohongshu.ReportProgress(ctx, xiaohongshu.Progress{Stage: xiaohongshu.StageOpenPublish, Message: "打开发布页"})
+		action, err := xiaohongshu.NewPublishVideoAction(page)
+		if err != nil {
+			return err
+		}
 
-	action, err := xiaohongshu.NewPublishVideoAction(page)
-	if err != nil {
-		return err
-	}
-
-	return action.PublishVideo(ctx, content)
+		return action.PublishVideo(ctx, content)
+	})
 }
 
 // ListFeeds 获取Feeds列表
 func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	// 创建 Feeds 列表 action
-	action := xiaohongshu.NewFeedsListAction(page)
-
-	// 获取 Feeds 列表
-	feeds, err := action.GetFeedsList(ctx)
+	var feeds []xiaohongshu.Feed
+	err := withPage(ctx, opListFeeds, func(page *rod.Page) error {
+		// 创建 Feeds 列表 action
+		action := xiaohongshu.NewFeedsListAction(page)
+
+		// 获取 Feeds 列表
+		var err error
+		feeds, err = action.GetFeedsList(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -290,12 +331,14 @@ func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse,
 }
 
 func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string) (*FeedsListResponse, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	action := xiaohongshu.NewSearchAction(page)
+	var feeds []xiaohongshu.Feed
+	err := withPage(ctx, opSearchFeeds, func(page *rod.Page) error {
+		action := xiaohongshu.NewSearchAction(page)
 
-	feeds, err := action.Search(ctx, keyword)
+		var err error
+		feeds, err = action.Search(ctx, keyword)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -310,14 +353,16 @@ func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string) (*
 
 // GetFeedDetail 获取Feed详情
 func (s *XiaohongshuService) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (*FeedDetailResponse, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	// 创建 Feed 详情 action
-	action := xiaohongshu.NewFeedDetailAction(page)
-
-	// 获取 Feed 详情
-	result, err := action.GetFeedDetail(ctx, feedID, xsecToken)
+	var result *xiaohongshu.FeedDetailResponse
+	err := withPage(ctx, opFeedDetail, func(page *rod.Page) error {
+		// 创建 Feed 详情 action
+		action := xiaohongshu.NewFeedDetailAction(page)
+
+		// 获取 Feed 详情
+		var err error
+		result, err = action.GetFeedDetail(ctx, feedID, xsecToken)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -332,12 +377,14 @@ func (s *XiaohongshuService) GetFeedDetail(ctx context.Context, feedID, xsecToke
 
 // UserProfile 获取用户信息
 func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken string) (*UserProfileResponse, error) {
-	page, release := getPageWithRelease()
-	defer release()
+	var result *xiaohongshu.UserProfileResponse
+	err := withPage(ctx, opUserProfile, func(page *rod.Page) error {
+		action := xiaohongshu.NewUserProfileAction(page)
 
-	action := xiaohongshu.NewUserProfileAction(page)
-
-	result, err := action.UserProfile(ctx, userID, xsecToken)
+		var err error
+		result, err = action.UserProfile(ctx, userID, xsecToken)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -353,12 +400,12 @@ func (s *XiaohongshuService) UserProfile(ctx context.Context, userID, xsecToken
 
 // PostCommentToFeed 发表评论到Feed
 func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content string) (*PostCommentResponse, error) {
-	page, release := getPageWithRelease()
-	defer release()
+	err := withPage(ctx, opPostComment, func(page *rod.Page) error {
+		action := xiaohongshu.NewCommentFeedAction(page)
 
-	action := xiaohongshu.NewCommentFeedAction(page)
-
-	if err := action.PostComment(ctx, feedID, xsecToken, content); err != nil {
+		return action.PostComment(ctx, feedID, xsecToken, content)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -367,11 +414,10 @@ func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsec
 
 // LikeFeed 点赞笔记
 func (s *XiaohongshuService) LikeFeed(ctx context.Context, feedID, xsecToken string) (*ActionResult, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	action := xiaohongshu.NewLikeAction(page)
-	if err := action.Like(ctx, feedID, xsecToken); err != nil {
+	err := withPage(ctx, opLikeFeed, func(page *rod.Page) error {
+		return xiaohongshu.NewLikeAction(page).Like(ctx, feedID, xsecToken)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &ActionResult{FeedID: feedID, Success: true, Message: "点赞成功或已点赞"}, nil
@@ -379,11 +425,10 @@ func (s *XiaohongshuService) LikeFeed(ctx context.Context, feedID, xsecToken str
 
 // UnlikeFeed 取消点赞笔记
 func (s *XiaohongshuService) UnlikeFeed(ctx context.Context, feedID, xsecToken string) (*ActionResult, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	action := xiaohongshu.NewLikeAction(page)
-	if err := action.Unlike(ctx, feedID, xsecToken); err != nil {
+	err := withPage(ctx, opLikeFeed, func(page *rod.Page) error {
+		return xiaohongshu.NewLikeAction(page).Unlike(ctx, feedID, xsecToken)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &ActionResult{FeedID: feedID, Success: true, Message: "取消点赞成功或未点赞"}, nil
@@ -391,11 +436,10 @@ func (s *XiaohongshuService) UnlikeFeed(ctx context.Context, feedID, xsecToken s
 
 // FavoriteFeed 收藏笔记
 func (s *XiaohongshuService) FavoriteFeed(ctx context.Context, feedID, xsecToken string) (*ActionResult, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	action := xiaohongshu.NewFavoriteAction(page)
-	if err := action.Favorite(ctx, feedID, xsecToken); err != nil {
+	err := withPage(ctx, opFavoriteFeed, func(page *rod.Page) error {
+		return xiaohongshu.NewFavoriteAction(page).Favorite(ctx, feedID, xsecToken)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &ActionResult{FeedID: feedID, Success: true, Message: "收藏成功或已收藏"}, nil
@@ -403,11 +447,10 @@ func (s *XiaohongshuService) FavoriteFeed(ctx context.Context, feedID, xsecToken
 
 // UnfavoriteFeed 取消收藏笔记
 func (s *XiaohongshuService) UnfavoriteFeed(ctx context.Context, feedID, xsecToken string) (*ActionResult, error) {
-	page, release := getPageWithRelease()
-	defer release()
-
-	action := xiaohongshu.NewFavoriteAction(page)
-	if err := action.Unfavorite(ctx, feedID, xsecToken); err != nil {
+	err := withPage(ctx, opFavoriteFeed, func(page *rod.Page) error {
+		return xiaohongshu.NewFavoriteAction(page).Unfavorite(ctx, feedID, xsecToken)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &ActionResult{FeedID: feedID, Success: true, Message: "取消收藏成功或未收藏"}, nil
@@ -431,19 +474,21 @@ func (s *XiaohongshuService) browseRecommendationsInternal(ctx context.Context,
 		config.EnableComment = &enable
 	}
 
-	page, release := getPageWithRelease()
-	defer release()
-
-	action := xiaohongshu.NewBrowseAction(page, config)
-	stats, err := action.StartBrowse(ctx)
+	var stats *xiaohongshu.BrowseStats
+	err := withPage(ctx, opBrowse, func(page *rod.Page) error {
+		action := xiaohongshu.NewBrowseAction(page, config)
+		var err error
+		stats, err = action.StartBrowse(ctx)
+		return err
+	})
 
-	// 浏览完成后，关闭所有浏览器实例以实现真正的任务退出
+	// 浏览完成后，如果没有其他操作在使用浏览器，则关闭浏览器实例以实现真正的任务退出
 	if withoutComment {
-		logrus.Info("推荐页浏览任务完成（无评论模式），关闭所有浏览器实例")
+		logging.From(ctx).Info("推荐页浏览任务完成（无评论模式），尝试关闭空闲浏览器实例")
 	} else {
-		logrus.Info("推荐页浏览任务完成，关闭所有浏览器实例")
+		logging.From(ctx).Info("推荐页浏览任务完成，尝试关闭空闲浏览器实例")
 	}
-	browser.GetGlobalManager().CloseBrowser()
+	browser.GetGlobalManager().CloseBrowserIfIdle()
 
 	return stats, err
 }
@@ -464,10 +509,81 @@ func newBrowser() *headless_browser.Browser {
 	return browser.NewBrowser(configs.IsHeadless(), browser.WithBinPath(configs.GetBinPath()))
 }
 
-// getPageWithRelease 获取浏览器页面（使用全局浏览器管理器，确保同一时间只有一个浏览器实例）
-// 返回页面和释放函数，使用完毕后必须调用释放函数
-func getPageWithRelease() (*rod.Page, func()) {
-	return browser.GetGlobalManager().NewPageWithRelease()
+// getPageWithRelease 获取浏览器页面（使用全局浏览器管理器，多个操作共享同一个浏览器实例）
+// 返回页面和释放函数，使用完毕后必须调用释放函数；
+// 调用方取消 ctx 或等待超时（*browser.BusyError）时返回错误
+func getPageWithRelease(ctx context.Context, op browser.Op) (*rod.Page, func(), error) {
+	xiaohongshu.ReportProgress(ctx, xiaohongshu.Progress{Stage: xiaohongshu.StageAcquirePage, Message: "等待浏览器页面"})
+	page, release, err := browser.GetGlobalManager().NewPageWithRelease(ctx, op)
+	if err != nil {
+		return nil, nil, err
+	}
+	markPageAcquired(ctx)
+	return page, release, nil
+}
+
+// withPage 申请页面并执行 fn，执行完毕后释放页面。页面绑定 ctx，页面操作可以通过它取得进度回调与日志字段。
+// 如果 Chrome 崩溃或连接断开，浏览器管理器会丢弃失效实例并在下次申请时重新启动；
+// 只读操作会在新页面上自动重试一次，写操作不重试，避免重复发布、评论
+func withPage(ctx context.Context, op browser.Op, fn func(page *rod.Page) error) error {
+	manager := browser.GetGlobalManager()
+	for attempt := 0; ; attempt++ {
+		page, release, err := getPageWithRelease(ctx, op)
+		if err != nil {
+			return err
+		}
+
+		err = runRecorded(ctx, page.Context(ctx), op, fn)
+		release()
+
+		if err == nil || !manager.HandleOpError(ctx, err) {
+			return err
+		}
+		if op.Kind != browser.OpRead || attempt > 0 || ctx.Err() != nil {
+			return err
+		}
+		logging.From(ctx).Warnf("只读操作 %s 因浏览器异常中断，重试一次", op.Name)
+	}
+}
+
+// runRecorded 执行 fn，失败时保存调试包（截图、HTML、__INITIAL_STATE__、控制台输出与步骤记录），
+// 返回的错误附带调试包 ID
+func runRecorded(ctx context.Context, page *rod.Page, op browser.Op, fn func(page *rod.Page) error) error {
+	recorder := xiaohongshu.StartRecording(page)
+	defer recorder.Stop()
+
+	return captureFailure(ctx, recorder, op, runPageFunc(page, fn))
+}
+
+// captureFailure 为失败的操作保存调试包。浏览器已断开或调用方取消时无法（也无需）采集，用户未确认发布时无需采集
+func captureFailure(ctx context.Context, recorder *xiaohongshu.PageRecorder, op browser.Op, err error) error {
+	if err == nil || browser.IsBrowserGoneErr(err) || errors.Is(err, context.Canceled) || errors.Is(err, xiaohongshu.ErrNotConfirmed) {
+		return err
+	}
+
+	id, captureErr := recorder.Capture(op.Name, err)
+	if captureErr != nil {
+		logging.From(ctx).Warnf("保存调试包失败: %v", captureErr)
+		return err
+	}
+	if id == "" {
+		return err
+	}
+	return &xiaohongshu.BundleError{BundleID: id, Err: err}
+}
+
+// runPageFunc 执行 fn，并将 rod Must* 调用在浏览器断开时产生的 panic 转换为错误
+func runPageFunc(page *rod.Page, fn func(page *rod.Page) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok && browser.IsBrowserGoneErr(e) {
+				err = e
+				return
+			}
+			panic(r)
+		}
+	}()
+	return fn(page)
 }
 
 func saveCookies(page *rod.Page) error {
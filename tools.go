@@ -0,0 +1,774 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/idempotency"
+	"github.com/xpzouying/xiaohongshu-mcp/jobs"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+	"github.com/xpzouying/xiaohongshu-mcp/metrics"
+	"github.com/xpzouying/xiaohongshu-mcp/ratelimit"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// toolSpec 描述一个对外提供的操作。每个操作只在 newToolRegistry 中声明一次，
+// 据此同时生成 MCP 工具与 /api/v1 下的 REST 路由
+type toolSpec struct {
+	Name           string // MCP 工具名
+	Description    string // MCP 工具描述
+	Method         string // REST 方法，GET 从 query 读取参数，其余从 JSON body 读取
+	Path           string // REST 路径（相对 /api/v1）
+	Scope          string // 调用所需的 API Key 权限，见 configs.Scope*
+	Action         string // 受频率限制的写操作类型，见 configs.Action*，为空表示不限制
+	ErrorCode      string // REST 下无法识别的错误使用的错误码
+	FailMessage    string // 失败提示，同时作为 MCP 错误文本的前缀
+	SuccessMessage string // REST 成功提示
+	Destructive    bool   // 写操作的结果公开且无法通过其他工具撤销（发布、评论）
+	Idempotent     bool   // 写操作以相同参数重复调用没有额外影响（如已点赞时跳过点赞）
+	Confirm        bool   // 开启 confirm.publish 时，MCP 调用在点击发布前请求用户确认，REST 与后台任务直接拒绝
+}
+
+// 操作的调用方式，作为监控指标的 transport 标签
+const (
+	transportMCP      = "mcp"
+	transportREST     = "rest"
+	transportJob      = "job"
+	transportResource = "resource" // MCP 资源读取
+)
+
+// errorCode 返回操作错误对应的错误码，无法识别的错误使用操作自身的错误码
+func (s toolSpec) errorCode(err error) string {
+	if code := errorCode(err); code != errCodeInternalError {
+		return code
+	}
+	return s.ErrorCode
+}
+
+// requireMCP 开启 confirm.publish 时，需要确认的操作只能通过 MCP 调用，其他方式无法请求用户确认
+func (s toolSpec) requireMCP() error {
+	if s.Confirm && configs.Get().Confirm.Publish {
+		return fmt.Errorf("%w: %s", errConfirmUnavailable, s.Name)
+	}
+	return nil
+}
+
+// annotations 返回 MCP 工具的行为提示。read 权限的工具只读取内容，所有工具都访问小红书
+func (s toolSpec) annotations() *mcp.ToolAnnotations {
+	openWorld := true
+	if s.Scope == configs.ScopeRead {
+		return &mcp.ToolAnnotations{ReadOnlyHint: true, OpenWorldHint: &openWorld}
+	}
+	destructive := s.Destructive
+	return &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: s.Idempotent, OpenWorldHint: &openWorld}
+}
+
+// observe 记录操作的耗时与结果，发布与互动操作另外按 API Key 计数
+func (s toolSpec) observe(ctx context.Context, transport string, elapsed time.Duration, err error) {
+	code := metrics.CodeOK
+	if err != nil {
+		code = s.errorCode(err)
+	}
+	metrics.ObserveOperation(s.Name, transport, code, elapsed)
+
+	if s.Scope == configs.ScopePublish || s.Scope == configs.ScopeInteract {
+		metrics.CountAccountAction(accountFrom(ctx), s.Name, err == nil)
+	}
+}
+
+// tool 可以注册为 MCP 工具与 REST 路由的操作
+type tool interface {
+	spec() toolSpec
+	registerMCP(server *mcp.Server, authz *apiKeyAuth)
+	handleREST(c *gin.Context)
+	// prepareJob 解析并校验后台任务的参数，返回任务的执行函数
+	prepareJob(args json.RawMessage) (jobs.Func, error)
+	// invokeJSON 解析并校验 JSON 参数后执行操作，返回操作的输出
+	invokeJSON(ctx context.Context, transport string, args json.RawMessage) (any, error)
+	// docTypes 返回 REST 请求参数与响应 data 的类型，用于生成 OpenAPI 文档
+	docTypes() (in, out reflect.Type)
+	// useLimiter 设置写操作的限流器
+	useLimiter(limits *ratelimit.Limiter)
+	// useIdempotency 设置 idempotency_key 的记录
+	useIdempotency(keys *idempotency.Store)
+}
+
+// typedTool 输入为 In、输出为 Out 的操作。
+// 参数通过 binding 标签校验，MCP 与 REST 使用同一套规则
+type typedTool[In, Out any] struct {
+	toolSpec
+
+	run func(ctx context.Context, in In) (Out, error)
+
+	// present 生成 MCP 返回的文本摘要，为 nil 时返回输出的 JSON
+	present func(in In, out Out) *MCPToolResult
+	// structured 生成 MCP 的结构化结果（structuredContent），为 nil 时直接使用输出
+	structured func(out Out) any
+	// restData 生成 REST 响应的 data 字段，为 nil 时直接返回输出
+	restData func(out Out) any
+
+	limits *ratelimit.Limiter
+	keys   *idempotency.Store
+}
+
+func (t *typedTool[In, Out]) spec() toolSpec {
+	return t.toolSpec
+}
+
+func (t *typedTool[In, Out]) registerMCP(server *mcp.Server, authz *apiKeyAuth) {
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:         t.Name,
+			Description:  t.Description,
+			OutputSchema: t.outputSchema(),
+			Annotations:  t.annotations(),
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+			ctx = logging.WithRequestID(ctx, mcpRequestID(req.Extra))
+			logging.From(ctx).Infof("MCP: %s", t.Name)
+			if err := authz.authorizeMCP(req.Extra, "工具 "+t.Name, t.Scope); err != nil {
+				return convertToMCPResult(newMCPErrorResult(t.FailMessage, err)), nil, nil
+			}
+			ctx = withAccount(ctx, mcpAccount(req.Extra))
+			// 客户端取消调用（notifications/cancelled）时 ctx 被取消，页面操作随之中止
+			ctx = withMCPProgress(ctx, req)
+			if t.Confirm && configs.Get().Confirm.Publish {
+				var err error
+				if ctx, err = withMCPConfirm(ctx, req, configs.Get().Confirm.Timeout); err != nil {
+					return convertToMCPResult(newMCPErrorResult(t.FailMessage, err)), nil, nil
+				}
+			}
+			return convertToMCPResult(t.callMCP(ctx, in)), nil, nil
+		},
+	)
+}
+
+func (t *typedTool[In, Out]) callMCP(ctx context.Context, in In) *MCPToolResult {
+	if err := binding.Validator.ValidateStruct(in); err != nil {
+		return newMCPErrorResult(t.FailMessage, describeArgsError(in, err))
+	}
+
+	out, err := t.invoke(ctx, transportMCP, in)
+	if err != nil {
+		return newMCPErrorResult(t.FailMessage, err)
+	}
+
+	var result *MCPToolResult
+	if t.present != nil {
+		result = t.present(in, out)
+	} else {
+		result = jsonResult(t.FailMessage, out)
+	}
+	if !result.IsError {
+		result.StructuredContent = t.structuredContent(out)
+	}
+	return result
+}
+
+// structuredContent 返回输出对应的结构化结果，输出为 nil 指针时返回 nil
+func (t *typedTool[In, Out]) structuredContent(out Out) any {
+	var v any = out
+	if t.structured != nil {
+		v = t.structured(out)
+	}
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return nil
+	}
+	return v
+}
+
+// outputSchema 生成 MCP 工具结构化结果的 schema，无法生成时不声明
+func (t *typedTool[In, Out]) outputSchema() *jsonschema.Schema {
+	typ := reflect.TypeFor[Out]()
+	if t.structured != nil {
+		var zero Out
+		typ = reflect.TypeOf(t.structured(zero))
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	schema, err := jsonschema.ForType(typ, schemaOptions)
+	if err != nil {
+		logrus.Warnf("生成工具 %s 的输出 schema 失败: %v", t.Name, err)
+		return nil
+	}
+	if schema.Type != "object" {
+		logrus.Warnf("工具 %s 的输出不是对象，不声明输出 schema", t.Name)
+		return nil
+	}
+	allowNullArrays(schema)
+	return schema
+}
+
+// allowNullArrays 数组字段同时允许 null：Go 的 nil 切片序列化为 null
+func allowNullArrays(schema *jsonschema.Schema) {
+	if schema == nil {
+		return
+	}
+	if schema.Type == "array" {
+		schema.Type = ""
+		schema.Types = []string{"null", "array"}
+	}
+	allowNullArrays(schema.Items)
+	allowNullArrays(schema.AdditionalProperties)
+	for _, s := range schema.Properties {
+		allowNullArrays(s)
+	}
+	for _, s := range schema.Defs {
+		allowNullArrays(s)
+	}
+}
+
+func (t *typedTool[In, Out]) handleREST(c *gin.Context) {
+	var in In
+	var err error
+	if t.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&in)
+	} else {
+		err = c.ShouldBindJSON(&in)
+	}
+	if err != nil {
+		respondServiceError(c, t.ErrorCode, t.FailMessage, describeArgsError(in, err))
+		return
+	}
+	if err := t.requireMCP(); err != nil {
+		respondServiceError(c, t.ErrorCode, t.FailMessage, err)
+		return
+	}
+
+	ctx := withAccount(c.Request.Context(), c.GetString(apiKeyContextKey))
+	out, err := t.invoke(ctx, transportREST, in)
+	if err != nil {
+		respondServiceError(c, t.ErrorCode, t.FailMessage, err)
+		return
+	}
+
+	if t.restData != nil {
+		respondSuccess(c, t.restData(out), t.SuccessMessage)
+		return
+	}
+	respondSuccess(c, out, t.SuccessMessage)
+}
+
+// parseArgs 解析并校验 JSON 参数，args 为空时使用零值
+func (t *typedTool[In, Out]) parseArgs(args json.RawMessage) (In, error) {
+	var in In
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &in); err != nil {
+			return in, describeArgsError(in, err)
+		}
+	}
+	if err := binding.Validator.ValidateStruct(in); err != nil {
+		return in, describeArgsError(in, err)
+	}
+	return in, nil
+}
+
+func (t *typedTool[In, Out]) invokeJSON(ctx context.Context, transport string, args json.RawMessage) (any, error) {
+	in, err := t.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	out, err := t.invoke(ctx, transport, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (t *typedTool[In, Out]) prepareJob(args json.RawMessage) (jobs.Func, error) {
+	if err := t.requireMCP(); err != nil {
+		return nil, err
+	}
+	in, err := t.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, report func(any)) (any, error) {
+		ctx = xiaohongshu.WithProgress(ctx, func(p xiaohongshu.Progress) {
+			report(p)
+		})
+
+		out, err := t.invoke(ctx, transportJob, in)
+		if err != nil {
+			return nil, err
+		}
+		if t.restData != nil {
+			return t.restData(out), nil
+		}
+		return out, nil
+	}, nil
+}
+
+func (t *typedTool[In, Out]) useLimiter(limits *ratelimit.Limiter) {
+	t.limits = limits
+}
+
+func (t *typedTool[In, Out]) useIdempotency(keys *idempotency.Store) {
+	t.keys = keys
+}
+
+// invoke 检查 idempotency_key 与写操作的额度后执行操作，并记录监控指标。
+// 之后的日志都带有工具名与 API Key 名称
+func (t *typedTool[In, Out]) invoke(ctx context.Context, transport string, in In) (Out, error) {
+	ctx = logging.With(ctx, logrus.Fields{
+		logging.FieldTool:    t.Name,
+		logging.FieldAccount: accountFrom(ctx),
+	})
+	start := time.Now()
+	out, err := t.idempotentRun(ctx, in)
+	t.observe(ctx, transport, time.Since(start), err)
+	return out, err
+}
+
+// idempotentRun 带 idempotency_key 的请求在有效期内只执行一次，重复的请求返回首次执行的结果，
+// 不再占用额度。key 按 API Key 与工具分别记录
+func (t *typedTool[In, Out]) idempotentRun(ctx context.Context, in In) (Out, error) {
+	req, ok := any(in).(idempotentRequest)
+	if !ok || req.idempotencyKey() == "" || t.keys == nil {
+		return t.limitedRun(ctx, in)
+	}
+
+	var out Out
+	scope := accountFrom(ctx) + "/" + t.Name
+	replay, finish, err := t.keys.Begin(scope, req.idempotencyKey(), idempotency.Fingerprint(in))
+	if err != nil {
+		return out, err
+	}
+	if replay != nil {
+		if err := json.Unmarshal(replay, &out); err != nil {
+			return out, fmt.Errorf("解析首次执行的结果失败: %w", err)
+		}
+		return out, nil
+	}
+
+	// 拿到浏览器页面之后失败（发布后的检查失败、客户端断开导致取消等）时操作可能已经生效，
+	// 保留记录以免重试时重复发布；panic 时同样按是否拿到页面处理，避免记录一直处于执行中
+	ctx, use := withPageUse(ctx)
+	retryable := func(err error) bool {
+		return !use.acquired.Load() || errors.Is(err, xiaohongshu.ErrNotConfirmed)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err := fmt.Errorf("执行 %s 时 panic: %v", t.Name, p)
+			finish(nil, err, retryable(err))
+			panic(p)
+		}
+		finish(out, err, retryable(err))
+	}()
+
+	out, err = t.limitedRun(ctx, in)
+	return out, err
+}
+
+// limitedRun 写操作先占用一次额度，操作没有作用到小红书时归还：拿到浏览器页面之前失败
+// （参数校验、下载图片、等待页面超时或被取消）或用户未确认发布
+func (t *typedTool[In, Out]) limitedRun(ctx context.Context, in In) (Out, error) {
+	if t.Action == "" || t.limits == nil {
+		return t.run(ctx, in)
+	}
+
+	release, err := t.limits.Reserve(accountFrom(ctx), t.Action)
+	if err != nil {
+		var zero Out
+		return zero, err
+	}
+	ctx, use := withPageUse(ctx)
+	out, err := t.run(ctx, in)
+	if err != nil && (!use.acquired.Load() || errors.Is(err, xiaohongshu.ErrNotConfirmed)) {
+		release()
+	}
+	return out, err
+}
+
+// pageUse 记录操作是否已经拿到浏览器页面
+type pageUse struct {
+	acquired atomic.Bool
+}
+
+type pageUseKey struct{}
+
+// withPageUse 返回记录页面使用情况的 ctx，ctx 中已有记录时沿用
+func withPageUse(ctx context.Context) (context.Context, *pageUse) {
+	if use, ok := ctx.Value(pageUseKey{}).(*pageUse); ok {
+		return ctx, use
+	}
+	use := &pageUse{}
+	return context.WithValue(ctx, pageUseKey{}, use), use
+}
+
+// markPageAcquired 标记操作已经拿到浏览器页面，由 getPageWithRelease 调用
+func markPageAcquired(ctx context.Context) {
+	if use, ok := ctx.Value(pageUseKey{}).(*pageUse); ok {
+		use.acquired.Store(true)
+	}
+}
+
+func (t *typedTool[In, Out]) docTypes() (in, out reflect.Type) {
+	out = reflect.TypeFor[Out]()
+	if t.restData != nil {
+		var zero Out
+		out = reflect.TypeOf(t.restData(zero))
+	}
+	return reflect.TypeFor[In](), out
+}
+
+// describeArgsError 将参数错误包装为 errInvalidArgs，校验失败时转换为“缺少feed_id参数”形式的提示
+func describeArgsError(args any, err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return fmt.Errorf("%w: %v", errInvalidArgs, err)
+	}
+
+	typ := reflect.Indirect(reflect.ValueOf(args)).Type()
+	msgs := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		name := fe.Field()
+		if f, ok := typ.FieldByName(fe.StructField()); ok {
+			if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" {
+				name = tag
+			}
+		}
+
+		if fe.Tag() == "required" {
+			msgs = append(msgs, fmt.Sprintf("缺少%s参数", name))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("参数%s不满足 %s=%s", name, fe.Tag(), fe.Param()))
+		}
+	}
+	return fmt.Errorf("%w: %s", errInvalidArgs, strings.Join(msgs, "; "))
+}
+
+// textResult 返回纯文本的 MCP 结果
+func textResult(text string) *MCPToolResult {
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: text,
+		}},
+	}
+}
+
+// jsonResult 将输出格式化为 JSON 文本
+func jsonResult(prefix string, out any) *MCPToolResult {
+	jsonData, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return newMCPErrorResult(prefix, fmt.Errorf("序列化结果失败: %w", err))
+	}
+	return textResult(string(jsonData))
+}
+
+// noArgs 无参数的操作
+type noArgs struct{}
+
+// newToolRegistry 声明所有对外提供的操作
+func newToolRegistry(s *AppServer) []tool {
+	svc := s.xiaohongshuService
+
+	tools := []tool{
+		&typedTool[noArgs, *LoginStatusResponse]{
+			toolSpec: toolSpec{
+				Name:           "check_login_status",
+				Description:    "检查小红书登录状态",
+				Method:         http.MethodGet,
+				Path:           "/login/status",
+				Scope:          configs.ScopeRead,
+				ErrorCode:      "STATUS_CHECK_FAILED",
+				FailMessage:    "检查登录状态失败",
+				SuccessMessage: "检查登录状态成功",
+			},
+			run: func(ctx context.Context, _ noArgs) (*LoginStatusResponse, error) {
+				return svc.CheckLoginStatus(ctx)
+			},
+			present: func(_ noArgs, status *LoginStatusResponse) *MCPToolResult {
+				if !status.IsLoggedIn {
+					return textResult("当前未登录，请先使用 get_login_qrcode 扫码登录")
+				}
+				return textResult(fmt.Sprintf("已登录，用户名: %s", status.Username))
+			},
+		},
+		&typedTool[noArgs, *LoginQrcodeResponse]{
+			toolSpec: toolSpec{
+				Name:           "get_login_qrcode",
+				Description:    "获取登录二维码（返回 Base64 图片和超时时间）",
+				Method:         http.MethodGet,
+				Path:           "/login/qrcode",
+				Scope:          configs.ScopeAdmin,
+				ErrorCode:      "STATUS_CHECK_FAILED",
+				FailMessage:    "获取登录扫码图片失败",
+				SuccessMessage: "获取登录二维码成功",
+			},
+			run: func(ctx context.Context, _ noArgs) (*LoginQrcodeResponse, error) {
+				return svc.GetLoginQrcode(ctx)
+			},
+			present: presentLoginQrcode,
+		},
+		&typedTool[PublishRequest, *PublishResponse]{
+			toolSpec: toolSpec{
+				Name:           "publish_content",
+				Description:    "发布小红书图文内容",
+				Method:         http.MethodPost,
+				Path:           "/publish",
+				Scope:          configs.ScopePublish,
+				Action:         configs.ActionPublish,
+				ErrorCode:      "PUBLISH_FAILED",
+				FailMessage:    "发布失败",
+				SuccessMessage: "发布成功",
+				Destructive:    true,
+				Confirm:        true,
+			},
+			run: func(ctx context.Context, in PublishRequest) (*PublishResponse, error) {
+				logging.From(ctx).Infof("发布内容 - 标题: %s, 图片数量: %d, 标签数量: %d", in.Title, len(in.Images), len(in.Tags))
+				return svc.PublishContent(ctx, &in)
+			},
+			present: func(_ PublishRequest, out *PublishResponse) *MCPToolResult {
+				return textResult(fmt.Sprintf("内容发布成功 - 标题: %s, 图片数量: %d%s", out.Title, out.Images, formatPostID(out.PostID)))
+			},
+		},
+		&typedTool[noArgs, *FeedsListResponse]{
+			toolSpec: toolSpec{
+				Name:           "list_feeds",
+				Description:    "获取用户发布的内容列表",
+				Method:         http.MethodGet,
+				Path:           "/feeds/list",
+				Scope:          configs.ScopeRead,
+				ErrorCode:      "LIST_FEEDS_FAILED",
+				FailMessage:    "获取Feeds列表失败",
+				SuccessMessage: "获取Feeds列表成功",
+			},
+			run: func(ctx context.Context, _ noArgs) (*FeedsListResponse, error) {
+				return svc.ListFeeds(ctx)
+			},
+			present: presentFeeds,
+		},
+		&typedTool[SearchFeedsArgs, *FeedsListResponse]{
+			toolSpec: toolSpec{
+				Name:           "search_feeds",
+				Description:    "搜索小红书内容（需要已登录）",
+				Method:         http.MethodGet,
+				Path:           "/feeds/search",
+				Scope:          configs.ScopeRead,
+				ErrorCode:      "SEARCH_FEEDS_FAILED",
+				FailMessage:    "搜索Feeds失败",
+				SuccessMessage: "搜索Feeds成功",
+			},
+			run: func(ctx context.Context, in SearchFeedsArgs) (*FeedsListResponse, error) {
+				return svc.SearchFeeds(ctx, in.Keyword)
+			},
+			present: func(_ SearchFeedsArgs, out *FeedsListResponse) *MCPToolResult {
+				return presentFeeds(noArgs{}, out)
+			},
+		},
+		&typedTool[FeedDetailArgs, *FeedDetailResponse]{
+			toolSpec: toolSpec{
+				Name:           "get_feed_detail",
+				Description:    "获取小红书笔记详情，返回笔记内容、图片、作者信息、互动数据（点赞/收藏/分享数）及评论列表",
+				Method:         http.MethodPost,
+				Path:           "/feeds/detail",
+				Scope:          configs.ScopeRead,
+				ErrorCode:      "GET_FEED_DETAIL_FAILED",
+				FailMessage:    "获取Feed详情失败",
+				SuccessMessage: "获取Feed详情成功",
+			},
+			run: func(ctx context.Context, in FeedDetailArgs) (*FeedDetailResponse, error) {
+				return svc.GetFeedDetail(ctx, in.FeedID, in.XsecToken)
+			},
+		},
+		&typedTool[UserProfileArgs, *UserProfileResponse]{
+			toolSpec: toolSpec{
+				Name:           "user_profile",
+				Description:    "获取小红书用户主页，返回用户基本信息，关注、粉丝、获赞量及其笔记内容",
+				Method:         http.MethodPost,
+				Path:           "/user/profile",
+				Scope:          configs.ScopeRead,
+				ErrorCode:      "GET_USER_PROFILE_FAILED",
+				FailMessage:    "获取用户主页失败",
+				SuccessMessage: "获取用户主页成功",
+			},
+			run: func(ctx context.Context, in UserProfileArgs) (*UserProfileResponse, error) {
+				return svc.UserProfile(ctx, in.UserID, in.XsecToken)
+			},
+			present: presentUserProfile,
+			// 兼容旧版 REST 响应：用户主页位于 data.data
+			restData: func(out *UserProfileResponse) any {
+				return UserProfileData{Data: out}
+			},
+		},
+		&typedTool[PostCommentArgs, *PostCommentResponse]{
+			toolSpec: toolSpec{
+				Name:           "post_comment_to_feed",
+				Description:    "发表评论到小红书笔记",
+				Method:         http.MethodPost,
+				Path:           "/feeds/comment",
+				Scope:          configs.ScopeInteract,
+				Action:         configs.ActionComment,
+				ErrorCode:      "POST_COMMENT_FAILED",
+				FailMessage:    "发表评论失败",
+				SuccessMessage: "评论发表成功",
+				Destructive:    true,
+			},
+			run: func(ctx context.Context, in PostCommentArgs) (*PostCommentResponse, error) {
+				logging.From(ctx).Infof("发表评论 - Feed ID: %s, 内容长度: %d", in.FeedID, len(in.Content))
+				return svc.PostCommentToFeed(ctx, in.FeedID, in.XsecToken, in.Content)
+			},
+			present: func(_ PostCommentArgs, out *PostCommentResponse) *MCPToolResult {
+				return textResult(fmt.Sprintf("评论发表成功 - Feed ID: %s", out.FeedID))
+			},
+		},
+		&typedTool[PublishVideoRequest, *PublishVideoResponse]{
+			toolSpec: toolSpec{
+				Name:           "publish_with_video",
+				Description:    "发布小红书视频内容（仅支持本地单个视频文件）",
+				Method:         http.MethodPost,
+				Path:           "/publish_video",
+				Scope:          configs.ScopePublish,
+				Action:         configs.ActionPublish,
+				ErrorCode:      "PUBLISH_VIDEO_FAILED",
+				FailMessage:    "视频发布失败",
+				SuccessMessage: "视频发布成功",
+				Destructive:    true,
+				Confirm:        true,
+			},
+			run: func(ctx context.Context, in PublishVideoRequest) (*PublishVideoResponse, error) {
+				logging.From(ctx).Infof("发布视频 - 标题: %s, 标签数量: %d", in.Title, len(in.Tags))
+				return svc.PublishVideo(ctx, &in)
+			},
+			present: func(_ PublishVideoRequest, out *PublishVideoResponse) *MCPToolResult {
+				return textResult(fmt.Sprintf("视频发布成功 - 标题: %s%s", out.Title, formatPostID(out.PostID)))
+			},
+		},
+		&typedTool[LikeFeedArgs, *ActionResult]{
+			toolSpec: toolSpec{
+				Name:           "like_feed",
+				Description:    "为指定笔记点赞或取消点赞（如已点赞将跳过点赞，如未点赞将跳过取消点赞）",
+				Method:         http.MethodPost,
+				Path:           "/feeds/like",
+				Scope:          configs.ScopeInteract,
+				Action:         configs.ActionLike,
+				ErrorCode:      "LIKE_FAILED",
+				FailMessage:    "点赞操作失败",
+				SuccessMessage: "点赞操作成功",
+				Idempotent:     true,
+			},
+			run: func(ctx context.Context, in LikeFeedArgs) (*ActionResult, error) {
+				if in.Unlike {
+					return svc.UnlikeFeed(ctx, in.FeedID, in.XsecToken)
+				}
+				return svc.LikeFeed(ctx, in.FeedID, in.XsecToken)
+			},
+			present: func(in LikeFeedArgs, out *ActionResult) *MCPToolResult {
+				action := "点赞"
+				if in.Unlike {
+					action = "取消点赞"
+				}
+				return textResult(fmt.Sprintf("%s成功 - Feed ID: %s", action, out.FeedID))
+			},
+		},
+		&typedTool[FavoriteFeedArgs, *ActionResult]{
+			toolSpec: toolSpec{
+				Name:           "favorite_feed",
+				Description:    "收藏指定笔记或取消收藏（如已收藏将跳过收藏，如未收藏将跳过取消收藏）",
+				Method:         http.MethodPost,
+				Path:           "/feeds/favorite",
+				Scope:          configs.ScopeInteract,
+				Action:         configs.ActionFavorite,
+				ErrorCode:      "FAVORITE_FAILED",
+				FailMessage:    "收藏操作失败",
+				SuccessMessage: "收藏操作成功",
+				Idempotent:     true,
+			},
+			run: func(ctx context.Context, in FavoriteFeedArgs) (*ActionResult, error) {
+				if in.Unfavorite {
+					return svc.UnfavoriteFeed(ctx, in.FeedID, in.XsecToken)
+				}
+				return svc.FavoriteFeed(ctx, in.FeedID, in.XsecToken)
+			},
+			present: func(in FavoriteFeedArgs, out *ActionResult) *MCPToolResult {
+				action := "收藏"
+				if in.Unfavorite {
+					action = "取消收藏"
+				}
+				return textResult(fmt.Sprintf("%s成功 - Feed ID: %s", action, out.FeedID))
+			},
+		},
+		&typedTool[BrowseRecommendationsArgs, *xiaohongshu.BrowseStats]{
+			toolSpec: toolSpec{
+				Name:           "browse_recommendations",
+				Description:    "模拟人类浏览小红书推荐页，包括滚动、随机点击笔记、浏览评论区，并有概率进行点赞、收藏、评论等互动操作",
+				Method:         http.MethodPost,
+				Path:           "/browse/recommendations",
+				Scope:          configs.ScopeInteract,
+				ErrorCode:      "BROWSE_FAILED",
+				FailMessage:    "浏览推荐页失败",
+				SuccessMessage: "浏览推荐页完成",
+				Destructive:    true, // 可能发表评论
+			},
+			run: func(ctx context.Context, in BrowseRecommendationsArgs) (*xiaohongshu.BrowseStats, error) {
+				return svc.BrowseRecommendations(s.reserveInteractions(ctx), in.browseConfig())
+			},
+			present: func(_ BrowseRecommendationsArgs, stats *xiaohongshu.BrowseStats) *MCPToolResult {
+				return textResult("浏览推荐页完成！\n\n" + formatBrowseStats(stats))
+			},
+		},
+		&typedTool[BrowseRecommendationsArgs, *xiaohongshu.BrowseStats]{
+			toolSpec: toolSpec{
+				Name:           "browse_recommendations_without_comment",
+				Description:    "模拟人类浏览小红书推荐页，包括滚动、随机点击笔记、浏览评论区，并有概率进行点赞、收藏等互动操作，但不会进行评论",
+				Method:         http.MethodPost,
+				Path:           "/browse/recommendations/without_comment",
+				Scope:          configs.ScopeInteract,
+				ErrorCode:      "BROWSE_FAILED",
+				FailMessage:    "浏览推荐页失败",
+				SuccessMessage: "浏览推荐页完成（无评论模式）",
+			},
+			run: func(ctx context.Context, in BrowseRecommendationsArgs) (*xiaohongshu.BrowseStats, error) {
+				return svc.BrowseRecommendationsWithoutComment(s.reserveInteractions(ctx), in.browseConfig())
+			},
+			present: func(_ BrowseRecommendationsArgs, stats *xiaohongshu.BrowseStats) *MCPToolResult {
+				return textResult("浏览推荐页完成（无评论模式）！\n\n" + formatBrowseStats(stats))
+			},
+		},
+		&typedTool[BrowseRecommendationsArgs, []*ParallelInstanceResult]{
+			toolSpec: toolSpec{
+				Name:           "parallel_browse_recommendations",
+				Description:    "使用多个独立浏览器实例并行模拟人类浏览小红书推荐页，每个实例拥有独立的登录状态和 cookies",
+				Method:         http.MethodPost,
+				Path:           "/browse/recommendations/parallel",
+				Scope:          configs.ScopeInteract,
+				ErrorCode:      "PARALLEL_BROWSE_FAILED",
+				FailMessage:    "并行浏览推荐页失败",
+				SuccessMessage: "并行浏览推荐页完成",
+				Destructive:    true, // 可能发表评论
+			},
+			run: func(ctx context.Context, in BrowseRecommendationsArgs) ([]*ParallelInstanceResult, error) {
+				return s.parallelBrowse(s.reserveInteractions(ctx), in)
+			},
+			present: presentParallelBrowse,
+			structured: func(results []*ParallelInstanceResult) any {
+				return ParallelBrowseResult{Results: results}
+			},
+		},
+	}
+
+	for _, t := range tools {
+		t.useLimiter(s.limits)
+		t.useIdempotency(s.idempotency)
+	}
+	return tools
+}
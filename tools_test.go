@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/metrics"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+type echoArgs struct {
+	FeedID string `json:"feed_id" form:"feed_id" binding:"required"`
+	Limit  int    `json:"limit,omitempty" form:"limit" binding:"omitempty,max=50"`
+}
+
+type echoOut struct {
+	FeedID string   `json:"feed_id"`
+	Tags   []string `json:"tags"`
+}
+
+// newEchoTool 返回原样输出 feed_id 的操作，收到的参数记录到 got
+func newEchoTool(method string, got *echoArgs) *typedTool[echoArgs, *echoOut] {
+	return &typedTool[echoArgs, *echoOut]{
+		toolSpec: toolSpec{
+			Name:           "echo",
+			Description:    "echo",
+			Method:         method,
+			Path:           "/echo",
+			ErrorCode:      "ECHO_FAILED",
+			FailMessage:    "echo 失败",
+			SuccessMessage: "echo 成功",
+		},
+		run: func(_ context.Context, in echoArgs) (*echoOut, error) {
+			*got = in
+			if in.FeedID == "none" {
+				return nil, nil
+			}
+			return &echoOut{FeedID: in.FeedID}, nil
+		},
+	}
+}
+
+// serveTool 将操作注册为 REST 路由并发送请求
+func serveTool(t tool, req *http.Request) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(t.spec().Method, t.spec().Path, t.handleREST)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestToolRESTBinding(t *testing.T) {
+	newTestAppServer(t, nil)
+
+	var got echoArgs
+	w := serveTool(newEchoTool(http.MethodGet, &got), httptest.NewRequest(http.MethodGet, "/echo?feed_id=f1&limit=3", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, echoArgs{FeedID: "f1", Limit: 3}, got, "GET 从 query 读取参数")
+
+	got = echoArgs{}
+	w = serveTool(newEchoTool(http.MethodPost, &got),
+		httptest.NewRequest(http.MethodPost, "/echo?feed_id=ignored", strings.NewReader(`{"feed_id":"f2","limit":5}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, echoArgs{FeedID: "f2", Limit: 5}, got, "POST 从 JSON body 读取参数")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"缺少 query 参数", httptest.NewRequest(http.MethodGet, "/echo", nil), "缺少feed_id参数"},
+		{"query 参数超出范围", httptest.NewRequest(http.MethodGet, "/echo?feed_id=f&limit=99", nil), "参数limit不满足 max=50"},
+		{"body 缺少参数", httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{}`)), "缺少feed_id参数"},
+		{"body 不是 JSON", httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{`)), "请求参数错误"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveTool(newEchoTool(tt.req.Method, &got), tt.req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var resp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "INVALID_REQUEST", resp.Code)
+			assert.Contains(t, resp.Details, tt.want)
+		})
+	}
+}
+
+func TestDescribeArgsError(t *testing.T) {
+	validate := func(args any) error {
+		return describeArgsError(args, binding.Validator.ValidateStruct(args))
+	}
+
+	err := validate(echoArgs{Limit: 51})
+	assert.ErrorIs(t, err, errInvalidArgs)
+	assert.EqualError(t, err, "请求参数错误: 缺少feed_id参数; 参数limit不满足 max=50")
+
+	err = validate(&PublishRequest{Title: "标题"})
+	assert.ErrorIs(t, err, errInvalidArgs)
+	assert.Contains(t, err.Error(), "缺少content参数", "使用 json 标签中的参数名")
+
+	var syntaxErr *json.SyntaxError
+	err = describeArgsError(echoArgs{}, json.Unmarshal([]byte("{"), &struct{}{}))
+	assert.ErrorIs(t, err, errInvalidArgs)
+	assert.NotErrorAs(t, err, &syntaxErr, "其他错误只保留文本")
+	assert.Contains(t, err.Error(), "unexpected end of JSON input")
+}
+
+func TestToolsMCPAndRESTParity(t *testing.T) {
+	s := newTestAppServer(t, nil)
+
+	routes := map[string]bool{}
+	for _, r := range setupRoutes(s).Routes() {
+		routes[r.Method+" "+r.Path] = true
+	}
+	listed, err := connectMCP(t, s.mcpServer, nil).ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	mcpTools := map[string]*mcp.Tool{}
+	for _, tool := range listed.Tools {
+		mcpTools[tool.Name] = tool
+	}
+
+	require.NotEmpty(t, s.tools)
+	assert.Len(t, mcpTools, len(s.tools), "每个操作对应一个 MCP 工具")
+	for _, tool := range s.tools {
+		spec := tool.spec()
+		assert.Contains(t, mcpTools, spec.Name)
+		assert.True(t, routes[spec.Method+" /api/v1"+spec.Path], "%s 缺少 REST 路由 %s %s", spec.Name, spec.Method, spec.Path)
+	}
+}
+
+func TestToolResultShaping(t *testing.T) {
+	newTestAppServer(t, nil)
+
+	var got echoArgs
+	echo := newEchoTool(http.MethodPost, &got)
+	echo.restData = func(out *echoOut) any { return map[string]any{"id": out.FeedID} }
+	echo.structured = func(out *echoOut) any { return out }
+
+	w := serveTool(echo, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"feed_id":"f1"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"success":true,"data":{"id":"f1"},"message":"echo 成功"}`, w.Body.String())
+
+	run, err := echo.prepareJob(json.RawMessage(`{"feed_id":"f2"}`))
+	require.NoError(t, err)
+	result, err := run(context.Background(), func(any) {})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": "f2"}, result, "后台任务的结果与 REST 的 data 一致")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	echo.registerMCP(server, nil)
+	session := connectMCP(t, server, nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"feed_id": "f3"}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.Equal(t, map[string]any{"feed_id": "f3", "tags": nil}, res.StructuredContent)
+	require.Len(t, res.Content, 1)
+	assert.JSONEq(t, `{"feed_id":"f3","tags":null}`, res.Content[0].(*mcp.TextContent).Text, "未设置 present 时返回输出的 JSON")
+
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"feed_id": "none"}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.Nil(t, res.StructuredContent, "输出为 nil 指针时不返回结构化结果")
+
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"feed_id": ""}})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "缺少feed_id参数")
+}
+
+func TestConfirmRejectsRESTAndJobs(t *testing.T) {
+	router := setupRoutes(newTestAppServer(t, func(cfg *configs.Config) { cfg.Confirm.Publish = true }))
+	publish := `{"title":"标题","content":"正文","images":["/tmp/a.jpg"]}`
+
+	for path, body := range map[string]string{
+		"/api/v1/publish": publish,
+		"/api/v1/jobs":    `{"tool":"publish_content","arguments":` + publish + `}`,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "CONFIRMATION_REQUIRED", resp.Code, path)
+	}
+}
+
+func TestToolOutputSchema(t *testing.T) {
+	s := newTestAppServer(t, nil)
+	listed, err := connectMCP(t, s.mcpServer, nil).ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	declared := map[string]*jsonschema.Schema{}
+	for _, tool := range listed.Tools {
+		assert.NotNil(t, tool.OutputSchema, "%s 未声明输出 schema", tool.Name)
+		declared[tool.Name] = tool.OutputSchema
+	}
+
+	feeds := declared["list_feeds"]
+	require.NotNil(t, feeds)
+	assert.Equal(t, "object", feeds.Type)
+	assert.Equal(t, []string{"null", "array"}, feeds.Properties["feeds"].Types, "数组字段允许 null")
+	results := declared["parallel_browse_recommendations"]
+	require.NotNil(t, results)
+	assert.Contains(t, results.Properties, "results", "输出为切片时按 structured 包装为对象")
+
+	// Go 的 nil 切片序列化为 null，结构化结果需要通过客户端的 schema 校验
+	tests := []struct {
+		tool string
+		out  any
+	}{
+		{"list_feeds", &FeedsListResponse{}},
+		{"list_feeds", &FeedsListResponse{Feeds: []xiaohongshu.Feed{{}}, Count: 1}},
+		{"user_profile", &UserProfileResponse{}},
+		{"parallel_browse_recommendations", ParallelBrowseResult{}},
+		{"parallel_browse_recommendations", ParallelBrowseResult{Results: []*ParallelInstanceResult{{}}}},
+	}
+	for _, tt := range tests {
+		resolved, err := declared[tt.tool].Resolve(nil)
+		require.NoError(t, err, tt.tool)
+
+		data, err := json.Marshal(tt.out)
+		require.NoError(t, err)
+		var instance any
+		require.NoError(t, json.Unmarshal(data, &instance))
+		assert.NoError(t, resolved.Validate(instance), "%s: %s", tt.tool, data)
+	}
+}
+
+func TestToolObserveMetrics(t *testing.T) {
+	newTestAppServer(t, nil)
+
+	var got echoArgs
+	observed := newEchoTool(http.MethodPost, &got)
+	observed.Name = "observed_echo"
+	observed.Scope = configs.ScopeInteract
+	observed.run = func(_ context.Context, in echoArgs) (*echoOut, error) {
+		if in.FeedID == "fail" {
+			return nil, xiaohongshu.ErrNotLoggedIn
+		}
+		return &echoOut{}, nil
+	}
+
+	for _, body := range []string{`{"feed_id":"ok"}`, `{"feed_id":"fail"}`, `{"feed_id":"fail"}`} {
+		serveTool(observed, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body)))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `xhs_operations_total{code="OK",operation="observed_echo",transport="rest"} 1`)
+	assert.Contains(t, body, `xhs_operations_total{code="NOT_LOGGED_IN",operation="observed_echo",transport="rest"} 2`, "失败时记录错误码")
+	assert.Contains(t, body, `xhs_account_actions_total{account="anonymous",operation="observed_echo",result="success"} 1`)
+	assert.Contains(t, body, `xhs_account_actions_total{account="anonymous",operation="observed_echo",result="error"} 2`)
+}
@@ -0,0 +1,102 @@
+// Package ttlcache 短时间缓存读取结果。相同的内容在有效期内被重复读取时直接返回缓存，
+// 不再重新打开页面；同一个 key 同时只加载一次，其他调用方等待同一个结果
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// entry 一个 key 的缓存，done 关闭前正在加载
+type entry[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	expires time.Time
+}
+
+// Cache 按 key 缓存加载结果，可并发使用
+type Cache[V any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*entry[V]
+	now     func() time.Time
+}
+
+// New 创建缓存，加载成功的结果保留 ttl，ttl 为 0 时不缓存（仍合并同时发生的加载）
+func New[V any](ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		ttl:     ttl,
+		entries: map[string]*entry[V]{},
+		now:     time.Now,
+	}
+}
+
+// Get 返回 key 在有效期内的缓存，没有时调用 load 加载。加载失败的结果不缓存；
+// 等待中的调用方在加载被其发起方取消时自行重新加载
+func (c *Cache[V]) Get(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	for {
+		c.mu.Lock()
+		c.pruneLocked()
+		e, ok := c.entries[key]
+		if !ok {
+			e = &entry[V]{done: make(chan struct{})}
+			c.entries[key] = e
+			c.mu.Unlock()
+			return c.load(ctx, key, e, load)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+		if e.err != nil && isCanceled(e.err) && ctx.Err() == nil {
+			continue
+		}
+		return e.value, e.err
+	}
+}
+
+// load 执行加载并保存结果，失败时删除记录
+func (c *Cache[V]) load(ctx context.Context, key string, e *entry[V], load func(ctx context.Context) (V, error)) (V, error) {
+	e.value, e.err = load(ctx)
+
+	c.mu.Lock()
+	e.expires = c.now().Add(c.ttl)
+	if e.err != nil || c.ttl <= 0 {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	close(e.done)
+	return e.value, e.err
+}
+
+// pruneLocked 删除过期的缓存，调用方需持有 c.mu
+func (c *Cache[V]) pruneLocked() {
+	now := c.now()
+	for key, e := range c.entries {
+		if isDone(e) && !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func isDone[V any](e *entry[V]) bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,100 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCachesUntilExpiry(t *testing.T) {
+	c := New[string](time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	var loads int
+	load := func(context.Context) (string, error) {
+		loads++
+		return "feed", nil
+	}
+
+	for range 2 {
+		v, err := c.Get(context.Background(), "xhs://feed/home", load)
+		require.NoError(t, err)
+		assert.Equal(t, "feed", v)
+	}
+	assert.Equal(t, 1, loads)
+
+	now = now.Add(time.Minute)
+	_, err := c.Get(context.Background(), "xhs://feed/home", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads, "过期后重新加载")
+
+	_, err = c.Get(context.Background(), "failed", func(context.Context) (string, error) {
+		return "", errors.New("failed")
+	})
+	assert.Error(t, err)
+	_, err = c.Get(context.Background(), "failed", load)
+	require.NoError(t, err, "失败的结果不缓存")
+}
+
+func TestGetLoadsOnceForConcurrentCallers(t *testing.T) {
+	c := New[int](time.Minute)
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.Get(context.Background(), "k", load)
+		}()
+	}
+	assert.Eventually(t, func() bool { return loads.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, []int{42, 42, 42, 42, 42}, results)
+
+	uncached := New[int](0)
+	_, _ = uncached.Get(context.Background(), "k", load)
+	_, _ = uncached.Get(context.Background(), "k", load)
+	assert.Equal(t, int32(3), loads.Load(), "ttl 为 0 时不缓存")
+}
+
+func TestGetRetriesWhenLoaderCanceled(t *testing.T) {
+	c := New[int](time.Minute)
+	started := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		_, _ = c.Get(ctx, "k", func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+	}()
+	<-started
+
+	done := make(chan int)
+	go func() {
+		v, _ := c.Get(context.Background(), "k", func(context.Context) (int, error) { return 7, nil })
+		done <- v
+	}()
+	cancel()
+
+	assert.Equal(t, 7, <-done, "发起方取消后等待者自行加载")
+}
@@ -20,8 +20,12 @@ type SuccessResponse struct {
 
 // MCPToolResult MCP 工具结果（内部使用）
 type MCPToolResult struct {
-	Content []MCPContent `json:"content"`
-	IsError bool         `json:"isError,omitempty"`
+	Content     []MCPContent `json:"content"`
+	IsError     bool         `json:"isError,omitempty"`
+	ErrorCode   string       `json:"errorCode,omitempty"`   // 出错时的错误码，见 error_codes.go
+	DebugBundle string       `json:"debugBundle,omitempty"` // 出错时保存的调试包 ID
+
+	StructuredContent any `json:"structuredContent,omitempty"` // 成功时的结构化结果，与工具的输出 schema 一致
 }
 
 // MCPContent MCP 内容（内部使用）
@@ -32,25 +36,12 @@ type MCPContent struct {
 	Data     string `json:"data"`
 }
 
-// FeedDetailRequest Feed详情请求
-type FeedDetailRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
-}
-
 // FeedDetailResponse Feed详情响应
 type FeedDetailResponse struct {
 	FeedID string `json:"feed_id"`
 	Data   any    `json:"data"`
 }
 
-// PostCommentRequest 发表评论请求
-type PostCommentRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
-	Content   string `json:"content" binding:"required"`
-}
-
 // PostCommentResponse 发表评论响应
 type PostCommentResponse struct {
 	FeedID  string `json:"feed_id"`
@@ -58,10 +49,9 @@ type PostCommentResponse struct {
 	Message string `json:"message"`
 }
 
-// UserProfileRequest 用户主页请求
-type UserProfileRequest struct {
-	UserID    string `json:"user_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
+// UserProfileData 用户主页的 REST 响应，兼容旧版接口，用户主页位于 data.data
+type UserProfileData struct {
+	Data *UserProfileResponse `json:"data"`
 }
 
 // ActionResult 通用动作响应（点赞/收藏等）
@@ -71,12 +61,7 @@ type ActionResult struct {
 	Message string `json:"message"`
 }
 
-// BrowseRequest 浏览请求
-type BrowseRequest struct {
-	Duration            int      `json:"duration"`              // 浏览时长（分钟）
-	MinScrolls          int      `json:"min_scrolls"`           // 最小滚动次数
-	MaxScrolls          int      `json:"max_scrolls"`           // 最大滚动次数
-	ClickProbability    int      `json:"click_probability"`     // 点击笔记的概率 (0-100)
-	InteractProbability int      `json:"interact_probability"`  // 互动概率 (0-100)
-	Comments            []string `json:"comments,omitempty"`    // 评论内容列表
+// ParallelBrowseResult 并行浏览的 MCP 结构化结果
+type ParallelBrowseResult struct {
+	Results []*ParallelInstanceResult `json:"results"`
 }
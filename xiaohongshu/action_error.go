@@ -0,0 +1,125 @@
+package xiaohongshu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// ActionError 页面操作失败时返回，记录失败的步骤以及相关的选择器
+type ActionError struct {
+	Step     string // 失败的步骤，例如 "点击评论输入框"
+	Selector string // 相关的 CSS 选择器，可能为空
+	Err      error
+}
+
+func (e *ActionError) Error() string {
+	if e.Selector != "" {
+		return fmt.Sprintf("%s失败 [%s]: %v", e.Step, e.Selector, e.Err)
+	}
+	return fmt.Sprintf("%s失败: %v", e.Step, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// Is 按选择器查找或操作元素时找不到元素（或等待超时），视为 ErrSelectorMissing
+func (e *ActionError) Is(target error) bool {
+	if target != ErrSelectorMissing || e.Selector == "" {
+		return false
+	}
+	var notFound *rod.ElementNotFoundError
+	return errors.As(e.Err, &notFound) || errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// stepErr 包装步骤错误，err 为 nil 时返回 nil
+func stepErr(step, selector string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ActionError{Step: step, Selector: selector, Err: err}
+}
+
+// recoverAction 将动作执行过程中意外产生的 panic 转换为错误，保证 panic 不会逃逸出本包。
+// 用法：defer recoverAction(&err, "发表评论")
+func recoverAction(err *error, step string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	logrus.Errorf("%s 发生 panic: %v\n%s", step, r, debug.Stack())
+	cause, ok := r.(error)
+	if !ok {
+		cause = fmt.Errorf("%v", r)
+	}
+	*err = &ActionError{Step: step, Err: cause}
+}
+
+// navigate 打开 url 并等待页面 load 事件
+func navigate(page *rod.Page, step, url string) (err error) {
+	defer func() { traceStep(page, step+" "+url, "", err) }()
+
+	if err := page.Navigate(url); err != nil {
+		return stepErr(step, "", fmt.Errorf("打开 %s: %w", url, err))
+	}
+	if err := page.WaitLoad(); err != nil {
+		return stepErr(step, "", fmt.Errorf("等待 %s 加载: %w", url, err))
+	}
+	return nil
+}
+
+// waitDOMStable 等待页面 DOM 稳定（与 MustWaitDOMStable 的参数一致）
+func waitDOMStable(page *rod.Page, step string) error {
+	err := stepErr(step, "", page.WaitDOMStable(time.Second, 0))
+	traceStep(page, step, "", err)
+	return err
+}
+
+// clickElement 查找并点击元素
+func clickElement(page *rod.Page, step string, key SelectorKey) error {
+	el, err := findElement(page, step, key)
+	if err != nil {
+		return err
+	}
+	err = stepErr(step, selectorLabel(key), el.Click(proto.InputMouseButtonLeft, 1))
+	traceStep(page, step, key, err)
+	return err
+}
+
+// inputElement 查找元素并输入文本
+func inputElement(page *rod.Page, step string, key SelectorKey, text string) error {
+	el, err := findElement(page, step, key)
+	if err != nil {
+		return err
+	}
+	err = stepErr(step, selectorLabel(key), el.Input(text))
+	traceStep(page, step, key, err)
+	return err
+}
+
+// waitInitialState 等待页面注入 window.__INITIAL_STATE__
+func waitInitialState(page *rod.Page) error {
+	return stepErr("等待 __INITIAL_STATE__", "", page.Wait(rod.Eval(`() => window.__INITIAL_STATE__ !== undefined`)))
+}
+
+// readInitialState 读取 window.__INITIAL_STATE__ 的 JSON 字符串，不存在时返回空字符串
+func readInitialState(page *rod.Page) (string, error) {
+	res, err := page.Eval(`() => {
+		if (window.__INITIAL_STATE__) {
+			return JSON.stringify(window.__INITIAL_STATE__);
+		}
+		return "";
+	}`)
+	if err != nil {
+		return "", stepErr("读取 __INITIAL_STATE__", "", err)
+	}
+	return res.Value.String(), nil
+}
@@ -0,0 +1,34 @@
+package xiaohongshu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionError(t *testing.T) {
+	cause := errors.New("context deadline exceeded")
+	err := stepErr("点击评论输入框", "div.input-box div.content-edit span", cause)
+
+	assert.Equal(t, "点击评论输入框失败 [div.input-box div.content-edit span]: context deadline exceeded", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	var actionErr *ActionError
+	require.ErrorAs(t, err, &actionErr)
+	assert.Equal(t, "div.input-box div.content-edit span", actionErr.Selector)
+
+	assert.NoError(t, stepErr("打开页面", "", nil))
+}
+
+func TestRecoverAction(t *testing.T) {
+	run := func() (err error) {
+		defer recoverAction(&err, "发表评论")
+		panic(errors.New("element not found"))
+	}
+
+	err := run()
+	require.Error(t, err)
+	assert.Equal(t, "发表评论失败: element not found", err.Error())
+}
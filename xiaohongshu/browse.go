@@ -17,23 +17,19 @@ import (
 	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
 )
 
-func isRodSessionNotFoundErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	msg := err.Error()
-	return strings.Contains(msg, "Session with given id not found") || strings.Contains(msg, "-32001")
-}
-
+// 内置的浏览默认值，实际生效的默认值见配置文件的 browse 段
 const (
-	DefaultBrowseDurationMinutes = 60
-	DefaultMinScrollsPerRound    = 1
-	DefaultMaxScrollsPerRound    = 3
-	DefaultClickProbability      = 100
-	DefaultInteractProbability   = 60
-	DefaultLikeOnlyProbability   = 30
+	DefaultBrowseDurationMinutes = configs.DefaultBrowseDurationMinutes
+	DefaultMinScrollsPerRound    = configs.DefaultMinScrollsPerRound
+	DefaultMaxScrollsPerRound    = configs.DefaultMaxScrollsPerRound
+	DefaultClickProbability      = configs.DefaultClickProbability
+	DefaultInteractProbability   = configs.DefaultInteractProbability
+	DefaultLikeOnlyProbability   = configs.DefaultLikeOnlyProbability
 )
 
 // BrowseConfig 浏览配置
@@ -61,6 +57,9 @@ type BrowseAction struct {
 	page   *rod.Page
 	config BrowseConfig
 	ten    *tenTimesManager
+
+	lastProgress *BrowseProgress // 上次上报的统计，未变化时不重复上报
+	log          *logrus.Entry   // 带有请求 ID、实例 ID 等字段的日志记录器，StartBrowse 时按 ctx 设置
 }
 
 type tenTimesManager struct {
@@ -109,38 +108,45 @@ type BrowseStats struct {
 
 // NewBrowseAction 创建浏览动作
 func NewBrowseAction(page *rod.Page, config BrowseConfig) *BrowseAction {
-	// 设置默认值（仅当调用方未显式配置时才使用默认值）
+	// 设置默认值（仅当调用方未显式配置时才使用配置文件中的默认值）
+	defaults := configs.Get().Browse
 	if config.Duration == 0 {
-		config.Duration = DefaultBrowseDurationMinutes // 默认浏览时长
+		config.Duration = defaults.DurationMinutes // 默认浏览时长
 	}
 	if config.MinScrolls == 0 {
-		config.MinScrolls = DefaultMinScrollsPerRound // 每轮最小滚动次数
+		config.MinScrolls = defaults.MinScrolls // 每轮最小滚动次数
 	}
 	if config.MaxScrolls == 0 {
-		config.MaxScrolls = DefaultMaxScrollsPerRound // 每轮最大滚动次数
+		config.MaxScrolls = defaults.MaxScrolls // 每轮最大滚动次数
 	}
 	if config.ClickProbability == 0 {
-		config.ClickProbability = DefaultClickProbability // 点击概率
+		config.ClickProbability = defaults.ClickProbability // 点击概率
 	}
 	if config.InteractProbability == 0 {
-		config.InteractProbability = DefaultInteractProbability // 互动概率（兼容保留）
+		config.InteractProbability = defaults.InteractProbability // 互动概率（兼容保留）
 	}
 	if config.LikeOnlyProbability <= 0 || config.LikeOnlyProbability > 100 {
-		config.LikeOnlyProbability = DefaultLikeOnlyProbability // 仅点赞概率（兼容保留）
+		config.LikeOnlyProbability = defaults.LikeOnlyProbability // 仅点赞概率（兼容保留）
 	}
 
-	tenMgr, err := newTenTimesManager(config.InstanceID)
+	tenMgr, err := newTenTimesManager(config.InstanceID, defaults.TenTimesForceAll)
 	if err != nil {
-		logrus.WithError(err).WithField("instance", config.InstanceID).Warn("ten-times 初始化失败，将回退正常模式")
+		pageLog(page).WithError(err).WithField("instance", config.InstanceID).Warn("ten-times 初始化失败，将回退正常模式")
 		tenMgr = nil
 	}
 
-	return &BrowseAction{page: page, config: config, ten: tenMgr}
+	return &BrowseAction{page: page, config: config, ten: tenMgr, log: pageLog(page)}
 }
 
 // StartBrowse 开始浏览推荐页
-func (b *BrowseAction) StartBrowse(ctx context.Context) (*BrowseStats, error) {
-	logrus.Info("开始模拟人类浏览小红书推荐页")
+func (b *BrowseAction) StartBrowse(ctx context.Context) (_ *BrowseStats, err error) {
+	defer recoverAction(&err, "浏览推荐页")
+
+	b.log = logging.From(ctx)
+	if b.config.InstanceID != "" {
+		b.log = b.log.WithField("instance", b.config.InstanceID)
+	}
+	b.log.Info("开始模拟人类浏览小红书推荐页")
 
 	stats := &BrowseStats{
 		ViewedNotes: make([]string, 0),
@@ -150,11 +156,13 @@ func (b *BrowseAction) StartBrowse(ctx context.Context) (*BrowseStats, error) {
 
 	// 导航到推荐页
 	// 为首次导航创建一个独立的超时 context
-	navCtx, navCancel := context.WithTimeout(ctx, 60*time.Second)
-	defer navCancel()
-	navPage := b.page.Context(navCtx)
-	navPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-	waitForExploreReady(navPage, 6*time.Second) // 等待页面完全加载
+	if err := navigateExplore(ctx, b.page, "打开推荐页"); err != nil {
+		return nil, err
+	}
+	if err := diagnosePage(b.page.Context(ctx), "打开推荐页"); err != nil {
+		return nil, err
+	}
+	waitForExploreReady(b.page.Context(ctx), 6*time.Second) // 等待页面完全加载
 	pause(300, 700)
 
 	// 浏览时长
@@ -170,12 +178,12 @@ func (b *BrowseAction) StartBrowse(ctx context.Context) (*BrowseStats, error) {
 	// 刷新机制：模拟真实用户每隔几分钟刷新页面获取新内容
 	// 随机设置第一次刷新时间：2-5分钟后
 	nextRefreshTime := time.Now().Add(randomRefreshInterval())
-	logrus.Infof("计划在 %.1f 分钟后刷新页面", time.Until(nextRefreshTime).Minutes())
+	b.log.Infof("计划在 %.1f 分钟后刷新页面", time.Until(nextRefreshTime).Minutes())
 
 	for time.Now().Before(browseUntil) {
 		select {
 		case <-ctx.Done():
-			logrus.Info("浏览被取消")
+			b.log.Info("浏览被取消")
 			stats.Duration = time.Since(startTime)
 			return stats, ctx.Err()
 		default:
@@ -184,33 +192,33 @@ func (b *BrowseAction) StartBrowse(ctx context.Context) (*BrowseStats, error) {
 				if b.ten != nil && (b.ten.active != nil || len(b.ten.queue) > 0) {
 					delaySeconds := rand.Intn(31) + 30 // 30-60s
 					nextRefreshTime = time.Now().Add(time.Duration(delaySeconds) * time.Second)
-					logrus.WithFields(logrus.Fields{
+					b.log.WithFields(logrus.Fields{
 						"instance": b.config.InstanceID,
 						"delay_s":  delaySeconds,
 					}).Info("ten-times 任务进行中，延后刷新推荐页")
 				} else {
-				logrus.Info("刷新推荐页以获取新内容")
-				// 为刷新操作创建一个独立的超时 context
-				refreshCtx, refreshCancel := context.WithTimeout(ctx, 60*time.Second)
-				refreshPage := b.page.Context(refreshCtx)
-				refreshPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-				refreshCancel()                                 // 导航完成后立即取消 context
-				waitForExploreReady(refreshPage, 6*time.Second) // 刷新后等待加载
+				b.log.Info("刷新推荐页以获取新内容")
+				if err := navigateExplore(ctx, b.page, "刷新推荐页"); err != nil {
+					b.log.Warnf("刷新推荐页失败: %v", err)
+				}
+				waitForExploreReady(b.page.Context(ctx), 6*time.Second) // 刷新后等待加载
 				pause(300, 700)
 
 				// 设置下一次刷新时间：2-5分钟后
 				nextRefreshTime = time.Now().Add(randomRefreshInterval())
 				remainingMinutes := time.Until(browseUntil).Minutes()
 				if remainingMinutes > 0 {
-					logrus.Infof("页面已刷新，计划在 %.1f 分钟后再次刷新（剩余浏览时间: %.1f 分钟）",
+					b.log.Infof("页面已刷新，计划在 %.1f 分钟后再次刷新（剩余浏览时间: %.1f 分钟）",
 						time.Until(nextRefreshTime).Minutes(), remainingMinutes)
 				}
 				}
 			}
 
 			// 执行一轮浏览
-			if err := b.browseRound(ctx, stats); err != nil {
-				logrus.Warnf("浏览出错: %v", err)
+			err := b.browseRound(ctx, stats)
+			b.reportProgress(ctx, stats, startTime)
+			if err != nil {
+				b.log.Warnf("浏览出错: %v", err)
 				pause(1200, 2500)
 				continue
 			}
@@ -218,12 +226,32 @@ func (b *BrowseAction) StartBrowse(ctx context.Context) (*BrowseStats, error) {
 	}
 
 	stats.Duration = time.Since(startTime)
-	logrus.Infof("浏览完成 - 统计: 滚动%d次, 点击%d个笔记, 点赞%d次, 收藏%d次, 评论%d次",
+	b.log.Infof("浏览完成 - 统计: 滚动%d次, 点击%d个笔记, 点赞%d次, 收藏%d次, 评论%d次",
 		stats.ScrollCount, stats.ClickCount, stats.LikeCount, stats.FavoriteCount, stats.CommentCount)
 
 	return stats, nil
 }
 
+// reportProgress 统计数据变化时上报浏览进度
+func (b *BrowseAction) reportProgress(ctx context.Context, stats *BrowseStats, startTime time.Time) {
+	p := browseProgress(b.config.InstanceID, stats, time.Since(startTime))
+	if last := b.lastProgress; last != nil {
+		unchanged := *last
+		unchanged.ElapsedSeconds = p.ElapsedSeconds
+		if unchanged == *p {
+			return
+		}
+	}
+	b.lastProgress = p
+
+	ReportProgress(ctx, Progress{
+		Stage: StageBrowse,
+		Message: fmt.Sprintf("已浏览 %d 个笔记，点赞 %d 次，收藏 %d 次，评论 %d 次",
+			p.ViewedCount, p.LikeCount, p.FavoriteCount, p.CommentCount),
+		Browse: p,
+	})
+}
+
 // browseRound 执行一轮浏览
 func (b *BrowseAction) browseRound(ctx context.Context, stats *BrowseStats) error {
 	page := b.page.Context(ctx)
@@ -235,7 +263,7 @@ func (b *BrowseAction) browseRound(ctx context.Context, stats *BrowseStats) erro
 		if b.ten != nil {
 			processed, err := b.processTenTimesIfNeeded(ctx, stats)
 			if err != nil {
-				logrus.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 处理异常，继续正常浏览")
+				b.log.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 处理异常，继续正常浏览")
 			} else if processed {
 				pause(700, 1100)
 				continue
@@ -254,7 +282,7 @@ func (b *BrowseAction) browseRound(ctx context.Context, stats *BrowseStats) erro
 		if b.ten != nil {
 			processed, err := b.processTenTimesIfNeeded(ctx, stats)
 			if err != nil {
-				logrus.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 处理异常，继续正常浏览")
+				b.log.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 处理异常，继续正常浏览")
 			} else if processed {
 				pause(700, 1100)
 				continue
@@ -264,13 +292,12 @@ func (b *BrowseAction) browseRound(ctx context.Context, stats *BrowseStats) erro
 		// 根据概率决定是否点击笔记
 		if rand.Intn(100) < b.config.ClickProbability {
 			if err := b.clickAndViewNote(ctx, stats); err != nil {
-				logrus.Warnf("点击笔记出错: %v", err)
-				// 返回推荐页 - 创建独立的超时 context
-				recoverCtx, recoverCancel := context.WithTimeout(ctx, 60*time.Second)
-				recoverPage := page.Context(recoverCtx)
-				recoverPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-				recoverCancel()
-				waitForExploreReady(recoverPage, 6*time.Second)
+				b.log.Warnf("点击笔记出错: %v", err)
+				// 返回推荐页
+				if err := navigateExplore(ctx, page, "返回推荐页"); err != nil {
+					return err
+				}
+				waitForExploreReady(page, 6*time.Second)
 				pause(300, 700)
 			}
 		}
@@ -298,7 +325,7 @@ func (b *BrowseAction) processTenTimesIfNeeded(ctx context.Context, stats *Brows
 		if !didInteract && !done {
 			b.ten.activeNotVisible++
 			if b.ten.activeNotVisible >= 6 {
-				logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "feed_id": b.ten.active.FeedID}).Warn("ten-times active 目标长时间不可见，回退正常浏览")
+				b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "feed_id": b.ten.active.FeedID}).Warn("ten-times active 目标长时间不可见，回退正常浏览")
 				b.ten.active = nil
 				b.ten.activeNotVisible = 0
 				return false, nil
@@ -338,7 +365,7 @@ func (b *BrowseAction) processTenTimesIfNeeded(ctx context.Context, stats *Brows
 		authorName, nameErr := b.extractAuthorNameFromCard(card)
 		authorSource := "dom"
 		if nameErr != nil {
-			logrus.WithError(nameErr).WithFields(logrus.Fields{"feed_id": feedID, "instance": b.config.InstanceID}).Debug("提取博主名失败")
+			b.log.WithError(nameErr).WithFields(logrus.Fields{"feed_id": feedID, "instance": b.config.InstanceID}).Debug("提取博主名失败")
 			authorSource = "initial_state"
 		}
 		if strings.TrimSpace(authorName) == "" {
@@ -355,7 +382,7 @@ func (b *BrowseAction) processTenTimesIfNeeded(ctx context.Context, stats *Brows
 		if !matchedTarget && authorName != "" {
 			_, matchedTarget = b.ten.targets[authorName]
 		}
-		logrus.WithFields(logrus.Fields{
+		b.log.WithFields(logrus.Fields{
 			"instance":       b.config.InstanceID,
 			"feed_id":        feedID,
 			"author":         authorName,
@@ -395,7 +422,7 @@ func (b *BrowseAction) processTenTimesIfNeeded(ctx context.Context, stats *Brows
 
 		b.ten.queue = append(b.ten.queue, &tenTask{Author: authorName, FeedID: feedID, XsecToken: xsecToken, Title: title})
 		b.ten.enqueued[feedID] = struct{}{}
-		logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": authorName, "feed_id": feedID}).Info("ten-times 入队目标笔记")
+		b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": authorName, "feed_id": feedID}).Info("ten-times 入队目标笔记")
 	}
 
 	if len(b.ten.queue) == 0 {
@@ -458,7 +485,7 @@ func (b *BrowseAction) executeTenInteractionStep(ctx context.Context, stats *Bro
 
 	card, findErr := b.findVisibleCardByFeedID(page, task.FeedID)
 	if findErr != nil || card == nil {
-		logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID, "count": st.Count}).Info("ten-times 当前目标不可见，继续滚动等待")
+		b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID, "count": st.Count}).Info("ten-times 当前目标不可见，继续滚动等待")
 		return false, false, nil
 	}
 
@@ -472,28 +499,27 @@ func (b *BrowseAction) executeTenInteractionStep(ctx context.Context, stats *Bro
 		pause(600, 1100)
 	}
 	if openErr != nil {
-		logrus.WithError(openErr).WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Warn("ten-times 打开笔记失败，将稍后重试")
+		b.log.WithError(openErr).WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Warn("ten-times 打开笔记失败，将稍后重试")
 		return false, false, nil
 	}
 
-	logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID, "round": st.Count + 1}).Info("ten-times 开始互动")
+	b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID, "round": st.Count + 1}).Info("ten-times 开始互动")
 
 	if browseErr := b.browseNoteToBottom(page, task.FeedID, isRealVideo); browseErr != nil {
-		logrus.WithError(browseErr).WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Warn("ten-times 浏览到底失败")
+		b.log.WithError(browseErr).WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Warn("ten-times 浏览到底失败")
 	}
 
 	if st.Count == 0 {
-		logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Info("ten-times 首次互动：尝试点赞+收藏")
+		b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Info("ten-times 首次互动：尝试点赞+收藏")
 		b.forceLikeAndFavoriteInModal(page, task.FeedID, stats)
 	}
 
 	if err := b.closeNoteModal(page); err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Warn("ten-times 关闭弹窗失败")
-		fallbackCtx, fallbackCancel := context.WithTimeout(ctx, 60*time.Second)
-		fallbackPage := page.Context(fallbackCtx)
-		fallbackPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-		fallbackCancel()
-		waitForExploreReady(fallbackPage, 6*time.Second)
+		b.log.WithError(err).WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Warn("ten-times 关闭弹窗失败")
+		if navErr := navigateExplore(ctx, page, "返回推荐页"); navErr != nil {
+			b.log.WithError(navErr).WithField("instance", b.config.InstanceID).Warn("ten-times 返回推荐页失败")
+		}
+		waitForExploreReady(page, 6*time.Second)
 	} else {
 		waitForNoteModalClosed(page, 3*time.Second)
 	}
@@ -505,16 +531,16 @@ func (b *BrowseAction) executeTenInteractionStep(ctx context.Context, stats *Bro
 		st.Completed = true
 	}
 	if err := b.ten.saveState(); err != nil {
-		logrus.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 保存状态失败")
+		b.log.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 保存状态失败")
 	}
 
-	logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID, "count": st.Count}).Info("ten-times 单次互动完成")
+	b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID, "count": st.Count}).Info("ten-times 单次互动完成")
 
 	if st.Completed {
 		if err := b.ten.appendCompleted(task.Author, task.FeedID, st.Title); err != nil {
-			logrus.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 写入 completed 失败")
+			b.log.WithError(err).WithField("instance", b.config.InstanceID).Warn("ten-times 写入 completed 失败")
 		}
-		logrus.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Info("ten-times 十次互动完成")
+		b.log.WithFields(logrus.Fields{"instance": b.config.InstanceID, "author": task.Author, "feed_id": task.FeedID}).Info("ten-times 十次互动完成")
 		return true, true, nil
 	}
 
@@ -585,16 +611,7 @@ func (b *BrowseAction) extractTitleFromCard(card *rod.Element) (string, error) {
 		return "", fmt.Errorf("nil card")
 	}
 
-	selectors := []string{
-		"a.title span",
-		"a.title",
-		".title span",
-		".title",
-		"a[class*='title'] span",
-		"a[class*='title']",
-	}
-
-	for _, sel := range selectors {
+	for _, sel := range selectorsFor(SelectorNoteCardTitle) {
 		el, err := card.Element(sel)
 		if err != nil || el == nil {
 			continue
@@ -671,16 +688,14 @@ func (b *BrowseAction) browseNoteToBottom(page *rod.Page, feedID string, isRealV
 	}
 
 	if err := b.scrollCommentArea(page); err != nil {
-		logrus.WithError(err).Debug("ten-times 评论区滚动失败")
+		b.log.WithError(err).Debug("ten-times 评论区滚动失败")
 	}
 	return nil
 }
 
 func scrollModalOnce(page *rod.Page) (before int, after int, err error) {
-	res := page.MustEval(`() => {
-		const modal = document.querySelector('.note-detail-modal') ||
-		             document.querySelector('.modal') ||
-		             document.querySelector('[class*="detail"]');
+	obj, err := page.Eval(`(modalSelectors) => {
+		const modal = modalSelectors.map((s) => document.querySelector(s)).find(Boolean);
 		if (!modal) return { ok: false, before: 0, after: 0 };
 		const candidates = [];
 		candidates.push(modal);
@@ -700,7 +715,11 @@ func scrollModalOnce(page *rod.Page) (before int, after int, err error) {
 		el.scrollTop = Math.min(before + step, (el.scrollHeight || 0));
 		const after = el.scrollTop || 0;
 		return { ok: true, before, after };
-	}`)
+	}`, selectorsFor(SelectorNoteModal))
+	if err != nil {
+		return 0, 0, err
+	}
+	res := obj.Value
 
 	ok := res.Get("ok").Bool()
 	if !ok {
@@ -709,17 +728,9 @@ func scrollModalOnce(page *rod.Page) (before int, after int, err error) {
 	return res.Get("before").Int(), res.Get("after").Int(), nil
 }
 
-func newTenTimesManager(instanceID string) (*tenTimesManager, error) {
+func newTenTimesManager(instanceID string, forceAll bool) (*tenTimesManager, error) {
 	timesPath, completedPath, statePath := resolveTenFiles(instanceID)
 
-	forceAll := false
-	if v := strings.ToLower(strings.TrimSpace(os.Getenv("TEN_TIMES_FORCE_ALL"))); v != "" {
-		switch v {
-		case "1", "true", "yes", "on":
-			forceAll = true
-		}
-	}
-
 	var targets map[string]struct{}
 	var err error
 	if !forceAll {
@@ -974,14 +985,8 @@ func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
 }
 
 func (b *BrowseAction) getNoteModalRoot(page *rod.Page) (*rod.Element, error) {
-	selectors := []string{
-		".note-detail-modal",
-		".modal",
-		"[class*='detail']",
-	}
-
 	for i := 0; i < 6; i++ {
-		for _, sel := range selectors {
+		for _, sel := range selectorsFor(SelectorNoteModal) {
 			var el *rod.Element
 			err := rod.Try(func() {
 				el = page.Timeout(500 * time.Millisecond).MustElement(sel)
@@ -1000,8 +1005,9 @@ func (b *BrowseAction) getNoteModalRoot(page *rod.Page) (*rod.Element, error) {
 }
 
 func normalizeModalSelector(sel string) string {
-	sel = strings.TrimPrefix(sel, ".note-detail-modal ")
-	sel = strings.TrimPrefix(sel, ".modal ")
+	for _, modal := range selectorsFor(SelectorNoteModal) {
+		sel = strings.TrimPrefix(sel, modal+" ")
+	}
 	return sel
 }
 
@@ -1057,16 +1063,10 @@ func (b *BrowseAction) getFollowStatusInModal(page *rod.Page) (FollowStatus, err
 		modal = nil
 	}
 
-	selectors := []string{
-		"button.follow-button",
-		".follow-button",
-		"button[class*='follow']",
-	}
-
 	var btn *rod.Element
 	if modal != nil {
 		for i := 0; i < 6 && btn == nil; i++ {
-			for _, sel := range selectors {
+			for _, sel := range selectorsFor(SelectorFollowButton) {
 				var el *rod.Element
 				err := rod.Try(func() {
 					el = modal.Timeout(500 * time.Millisecond).MustElement(sel)
@@ -1095,7 +1095,7 @@ func (b *BrowseAction) getFollowStatusInModal(page *rod.Page) (FollowStatus, err
 		if cls != nil {
 			classStr = *cls
 		}
-		logrus.Infof("关注按钮命中(选择器)：text=%s class=%s", text, classStr)
+		b.log.Infof("关注按钮命中(选择器)：text=%s class=%s", text, classStr)
 
 		if strings.Contains(text, "互相关注") {
 			return FollowStatusMutual, nil
@@ -1115,10 +1115,10 @@ func (b *BrowseAction) getFollowStatusInModal(page *rod.Page) (FollowStatus, err
 	}
 
 	// 兜底：用 JS 扫描页面内可见 button，按文本“关注/已关注/互相关注”识别
-	resultJSON := page.Timeout(2 * time.Second).MustEval(`() => {
+	resultJSONObj, err := page.Timeout(2 * time.Second).Eval(`(modalSelectors) => {
 		const normalizeText = (t) => (t || '').replace(/\s+/g, ' ').trim();
 		const roots = [];
-		const modal = document.querySelector('.note-detail-modal') || document.querySelector('.modal');
+		const modal = modalSelectors.map((s) => document.querySelector(s)).find(Boolean);
 		if (modal) roots.push(modal);
 		roots.push(document);
 
@@ -1161,7 +1161,11 @@ func (b *BrowseAction) getFollowStatusInModal(page *rod.Page) (FollowStatus, err
 			class: chosen ? chosen.class : '',
 			candidates
 		});
-	}`).String()
+	}`, selectorsFor(SelectorNoteModal))
+	if err != nil {
+		return FollowStatusUnknown, fmt.Errorf("关注状态 JS 扫描失败: %w", err)
+	}
+	resultJSON := resultJSONObj.Value.String()
 
 	var scan struct {
 		Found      bool   `json:"found"`
@@ -1180,7 +1184,7 @@ func (b *BrowseAction) getFollowStatusInModal(page *rod.Page) (FollowStatus, err
 		return FollowStatusUnknown, fmt.Errorf("未找到关注按钮")
 	}
 
-	logrus.Infof("关注按钮命中(JS)：text=%s class=%s", scan.Text, scan.Class)
+	b.log.Infof("关注按钮命中(JS)：text=%s class=%s", scan.Text, scan.Class)
 	if strings.Contains(scan.Text, "互相关注") {
 		return FollowStatusMutual, nil
 	}
@@ -1267,28 +1271,8 @@ func (b *BrowseAction) getLikeCollectStateFromDOM(modal *rod.Element) (liked boo
 		return nil, fmt.Errorf("not found")
 	}
 
-	likeSelectors := []string{
-		".interact-container .left .like-wrapper",
-		".left .like-wrapper",
-		".like-wrapper",
-		".interact-container .left .like-lottie",
-		".like-lottie",
-		".reds-icon.like-icon",
-		"[class*='like']",
-	}
-	collectSelectors := []string{
-		"#note-page-collect-board-guide",
-		".interact-container .left .collect-wrapper",
-		".left .collect-wrapper",
-		".collect-wrapper",
-		".interact-container .left .reds-icon.collect-icon",
-		".collect-icon",
-		".reds-icon.collect-icon",
-		"[class*='collect']",
-	}
-
-	likeEl, _ := findVisible(likeSelectors)
-	collectEl, _ := findVisible(collectSelectors)
+	likeEl, _ := findVisible(selectorsFor(SelectorLikeState))
+	collectEl, _ := findVisible(selectorsFor(SelectorCollectState))
 	if likeEl == nil && collectEl == nil {
 		return false, false, fmt.Errorf("未找到点赞/收藏按钮")
 	}
@@ -1306,41 +1290,47 @@ func (b *BrowseAction) getLikeCollectStateFromDOM(modal *rod.Element) (liked boo
 func (b *BrowseAction) forceLikeAndFavoriteInModal(page *rod.Page, feedID string, stats *BrowseStats) {
 	modal, modalErr := b.getNoteModalRoot(page)
 	if modalErr != nil {
-		logrus.Warnf("未找到弹窗容器，跳过点赞/收藏: %v", modalErr)
+		b.log.Warnf("未找到弹窗容器，跳过点赞/收藏: %v", modalErr)
 		return
 	}
 	likedDOM, collectedDOM, domErr := b.getLikeCollectStateFromDOM(modal)
 	if domErr != nil {
-		logrus.Warnf("互动状态(DOM)读取失败，将直接尝试点赞/收藏（仍会在 likeInModal/favoriteInModal 内做防撤销判断）: %v", domErr)
+		b.log.Warnf("互动状态(DOM)读取失败，将直接尝试点赞/收藏（仍会在 likeInModal/favoriteInModal 内做防撤销判断）: %v", domErr)
 	}
-	logrus.Infof("互动状态(DOM)：liked=%v collected=%v", likedDOM, collectedDOM)
+	b.log.Infof("互动状态(DOM)：liked=%v collected=%v", likedDOM, collectedDOM)
 
 	if !likedDOM {
-		logrus.Info("准备点赞：当前未点赞")
-		if err := b.likeInModal(page); err != nil {
-			logrus.Warnf("点赞失败: %v", err)
+		b.log.Info("准备点赞：当前未点赞")
+		if release, err := reserveInteraction(page.GetContext(), configs.ActionLike); err != nil {
+			b.log.Warnf("跳过点赞: %v", err)
+		} else if err := b.likeInModal(page); err != nil {
+			release()
+			b.log.Warnf("点赞失败: %v", err)
 		} else {
-			logrus.Info("点赞完成")
+			b.log.Info("点赞完成")
 			stats.LikeCount++
 			pause(300, 900)
 		}
 	} else {
-		logrus.Info("跳过点赞：已点赞（防撤销）")
+		b.log.Info("跳过点赞：已点赞（防撤销）")
 	}
 
 	// 点赞后 UI 可能会重绘，收藏前再刷新一次状态
 	likedDOM, collectedDOM, _ = b.getLikeCollectStateFromDOM(modal)
 	if !collectedDOM {
-		logrus.Info("准备收藏：当前未收藏")
-		if err := b.favoriteInModal(page); err != nil {
-			logrus.Warnf("收藏失败: %v", err)
+		b.log.Info("准备收藏：当前未收藏")
+		if release, err := reserveInteraction(page.GetContext(), configs.ActionFavorite); err != nil {
+			b.log.Warnf("跳过收藏: %v", err)
+		} else if err := b.favoriteInModal(page); err != nil {
+			release()
+			b.log.Warnf("收藏失败: %v", err)
 		} else {
-			logrus.Info("收藏完成")
+			b.log.Info("收藏完成")
 			stats.FavoriteCount++
 			pause(400, 1100)
 		}
 	} else {
-		logrus.Info("跳过收藏：已收藏（防撤销）")
+		b.log.Info("跳过收藏：已收藏（防撤销）")
 	}
 }
 
@@ -1366,7 +1356,9 @@ func (b *BrowseAction) humanLikeScrollWithBacktrack(page *rod.Page) error {
 			if i == segments-1 {
 				segmentScroll = totalScroll - (segmentScroll * (segments - 1))
 			}
-			page.Mouse.MustScroll(0, float64(segmentScroll))
+			if err := page.Mouse.Scroll(0, float64(segmentScroll), 0); err != nil {
+				return stepErr("鼠标滚动推荐页", "", err)
+			}
 
 			// 插入短暂停：0.2-1.2s
 			time.Sleep(randomDuration(150, 700))
@@ -1376,14 +1368,18 @@ func (b *BrowseAction) humanLikeScrollWithBacktrack(page *rod.Page) error {
 		// 使用键盘方向键
 		times := rand.Intn(3) + 1 // 1-3次（减少滚动次数）
 		for i := 0; i < times; i++ {
-			page.MustElement("body").MustKeyActions().Press(input.ArrowDown).MustDo()
+			if err := pressPageKey(page, input.ArrowDown); err != nil {
+				return stepErr("键盘滚动推荐页", "body", err)
+			}
 			// 插入短暂停：0.2-1.2s
 			time.Sleep(randomDuration(150, 700))
 		}
 
 	case 2:
 		// 使用 JavaScript 滚动
-		page.MustEval(fmt.Sprintf(`() => window.scrollBy({top: %d, behavior: 'smooth'})`, scrollAmount))
+		if _, err := page.Eval(fmt.Sprintf(`() => window.scrollBy({top: %d, behavior: 'smooth'})`, scrollAmount)); err != nil {
+			return stepErr("JS 滚动推荐页", "", err)
+		}
 		// 等待滚动动画完成
 		time.Sleep(randomDuration(450, 800))
 	}
@@ -1394,7 +1390,7 @@ func (b *BrowseAction) humanLikeScrollWithBacktrack(page *rod.Page) error {
 	// 回滚概率：7-18%
 	backtrackProbability := rand.Intn(12) + 7 // 7-18
 	if rand.Intn(100) < backtrackProbability {
-		logrus.Debug("触发回滚行为")
+		b.log.Debug("触发回滚行为")
 		// 向上回滚一小段距离（通常是滚动距离的 20-40%）
 		backtrackAmount := -(scrollAmount * (rand.Intn(20) + 20) / 100)
 
@@ -1402,12 +1398,16 @@ func (b *BrowseAction) humanLikeScrollWithBacktrack(page *rod.Page) error {
 			// 键盘回滚
 			times := rand.Intn(2) + 1
 			for i := 0; i < times; i++ {
-				page.MustElement("body").MustKeyActions().Press(input.ArrowUp).MustDo()
+				if err := pressPageKey(page, input.ArrowUp); err != nil {
+					return stepErr("键盘回滚推荐页", "body", err)
+				}
 				time.Sleep(randomDuration(150, 400))
 			}
 		} else {
 			// 鼠标或JS回滚
-			page.Mouse.MustScroll(0, float64(backtrackAmount))
+			if err := page.Mouse.Scroll(0, float64(backtrackAmount), 0); err != nil {
+				return stepErr("鼠标回滚推荐页", "", err)
+			}
 		}
 
 		// 回滚后的停顿
@@ -1421,7 +1421,7 @@ func (b *BrowseAction) humanLikeScrollWithBacktrack(page *rod.Page) error {
 func (b *BrowseAction) clickAndViewNote(ctx context.Context, stats *BrowseStats) error {
 	page := b.page.Context(ctx)
 
-	logrus.Debug("========== 开始点击笔记流程 ==========")
+	b.log.Debug("========== 开始点击笔记流程 ==========")
 
 	// 步骤1&2: 获取笔记列表并选择要浏览的笔记
 	selectedFeed, err := b.selectFeedForClick(page, stats)
@@ -1432,12 +1432,12 @@ func (b *BrowseAction) clickAndViewNote(ctx context.Context, stats *BrowseStats)
 	xsecToken := selectedFeed.XsecToken
 
 	if feedID == "" || xsecToken == "" {
-		logrus.Error("笔记信息不完整")
+		b.log.Error("笔记信息不完整")
 		return fmt.Errorf("笔记信息不完整")
 	}
 
 	// 步骤3: 记录选中笔记的基础信息（暂不在此阶段区分真视频/图文）
-	logrus.Debugf("步骤3: 选中笔记 ID=%s, type=%s", feedID, selectedFeed.NoteCard.Type)
+	b.log.Debugf("步骤3: 选中笔记 ID=%s, type=%s", feedID, selectedFeed.NoteCard.Type)
 
 	// 步骤4&5: 在可见卡片中查找匹配的笔记
 	selectedCard, err := b.selectCardForFeed(page, selectedFeed)
@@ -1452,14 +1452,12 @@ func (b *BrowseAction) clickAndViewNote(ctx context.Context, stats *BrowseStats)
 		if err == nil {
 			break
 		}
-		if attempt == 0 && isRodSessionNotFoundErr(err) {
-			logrus.Warnf("检测到 Rod session 失效，尝试刷新并重试点击: %v", err)
-			recoverCtx, recoverCancel := context.WithTimeout(ctx, 60*time.Second)
-			recoverPage := page.Context(recoverCtx)
-			recoverPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-			recoverCancel()
-			page = recoverPage
-			waitForExploreReady(recoverPage, 6*time.Second)
+		if attempt == 0 && browser.IsSessionNotFoundErr(err) {
+			b.log.Warnf("检测到 Rod session 失效，尝试刷新并重试点击: %v", err)
+			if err := navigateExplore(ctx, page, "刷新推荐页"); err != nil {
+				return err
+			}
+			waitForExploreReady(page, 6*time.Second)
 			pause(300, 700)
 
 			selectedCard, err = b.selectCardForFeed(page, selectedFeed)
@@ -1474,10 +1472,10 @@ func (b *BrowseAction) clickAndViewNote(ctx context.Context, stats *BrowseStats)
 		return err
 	}
 
-	logrus.Info("步骤7后: 检测作者关注状态")
+	b.log.Info("步骤7后: 检测作者关注状态")
 	followStatus, followErr := b.getFollowStatusInModal(page)
 	if followErr != nil {
-		logrus.Warnf("关注状态检测失败，将按未关注处理并退出: %v", followErr)
+		b.log.Warnf("关注状态检测失败，将按未关注处理并退出: %v", followErr)
 		followStatus = FollowStatusNotFollowed
 	}
 
@@ -1486,77 +1484,75 @@ func (b *BrowseAction) clickAndViewNote(ctx context.Context, stats *BrowseStats)
 	// followStatus != FollowStatusFollowed && followStatus != FollowStatusMutual正常逻辑
 
 	if followStatus != FollowStatusFollowed && followStatus != FollowStatusMutual {
-		logrus.Info("检测到未关注，停留 2-4 秒后直接退出（不执行互动）")
+		b.log.Info("检测到未关注，停留 2-4 秒后直接退出（不执行互动）")
 		pause(2000, 4000)
-		logrus.Info("步骤10: 关闭笔记弹窗")
+		b.log.Info("步骤10: 关闭笔记弹窗")
 		pause(300, 800)
 		if err := b.closeNoteModal(page); err != nil {
-			logrus.Warnf("关闭笔记弹窗失败，尝试刷新页面: %v", err)
-			fallbackCtx, fallbackCancel := context.WithTimeout(ctx, 60*time.Second)
-			fallbackPage := page.Context(fallbackCtx)
-			fallbackPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-			fallbackCancel()
+			b.log.Warnf("关闭笔记弹窗失败，尝试刷新页面: %v", err)
+			if err := navigateExplore(ctx, page, "刷新推荐页"); err != nil {
+				return err
+			}
 		} else {
-			logrus.Info("笔记弹窗关闭成功")
+			b.log.Info("笔记弹窗关闭成功")
 		}
 		waitForNoteModalClosed(page, 3*time.Second)
 		pause(200, 1000)
-		logrus.Info("========== 笔记点击流程完成 ==========")
+		b.log.Info("========== 笔记点击流程完成 ==========")
 		return nil
 	}
 
 	if isRealVideo {
-		logrus.Info("步骤7判定当前笔记为真视频，将采用视频浏览策略")
+		b.log.Info("步骤7判定当前笔记为真视频，将采用视频浏览策略")
 	} else {
-		logrus.Info("步骤7判定当前笔记为图文/动图，将采用图文浏览策略")
+		b.log.Info("步骤7判定当前笔记为图文/动图，将采用图文浏览策略")
 	}
 
 	// 步骤8: 浏览笔记内容
-	logrus.Info("步骤8: 浏览笔记内容")
+	b.log.Info("步骤8: 浏览笔记内容")
 	if err := b.browseNoteContent(page, feedID, isRealVideo); err != nil {
-		logrus.Warnf("浏览笔记内容出错: %v", err)
+		b.log.Warnf("浏览笔记内容出错: %v", err)
 	} else {
-		logrus.Info("笔记内容浏览完成")
+		b.log.Info("笔记内容浏览完成")
 	}
 
 	// 步骤9: 检查是否需要互动
-	logrus.Info("检测到已关注/互关：执行 100% 点赞+收藏")
+	b.log.Info("检测到已关注/互关：执行 100% 点赞+收藏")
 	b.forceLikeAndFavoriteInModal(page, feedID, stats)
-	logrus.Info("笔记互动完成")
+	b.log.Info("笔记互动完成")
 
 	// 步骤10: 关闭笔记弹窗（使用自然的方式）
-	logrus.Info("步骤10: 关闭笔记弹窗")
+	b.log.Info("步骤10: 关闭笔记弹窗")
 	pause(300, 800)
 	if err := b.closeNoteModal(page); err != nil {
-		logrus.Warnf("关闭笔记弹窗失败，尝试刷新页面: %v", err)
-		// 降级方案：刷新页面 - 创建独立的超时 context
-		fallbackCtx, fallbackCancel := context.WithTimeout(ctx, 60*time.Second)
-		fallbackPage := page.Context(fallbackCtx)
-		fallbackPage.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
-		fallbackCancel()
+		b.log.Warnf("关闭笔记弹窗失败，尝试刷新页面: %v", err)
+		// 降级方案：刷新页面
+		if err := navigateExplore(ctx, page, "刷新推荐页"); err != nil {
+			return err
+		}
 	} else {
-		logrus.Info("笔记弹窗关闭成功")
+		b.log.Info("笔记弹窗关闭成功")
 	}
 	waitForNoteModalClosed(page, 3*time.Second)
 	pause(200, 1000)
 
-	logrus.Info("========== 笔记点击流程完成 ==========")
+	b.log.Info("========== 笔记点击流程完成 ==========")
 	return nil
 }
 
 // selectFeedForClick 从页面数据和已浏览记录中选择要浏览的笔记
 func (b *BrowseAction) selectFeedForClick(page *rod.Page, stats *BrowseStats) (Feed, error) {
 	// 从 window.__INITIAL_STATE__ 获取笔记列表（与其他 MCP 功能保持一致）
-	logrus.Debug("步骤1: 从页面获取笔记列表")
+	b.log.Debug("步骤1: 从页面获取笔记列表")
 	feeds, err := b.getFeedsFromPage(page)
 	if err != nil || len(feeds) == 0 {
-		logrus.Errorf("获取笔记列表失败: %v", err)
+		b.log.Errorf("获取笔记列表失败: %v", err)
 		return Feed{}, fmt.Errorf("未找到笔记列表: %v", err)
 	}
-	logrus.Debugf("成功获取 %d 条笔记", len(feeds))
+	b.log.Debugf("成功获取 %d 条笔记", len(feeds))
 
 	// 基于已浏览去重，优先选择未浏览的笔记
-	logrus.Debug("步骤2: 筛选未浏览的笔记")
+	b.log.Debug("步骤2: 筛选未浏览的笔记")
 
 	viewed := stats.viewedSet
 	if viewed == nil {
@@ -1580,11 +1576,11 @@ func (b *BrowseAction) selectFeedForClick(page *rod.Page, stats *BrowseStats) (F
 	var selectedFeed Feed
 	if len(unviewed) > 0 {
 		selectedFeed = unviewed[rand.Intn(len(unviewed))]
-		logrus.Infof("选择未浏览笔记，剩余 %d 条未浏览", len(unviewed))
+		b.log.Infof("选择未浏览笔记，剩余 %d 条未浏览", len(unviewed))
 	} else {
 		// 回退：都看过则仍随机一个，但尽量通过滚动引入新内容
 		selectedFeed = feeds[rand.Intn(len(feeds))]
-		logrus.Info("所有笔记都已浏览，随机选择一条")
+		b.log.Info("所有笔记都已浏览，随机选择一条")
 	}
 
 	return selectedFeed, nil
@@ -1595,29 +1591,29 @@ func (b *BrowseAction) selectCardForFeed(page *rod.Page, selectedFeed Feed) (*ro
 	feedID := selectedFeed.ID
 
 	// 获取对应的 DOM 元素并点击
-	logrus.Debug("步骤4: 查找可见的笔记卡片")
+	b.log.Debug("步骤4: 查找可见的笔记卡片")
 	noteCards, err := b.getVisibleNoteCards(page)
 	if err != nil || len(noteCards) == 0 {
-		logrus.Errorf("查找笔记卡片失败: %v", err)
+		b.log.Errorf("查找笔记卡片失败: %v", err)
 		return nil, fmt.Errorf("未找到可见笔记卡片")
 	}
-	logrus.Infof("找到 %d 个可见笔记卡片", len(noteCards))
+	b.log.Infof("找到 %d 个可见笔记卡片", len(noteCards))
 
 	// 在可见卡片中寻找对应 feedID 的卡片；找不到则回退随机
-	logrus.Debug("步骤5: 匹配笔记卡片")
+	b.log.Debug("步骤5: 匹配笔记卡片")
 	var selectedCard *rod.Element
 	for _, card := range noteCards {
 		id, token, _ := b.extractNoteInfo(card)
 		if id == feedID || (id != "" && id == selectedFeed.ID) {
 			selectedCard = card
-			logrus.Infof("找到匹配的笔记卡片: ID=%s", id)
+			b.log.Infof("找到匹配的笔记卡片: ID=%s", id)
 			break
 		}
 		_ = token // 保持一致性，后续如需校验可用
 	}
 	if selectedCard == nil {
 		selectedCard = noteCards[rand.Intn(len(noteCards))]
-		logrus.Info("未找到匹配的笔记卡片，随机选择一个")
+		b.log.Info("未找到匹配的笔记卡片，随机选择一个")
 	}
 
 	return selectedCard, nil
@@ -1626,12 +1622,12 @@ func (b *BrowseAction) selectCardForFeed(page *rod.Page, selectedFeed Feed) (*ro
 // openNoteFromCard 点击笔记卡片并等待笔记页面加载（同时基于加载耗时检测是否为真视频）
 func (b *BrowseAction) openNoteFromCard(page *rod.Page, selectedCard *rod.Element, feedID string, stats *BrowseStats) (bool, error) {
 	// 点击进入笔记
-	logrus.Info("步骤6: 点击笔记卡片")
+	b.log.Info("步骤6: 点击笔记卡片")
 	if err := selectedCard.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		logrus.Errorf("点击笔记失败: %v", err)
+		b.log.Errorf("点击笔记失败: %v", err)
 		return false, fmt.Errorf("点击笔记失败: %v", err)
 	}
-	logrus.Info("笔记点击成功")
+	b.log.Info("笔记点击成功")
 	stats.ClickCount++
 	stats.ViewedNotes = append(stats.ViewedNotes, feedID)
 	if stats.viewedSet != nil {
@@ -1639,14 +1635,14 @@ func (b *BrowseAction) openNoteFromCard(page *rod.Page, selectedCard *rod.Elemen
 	}
 
 	// 步骤7: 等待笔记页面加载，并基于加载耗时检测是否为真视频
-	logrus.Info("步骤7: 等待笔记页面加载")
+	b.log.Info("步骤7: 等待笔记页面加载")
 	stepStart := time.Now()
 
 	// 先进行一个短暂的固定等待，模拟首屏渲染
 	pause(400, 900)
 
 	maxWait := 4 * time.Second
-	logrus.Debug("开始等待DOM稳定（最多 4 秒用于区分视频/图文）")
+	b.log.Debug("开始等待DOM稳定（最多 4 秒用于区分视频/图文）")
 	waitStart := time.Now()
 
 	var isRealVideo bool
@@ -1659,15 +1655,15 @@ func (b *BrowseAction) openNoteFromCard(page *rod.Page, selectedCard *rod.Elemen
 	if err != nil {
 		// 在 4 秒内仍未完成 => 判定为真视频
 		isRealVideo = true
-		logrus.Infof("笔记页面在 %v 内未完全加载，判定为真视频笔记", elapsedWait)
+		b.log.Infof("笔记页面在 %v 内未完全加载，判定为真视频笔记", elapsedWait)
 	} else {
 		// 4 秒内加载完成 => 视为图文/动图
 		isRealVideo = false
-		logrus.Info("笔记页面加载完成")
-		logrus.Debugf("笔记页面加载耗时: %v", elapsedWait)
+		b.log.Info("笔记页面加载完成")
+		b.log.Debugf("笔记页面加载耗时: %v", elapsedWait)
 	}
 
-	logrus.Debugf("步骤7 总等待时间（含初始等待）: %v", time.Since(stepStart))
+	b.log.Debugf("步骤7 总等待时间（含初始等待）: %v", time.Since(stepStart))
 
 	return isRealVideo, nil
 }
@@ -1675,11 +1671,11 @@ func (b *BrowseAction) openNoteFromCard(page *rod.Page, selectedCard *rod.Elemen
 // getFeedsFromPage 从页面的 window.__INITIAL_STATE__ 获取笔记列表
 // 这与项目中其他 MCP 功能（feeds.go, search.go）的实现方式完全一致
 func (b *BrowseAction) getFeedsFromPage(page *rod.Page) ([]Feed, error) {
-	logrus.Debug("### 开始从页面获取笔记数据")
+	b.log.Debug("### 开始从页面获取笔记数据")
 
 	// 只提取我们需要的部分，避免循环引用问题
 	// 直接访问 feed.feeds._value，而不是序列化整个 __INITIAL_STATE__
-	result := page.MustEval(`() => {
+	resultObj, err := page.Eval(`() => {
 		if (window.__INITIAL_STATE__ &&
 		    window.__INITIAL_STATE__.feed &&
 		    window.__INITIAL_STATE__.feed.feeds &&
@@ -1694,37 +1690,41 @@ func (b *BrowseAction) getFeedsFromPage(page *rod.Page) ([]Feed, error) {
 			})));
 		}
 		return "";
-	}`).String()
+	}`)
+	if err != nil {
+		return nil, stepErr("读取推荐页笔记列表", "", err)
+	}
+	result := resultObj.Value.String()
 
 	if result == "" {
-		logrus.Error("### 未找到笔记数据")
+		b.log.Error("### 未找到笔记数据")
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
 	}
 
-	logrus.Debugf("### 获取到的数据长度: %d bytes", len(result))
+	b.log.Debugf("### 获取到的数据长度: %d bytes", len(result))
 
 	// 解析笔记列表
 	var feeds []Feed
 	if err := json.Unmarshal([]byte(result), &feeds); err != nil {
-		logrus.Errorf("### 解析笔记数据失败: %v", err)
+		b.log.Errorf("### 解析笔记数据失败: %v", err)
 		return nil, fmt.Errorf("failed to unmarshal feeds: %w", err)
 	}
 
-	logrus.Infof("### 成功解析 %d 条笔记数据", len(feeds))
+	b.log.Infof("### 成功解析 %d 条笔记数据", len(feeds))
 	return feeds, nil
 }
 
 // getVisibleNoteCards 获取当前可见的笔记卡片
 func (b *BrowseAction) getVisibleNoteCards(page *rod.Page) ([]*rod.Element, error) {
-	logrus.Debug("*** 开始查找笔记卡片")
+	b.log.Debug("*** 开始查找笔记卡片")
 
 	// 小红书的笔记卡片选择器
-	cards, err := page.Elements("section.note-item")
+	cards, err := findAllElements(page, SelectorNoteCard)
 	if err != nil {
-		logrus.Errorf("*** 查找笔记卡片失败: %v", err)
+		b.log.Errorf("*** 查找笔记卡片失败: %v", err)
 		return nil, err
 	}
-	logrus.Infof("*** 找到 %d 个笔记卡片（包括不可见的）", len(cards))
+	b.log.Infof("*** 找到 %d 个笔记卡片（包括不可见的）", len(cards))
 
 	visibleCards := make([]*rod.Element, 0)
 	for i, card := range cards {
@@ -1737,26 +1737,32 @@ func (b *BrowseAction) getVisibleNoteCards(page *rod.Page) ([]*rod.Element, erro
 				return rect.top >= 0 && rect.bottom <= window.innerHeight;
 			}`)
 			if inViewport != nil && inViewport.Value.Bool() {
-				logrus.Debugf("*** 卡片 %d 在视口内", i)
+				b.log.Debugf("*** 卡片 %d 在视口内", i)
 				visibleCards = append(visibleCards, card)
 			} else {
-				logrus.Debugf("*** 卡片 %d 不在视口内", i)
+				b.log.Debugf("*** 卡片 %d 不在视口内", i)
 			}
 		} else {
-			logrus.Debugf("*** 卡片 %d 不可见或检查失败: %v", i, err)
+			b.log.Debugf("*** 卡片 %d 不可见或检查失败: %v", i, err)
 		}
 	}
 
-	logrus.Infof("*** 找到 %d 个可见的笔记卡片", len(visibleCards))
+	b.log.Infof("*** 找到 %d 个可见的笔记卡片", len(visibleCards))
 	return visibleCards, nil
 }
 
 // extractNoteInfo 从笔记卡片提取信息
 func (b *BrowseAction) extractNoteInfo(card *rod.Element) (feedID, xsecToken string, err error) {
 	// 查找笔记链接
-	linkElem, err := card.Element("a.cover")
-	if err != nil {
-		return "", "", err
+	var linkElem *rod.Element
+	for _, sel := range selectorsFor(SelectorNoteCardLink) {
+		if has, el, _ := card.Has(sel); has {
+			linkElem = el
+			break
+		}
+	}
+	if linkElem == nil {
+		return "", "", fmt.Errorf("未找到笔记链接")
 	}
 
 	href, err := linkElem.Attribute("href")
@@ -1831,84 +1837,86 @@ func parseNoteURL(urlStr string) (feedID, xsecToken string) {
 
 // browseNoteContent 浏览笔记内容（视频与图文策略不同）
 func (b *BrowseAction) browseNoteContent(page *rod.Page, feedID string, isRealVideo bool) error {
-	logrus.Debug(">>> 开始浏览笔记内容")
+	b.log.Debug(">>> 开始浏览笔记内容")
 
 	// 模拟先看标题和文案
-	logrus.Debug(">>> 阅读标题和内容")
+	b.log.Debug(">>> 阅读标题和内容")
 	pause(1500, 3000)
 
 	if isRealVideo {
 		// 真视频：不做图片滚动，采用“完整观看/部分观看”策略
 		if rand.Intn(100) < 10 {
-			logrus.Info(">>> 当前笔记为真视频，本次选择完整观看视频，等待视频播放完成")
+			b.log.Info(">>> 当前笔记为真视频，本次选择完整观看视频，等待视频播放完成")
 			_ = rod.Try(func() {
 				page.Timeout(2 * time.Second).MustWaitDOMStable()
 			})
 			watchDuration := randomDuration(12000, 30000)
-			logrus.Infof(">>> 视频完整观看结束，继续停留约 %.1f 秒", watchDuration.Seconds())
+			b.log.Infof(">>> 视频完整观看结束，继续停留约 %.1f 秒", watchDuration.Seconds())
 			time.Sleep(watchDuration)
 		} else {
 			// 90% 概率部分观看：停留 4-9 秒后进入后续操作
 			watchDuration := randomDuration(4000, 9000)
-			logrus.Infof(">>> 当前笔记为真视频，本次选择部分观看，停留约 %d 秒后进入后续操作", int(watchDuration.Seconds()))
+			b.log.Infof(">>> 当前笔记为真视频，本次选择部分观看，停留约 %d 秒后进入后续操作", int(watchDuration.Seconds()))
 			time.Sleep(watchDuration)
 		}
 	} else {
 		// 图文/动图：先浏览图片，再轻微滚动正文
-		logrus.Info(">>> 当前笔记为图文/动图，准备浏览图片和正文内容")
+		b.log.Info(">>> 当前笔记为图文/动图，准备浏览图片和正文内容")
 
 		imageCount, err := b.getNoteImageCount(page, feedID)
 		if err != nil {
-			logrus.Warnf(">>> 获取图片数量失败（可忽略）: %v", err)
+			b.log.Warnf(">>> 获取图片数量失败（可忽略）: %v", err)
 		} else if imageCount > 0 {
-			logrus.Infof(">>> 检测到该笔记共有 %d 张图片", imageCount)
+			b.log.Infof(">>> 检测到该笔记共有 %d 张图片", imageCount)
 
 			// 80% 概率执行图片轮播浏览
 			if rand.Intn(100) < 80 {
-				logrus.Info(">>> 开始浏览轮播图片")
+				b.log.Info(">>> 开始浏览轮播图片")
 				if err := b.browseNoteImages(page, imageCount); err != nil {
-					logrus.Warnf(">>> 浏览轮播图片失败: %v", err)
+					b.log.Warnf(">>> 浏览轮播图片失败: %v", err)
 				}
 			}
 		} else {
-			logrus.Debug(">>> 未检测到图片列表，跳过图片浏览")
+			b.log.Debug(">>> 未检测到图片列表，跳过图片浏览")
 		}
 
 		// 最后保留 1-2 次正文滚动，模拟浏览文案
 		scrollTimes := rand.Intn(2) + 1
-		logrus.Debugf(">>> 准备滚动 %d 次查看正文内容", scrollTimes)
+		b.log.Debugf(">>> 准备滚动 %d 次查看正文内容", scrollTimes)
 		for i := 0; i < scrollTimes; i++ {
-			page.Mouse.MustScroll(0, float64(rand.Intn(250)+150))
+			if err := page.Mouse.Scroll(0, float64(rand.Intn(250)+150), 0); err != nil {
+				return stepErr("滚动笔记正文", "", err)
+			}
 			pause(500, 1100)
 		}
-		logrus.Debug(">>> 正文内容滚动完成")
+		b.log.Debug(">>> 正文内容滚动完成")
 	}
 
 	// 智能浏览评论区（无论视频还是图文，都有概率滚动评论区）
 	if rand.Intn(100) < 70 { // 70% 概率浏览评论区
-		logrus.Debug(">>> 准备浏览评论区")
+		b.log.Debug(">>> 准备浏览评论区")
 		if err := b.scrollCommentArea(page); err != nil {
-			logrus.Warnf(">>> 滚动评论区失败: %v", err)
+			b.log.Warnf(">>> 滚动评论区失败: %v", err)
 		} else {
-			logrus.Debug(">>> 评论区浏览完成")
+			b.log.Debug(">>> 评论区浏览完成")
 		}
 	} else {
-		logrus.Debug(">>> 跳过评论区浏览")
+		b.log.Debug(">>> 跳过评论区浏览")
 	}
 
-	logrus.Info(">>> 笔记内容浏览完毕")
+	b.log.Info(">>> 笔记内容浏览完毕")
 	return nil
 }
 
 // browseNoteImages 基于轮播组件结构模拟依次查看图片，通过点击轮播箭头切换
 func (b *BrowseAction) browseNoteImages(page *rod.Page, imageCount int) error {
 	if imageCount <= 1 {
-		logrus.Debug(">>> 仅检测到 1 张图片，停留浏览")
+		b.log.Debug(">>> 仅检测到 1 张图片，停留浏览")
 		pause(1000, 2200)
 		return nil
 	}
 
-	logrus.Debugf(">>> 开始浏览轮播图片，总数=%d", imageCount)
+	b.log.Debugf(">>> 开始浏览轮播图片，总数=%d", imageCount)
 
 	// 绝大多数情况下完整浏览所有图片，少数情况只看前几张，避免过于规律
 	total := imageCount
@@ -1917,18 +1925,13 @@ func (b *BrowseAction) browseNoteImages(page *rod.Page, imageCount int) error {
 	}
 
 	var sliderArea *rod.Element
-	_ = rod.Try(func() {
-		sliderArea = page.Timeout(2 * time.Second).MustElement(".slider-container .note-slider")
-	})
-	if sliderArea == nil {
+	for _, sel := range selectorsFor(SelectorImageSlider) {
 		_ = rod.Try(func() {
-			sliderArea = page.Timeout(2 * time.Second).MustElement(".note-slider")
-		})
-	}
-	if sliderArea == nil {
-		_ = rod.Try(func() {
-			sliderArea = page.Timeout(2 * time.Second).MustElement(".slider-container")
+			sliderArea = page.Timeout(2 * time.Second).MustElement(sel)
 		})
+		if sliderArea != nil {
+			break
+		}
 	}
 	if sliderArea != nil {
 		_ = rod.Try(func() {
@@ -1938,17 +1941,16 @@ func (b *BrowseAction) browseNoteImages(page *rod.Page, imageCount int) error {
 
 	// 尝试找到轮播图右侧的箭头按钮（显式超时，避免 Element 默认等待太久导致卡死）
 	var rightArrow *rod.Element
-	var err error
-	err = rod.Try(func() {
-		rightArrow = page.Timeout(2 * time.Second).MustElement(".slider-container .arrow-controller.right")
-	})
-	if err != nil || rightArrow == nil {
+	for _, sel := range selectorsFor(SelectorImageSliderNext) {
 		_ = rod.Try(func() {
-			rightArrow = page.Timeout(2 * time.Second).MustElement(".arrow-controller.right")
+			rightArrow = page.Timeout(2 * time.Second).MustElement(sel)
 		})
+		if rightArrow != nil {
+			break
+		}
 	}
 	if rightArrow == nil {
-		logrus.Warn(">>> 未找到轮播图右侧箭头（已快速超时），改为停留等待浏览")
+		b.log.Warn(">>> 未找到轮播图右侧箭头（已快速超时），改为停留等待浏览")
 		pause(1000, 2200)
 		return nil
 	}
@@ -1965,13 +1967,15 @@ func (b *BrowseAction) browseNoteImages(page *rod.Page, imageCount int) error {
 				sliderArea.Timeout(1 * time.Second).MustHover()
 			}); err == nil {
 				scrollAmount := rand.Intn(180) + 120
-				page.Mouse.MustScroll(0, float64(scrollAmount))
+				if err := page.Mouse.Scroll(0, float64(scrollAmount), 0); err != nil {
+					return stepErr("滚动轮播图", "", err)
+				}
 				continue
 			}
 		}
 
 		if err := rightArrow.Timeout(2*time.Second).Click(proto.InputMouseButtonLeft, 1); err != nil {
-			logrus.Warnf(">>> 点击轮播图下一张失败，提前结束轮播浏览: %v", err)
+			b.log.Warnf(">>> 点击轮播图下一张失败，提前结束轮播浏览: %v", err)
 			break
 		}
 	}
@@ -1981,10 +1985,9 @@ func (b *BrowseAction) browseNoteImages(page *rod.Page, imageCount int) error {
 
 // getNoteImageCount 仅通过笔记详情中的轮播组件 DOM 结构获取当前笔记的图片张数
 func (b *BrowseAction) getNoteImageCount(page *rod.Page, _ string) (int, error) {
-	count := page.MustEval(`() => {
+	countObj, err := page.Eval(`(sliderSelectors) => {
 		try {
-			const sliderRoot = document.querySelector('.slider-container .note-slider') ||
-					document.querySelector('.note-slider');
+			const sliderRoot = sliderSelectors.map((s) => document.querySelector(s)).find(Boolean);
 			if (!sliderRoot) return 0;
 
 			// 1. 优先从 fraction 文本中解析总张数，例如 "5/6" => 6
@@ -2028,7 +2031,11 @@ func (b *BrowseAction) getNoteImageCount(page *rod.Page, _ string) (int, error)
 		} catch (e) {
 			return 0;
 		}
-	}`).Int()
+	}`, selectorsFor(SelectorImageSlider))
+	if err != nil {
+		return 0, stepErr("统计笔记图片数量", "", err)
+	}
+	count := countObj.Value.Int()
 
 	if count == 0 {
 		return 0, fmt.Errorf("未在 DOM 中发现轮播图组件或有效的图片")
@@ -2038,7 +2045,7 @@ func (b *BrowseAction) getNoteImageCount(page *rod.Page, _ string) (int, error)
 
 // isCommentAreaVisible 检查评论区是否在视口中可见
 func (b *BrowseAction) isCommentAreaVisible(page *rod.Page) (bool, error) {
-	isVisible := page.MustEval(`() => {
+	isVisibleObj, err := page.Eval(`(modalSelectors) => {
 		// 尝试多种评论区选择器
 		const commentSelectors = [
 			'.comment-container',
@@ -2055,9 +2062,7 @@ func (b *BrowseAction) isCommentAreaVisible(page *rod.Page) (bool, error) {
 
 				// 检查元素是否在视口中
 				// 考虑到小红书的弹窗结构，我们需要检查相对于弹窗容器的位置
-				const modal = document.querySelector('.note-detail-modal') ||
-				              document.querySelector('.modal') ||
-				              document.querySelector('[class*="detail"]');
+				const modal = modalSelectors.map((s) => document.querySelector(s)).find(Boolean);
 
 				if (modal) {
 					const modalRect = modal.getBoundingClientRect();
@@ -2083,7 +2088,11 @@ func (b *BrowseAction) isCommentAreaVisible(page *rod.Page) (bool, error) {
 		}
 
 		return false;
-	}`).Bool()
+	}`, selectorsFor(SelectorNoteModal))
+	if err != nil {
+		return false, stepErr("检查评论区可见性", "", err)
+	}
+	isVisible := isVisibleObj.Value.Bool()
 
 	return isVisible, nil
 }
@@ -2091,24 +2100,24 @@ func (b *BrowseAction) isCommentAreaVisible(page *rod.Page) (bool, error) {
 // scrollCommentArea 智能滚动评论区
 // 自动检测评论区是否有评论，以及是否到达底部
 func (b *BrowseAction) scrollCommentArea(page *rod.Page) error {
-	logrus.Debug("开始浏览评论区")
+	b.log.Debug("开始浏览评论区")
 
 	// 检查评论区是否有评论
 	hasComments, err := b.hasComments(page)
 	if err != nil {
-		logrus.Warnf("检查评论区失败: %v", err)
+		b.log.Warnf("检查评论区失败: %v", err)
 		return fmt.Errorf("检查评论区失败: %v", err)
 	}
 
 	if !hasComments {
-		logrus.Debug("评论区没有评论，跳过滚动")
+		b.log.Debug("评论区没有评论，跳过滚动")
 		return nil
 	}
 
 	// 检查评论区是否在视口中可见
 	commentVisible, err := b.isCommentAreaVisible(page)
 	if err != nil {
-		logrus.Warnf("检查评论区可见性失败: %v", err)
+		b.log.Warnf("检查评论区可见性失败: %v", err)
 		// 如果无法检查可见性，继续执行原有逻辑
 		commentVisible = false
 	}
@@ -2117,10 +2126,10 @@ func (b *BrowseAction) scrollCommentArea(page *rod.Page) error {
 	if !commentVisible {
 		b.scrollCommentAreaIntoView(page)
 	} else {
-		logrus.Debug("评论区已在视口中可见")
+		b.log.Debug("评论区已在视口中可见")
 	}
 
-	logrus.Debug("评论区有评论，开始滚动")
+	b.log.Debug("评论区有评论，开始滚动")
 
 	b.performCommentScrolling(page)
 
@@ -2129,10 +2138,10 @@ func (b *BrowseAction) scrollCommentArea(page *rod.Page) error {
 
 // scrollCommentAreaIntoView 将评论区滚动到视口中，包含无法精确定位时的降级逻辑
 func (b *BrowseAction) scrollCommentAreaIntoView(page *rod.Page) {
-	logrus.Info("评论区不在视口中，先滚动到评论区位置")
+	b.log.Info("评论区不在视口中，先滚动到评论区位置")
 
 	// 尝试找到评论区并滚动到其位置
-	scrolledToComment := page.MustEval(`() => {
+	scrolledToCommentObj, err := page.Eval(`() => {
 		// 尝试多种评论区选择器
 		const commentSelectors = [
 			'.comment-container',
@@ -2153,15 +2162,23 @@ func (b *BrowseAction) scrollCommentAreaIntoView(page *rod.Page) {
 		// 如果找不到评论区，尝试向下滚动一定距离
 		window.scrollBy({ top: 400, behavior: 'smooth' });
 		return false;
-	}`).Bool()
+	}`)
+	scrolledToComment := false
+	if err != nil {
+		b.log.Warnf("定位评论区失败: %v", err)
+	} else {
+		scrolledToComment = scrolledToCommentObj.Value.Bool()
+	}
 
 	// 等待滚动完成
 	pause(800, 1500)
 
 	if !scrolledToComment {
-		logrus.Warn("无法精确定位评论区，使用通用滚动")
+		b.log.Warn("无法精确定位评论区，使用通用滚动")
 		// 降级方案：通用滚动
-		page.Mouse.MustScroll(0, float64(rand.Intn(400)+300))
+		if err := page.Mouse.Scroll(0, float64(rand.Intn(400)+300), 0); err != nil {
+			b.log.Warnf("通用滚动失败: %v", err)
+		}
 		pause(700, 1500)
 	}
 }
@@ -2174,31 +2191,36 @@ func (b *BrowseAction) performCommentScrolling(page *rod.Page) {
 		// 获取滚动前的位置
 		beforeScroll, err := b.getScrollPosition(page)
 		if err != nil {
-			logrus.Warnf("获取滚动位置失败: %v", err)
+			b.log.Warnf("获取滚动位置失败: %v", err)
 			break
 		}
 
 		// 执行滚动
 		scrollAmount := rand.Intn(300) + 200 // 200-500像素
-		page.Mouse.MustScroll(0, float64(scrollAmount))
+		if err := page.Mouse.Scroll(0, float64(scrollAmount), 0); err != nil {
+			b.log.Warnf("滚动评论区失败: %v", err)
+			break
+		}
 		pause(700, 2000)
 
 		// 获取滚动后的位置
 		afterScroll, err := b.getScrollPosition(page)
 		if err != nil {
-			logrus.Warnf("获取滚动位置失败: %v", err)
+			b.log.Warnf("获取滚动位置失败: %v", err)
 			break
 		}
 
 		// 检查是否已经到底部（滚动位置几乎没有变化）
 		if afterScroll-beforeScroll < 50 { // 如果滚动距离小于50像素，认为到底了
-			logrus.Info("评论区已滚动到底部")
+			b.log.Info("评论区已滚动到底部")
 
 			// 有70%概率回滚一下
 			if rand.Intn(100) < 70 {
-				logrus.Info("回滚评论区")
+				b.log.Info("回滚评论区")
 				backAmount := rand.Intn(300) + 200 // 回滚200-500像素
-				page.Mouse.MustScroll(0, float64(-backAmount))
+				if err := page.Mouse.Scroll(0, float64(-backAmount), 0); err != nil {
+					b.log.Warnf("回滚评论区失败: %v", err)
+				}
 				pause(500, 1000)
 			}
 			break
@@ -2206,30 +2228,19 @@ func (b *BrowseAction) performCommentScrolling(page *rod.Page) {
 	}
 }
 
-// 评论区相关选择器集中定义，便于统一维护
-var (
-	// commentItemSelectors 用于检测评论区是否有评论（元素层面）
-	commentItemSelectors = []string{
-		".comment-item",                     // 评论项
-		".comments-container .comment-item", // 评论容器内的评论项
-		"[class*='comment-item']",           // 包含comment-item的class
-		".comment-list .item",               // 评论列表项
-	}
-)
-
 // hasComments 检查评论区是否有评论
 func (b *BrowseAction) hasComments(page *rod.Page) (bool, error) {
-	// 使用统一定义的评论项选择器集合
-	for _, selector := range commentItemSelectors {
+	// 使用选择器表中的评论项选择器
+	for _, selector := range selectorsFor(SelectorCommentItem) {
 		elements, err := page.Elements(selector)
 		if err == nil && len(elements) > 0 {
-			logrus.Debugf("通过选择器 %s 找到 %d 条评论", selector, len(elements))
+			b.log.Debugf("通过选择器 %s 找到 %d 条评论", selector, len(elements))
 			return true, nil
 		}
 	}
 
 	// 尝试通过JavaScript检查
-	hasComments := page.MustEval(`() => {
+	hasCommentsObj, err := page.Eval(`() => {
 		const commentContainers = document.querySelectorAll('[class*="comment"]');
 		for (let container of commentContainers) {
 			if (container.innerText && container.innerText.trim().length > 10) {
@@ -2237,12 +2248,16 @@ func (b *BrowseAction) hasComments(page *rod.Page) (bool, error) {
 			}
 		}
 		return false;
-	}`).Bool()
+	}`)
+	if err != nil {
+		return false, stepErr("检查评论", "", err)
+	}
+	hasComments := hasCommentsObj.Value.Bool()
 
 	if hasComments {
-		logrus.Info("通过JavaScript检测到评论")
+		b.log.Info("通过JavaScript检测到评论")
 	} else {
-		logrus.Info("未检测到任何评论")
+		b.log.Info("未检测到任何评论")
 	}
 
 	return hasComments, nil
@@ -2250,17 +2265,19 @@ func (b *BrowseAction) hasComments(page *rod.Page) (bool, error) {
 
 // getScrollPosition 获取当前滚动位置
 func (b *BrowseAction) getScrollPosition(page *rod.Page) (float64, error) {
-	position := page.MustEval(`() => {
+	positionObj, err := page.Eval(`(modalSelectors) => {
 		// 尝试获取笔记详情弹窗内的滚动位置
-		const modal = document.querySelector('.note-detail-modal') ||
-		              document.querySelector('.modal') ||
-		              document.querySelector('[class*="detail"]');
+		const modal = modalSelectors.map((s) => document.querySelector(s)).find(Boolean);
 		if (modal) {
 			const scrollContainer = modal.querySelector('[class*="scroll"]') || modal;
 			return scrollContainer.scrollTop || window.pageYOffset || document.documentElement.scrollTop;
 		}
 		return window.pageYOffset || document.documentElement.scrollTop;
-	}`).Num()
+	}`, selectorsFor(SelectorNoteModal))
+	if err != nil {
+		return 0, stepErr("获取滚动位置", "", err)
+	}
+	position := positionObj.Value.Num()
 
 	return position, nil
 }
@@ -2269,51 +2286,48 @@ func (b *BrowseAction) getScrollPosition(page *rod.Page) (float64, error) {
 // 小红书的笔记详情是悬浮在推荐页上的弹窗，不是新页面
 // 真实用户会使用 ESC 键或点击空白处（遮罩层）来关闭
 func (b *BrowseAction) closeNoteModal(page *rod.Page) error {
-	logrus.Info("<<< 准备关闭笔记弹窗")
+	b.log.Info("<<< 准备关闭笔记弹窗")
 
 	// 随机选择关闭方式，模拟真实用户习惯
 	closeMethod := rand.Intn(10)
 
 	if closeMethod < 6 { // 60% 概率使用 ESC 键
-		logrus.Info("<<< 使用 ESC 键关闭笔记")
-		page.MustElement("body").MustKeyActions().Press(input.Escape).MustDo()
+		b.log.Info("<<< 使用 ESC 键关闭笔记")
+		if err := pressPageKey(page, input.Escape); err != nil {
+			return stepErr("按 ESC 关闭笔记弹窗", "body", err)
+		}
 		time.Sleep(randomDuration(300, 600))
-		logrus.Info("<<< ESC 键已按下")
+		b.log.Info("<<< ESC 键已按下")
 		return nil
 	}
 
 	// 40% 概率点击遮罩层（空白处）
-	logrus.Info("<<< 尝试点击遮罩层关闭笔记")
+	b.log.Info("<<< 尝试点击遮罩层关闭笔记")
 
 	// 尝试多种可能的遮罩层选择器
-	maskSelectors := []string{
-		"div.close",             // 关闭按钮
-		"div[class*='mask']",    // 遮罩层
-		"div[class*='overlay']", // 覆盖层
-		".note-detail-mask",     // 笔记详情遮罩
-	}
-
-	for _, selector := range maskSelectors {
-		logrus.Debugf("<<< 尝试选择器: %s", selector)
+	for _, selector := range selectorsFor(SelectorNoteModalClose) {
+		b.log.Debugf("<<< 尝试选择器: %s", selector)
 		if mask, err := page.Element(selector); err == nil {
 			if visible, _ := mask.Visible(); visible {
-				logrus.Infof("<<< 找到可见的遮罩层: %s", selector)
+				b.log.Infof("<<< 找到可见的遮罩层: %s", selector)
 				if err := mask.Click(proto.InputMouseButtonLeft, 1); err == nil {
 					time.Sleep(randomDuration(300, 600))
-					logrus.Info("<<< 遮罩层点击成功")
+					b.log.Info("<<< 遮罩层点击成功")
 					return nil
 				} else {
-					logrus.Warnf("<<< 遮罩层点击失败: %v", err)
+					b.log.Warnf("<<< 遮罩层点击失败: %v", err)
 				}
 			}
 		}
 	}
 
 	// 如果找不到遮罩层，使用 ESC 作为降级方案
-	logrus.Info("<<< 未找到遮罩层，使用 ESC 键作为降级方案")
-	page.MustElement("body").MustKeyActions().Press(input.Escape).MustDo()
+	b.log.Info("<<< 未找到遮罩层，使用 ESC 键作为降级方案")
+	if err := pressPageKey(page, input.Escape); err != nil {
+		return stepErr("按 ESC 关闭笔记弹窗", "body", err)
+	}
 	time.Sleep(randomDuration(300, 600))
-	logrus.Info("<<< ESC 键已按下（降级方案）")
+	b.log.Info("<<< ESC 键已按下（降级方案）")
 
 	return nil
 }
@@ -2325,24 +2339,14 @@ func (b *BrowseAction) likeInModal(page *rod.Page) error {
 		return err
 	}
 
-	// 使用与详情页相同的选择器，但不跳转页面
-	// 选择器来自 like_favorite.go 中的 SelectorLikeButton
-	selector := ".interact-container .left .like-lottie"
-
-	// 尝试多个可能的点赞按钮选择器（弹窗内可能略有不同）
-	selectors := []string{
-		selector,          // 详情页选择器
-		".like-lottie",    // 简化选择器
-		"[class*='like']", // 包含like的class（已限定在弹窗容器内）
-	}
-
-	for _, sel := range selectors {
+	// 尝试多个可能的点赞按钮选择器（弹窗内可能略有不同，已限定在弹窗容器内）
+	for _, sel := range selectorsFor(SelectorModalLike) {
 		if elem, err := modal.Element(sel); err == nil {
 			if visible, _ := elem.Visible(); visible {
 				if isPressedLikeOrCollect(elem) {
 					return nil
 				}
-				logrus.Debugf("使用选择器点赞: %s", sel)
+				b.log.Debugf("使用选择器点赞: %s", sel)
 				if err := elem.Timeout(2*time.Second).Click(proto.InputMouseButtonLeft, 1); err == nil {
 					return nil
 				}
@@ -2360,24 +2364,14 @@ func (b *BrowseAction) favoriteInModal(page *rod.Page) error {
 		return err
 	}
 
-	// 使用与详情页相同的选择器，但不跳转页面
-	// 选择器来自 like_favorite.go 中的 SelectorCollectButton
-	selector := ".interact-container .left .reds-icon.collect-icon"
-
-	// 尝试多个可能的收藏按钮选择器（弹窗内可能略有不同）
-	selectors := []string{
-		selector,             // 详情页选择器
-		".collect-icon",      // 简化选择器
-		"[class*='collect']", // 包含collect的class（已限定在弹窗容器内）
-	}
-
-	for _, sel := range selectors {
+	// 尝试多个可能的收藏按钮选择器（弹窗内可能略有不同，已限定在弹窗容器内）
+	for _, sel := range selectorsFor(SelectorModalCollect) {
 		if elem, err := modal.Element(sel); err == nil {
 			if visible, _ := elem.Visible(); visible {
 				if isPressedLikeOrCollect(elem) {
 					return nil
 				}
-				logrus.Debugf("使用选择器收藏: %s", sel)
+				b.log.Debugf("使用选择器收藏: %s", sel)
 				if err := elem.Timeout(2*time.Second).Click(proto.InputMouseButtonLeft, 1); err == nil {
 					return nil
 				}
@@ -2397,12 +2391,32 @@ func pause(minMs, maxMs int) {
 	time.Sleep(randomDuration(minMs, maxMs))
 }
 
+// pressPageKey 在页面 body 上按下并释放一个按键
+func pressPageKey(page *rod.Page, key input.Key) error {
+	body, err := page.Element("body")
+	if err != nil {
+		return err
+	}
+	return doKeyActions(body, func(ka *rod.KeyActions) *rod.KeyActions {
+		return ka.Press(key)
+	})
+}
+
+// navigateExplore 打开推荐页，导航使用独立的 60 秒超时，避免单次导航卡住整个浏览任务
+func navigateExplore(ctx context.Context, page *rod.Page, step string) error {
+	navCtx, navCancel := context.WithTimeout(ctx, 60*time.Second)
+	defer navCancel()
+
+	return navigate(page.Context(navCtx), step, siteURL("/explore"))
+}
+
 func waitForExploreReady(page *rod.Page, timeout time.Duration) {
 	end := time.Now().Add(timeout)
 	for time.Now().Before(end) {
 		ok := false
 		_ = rod.Try(func() {
-			ok = page.Timeout(800 * time.Millisecond).MustEval(`() => document.querySelectorAll('section.note-item').length > 0`).Bool()
+			ok = page.Timeout(800 * time.Millisecond).MustEval(`(cardSelectors) => cardSelectors.some((s) => document.querySelectorAll(s).length > 0)`,
+				selectorsFor(SelectorNoteCard)).Bool()
 		})
 		if ok {
 			return
@@ -2416,8 +2430,8 @@ func waitForNoteModalClosed(page *rod.Page, timeout time.Duration) {
 	for time.Now().Before(end) {
 		closed := true
 		_ = rod.Try(func() {
-			closed = page.Timeout(800 * time.Millisecond).MustEval(`() => {
-				const m = document.querySelector('.note-detail-modal') || document.querySelector('.modal') || document.querySelector('[class*="detail"]');
+			closed = page.Timeout(800 * time.Millisecond).MustEval(`(modalSelectors) => {
+				const m = modalSelectors.map((s) => document.querySelector(s)).find(Boolean);
 				if (!m) return true;
 				const rect = m.getBoundingClientRect();
 				if (!rect || rect.width <= 0 || rect.height <= 0) return true;
@@ -2425,7 +2439,7 @@ func waitForNoteModalClosed(page *rod.Page, timeout time.Duration) {
 				if (!s) return false;
 				if (s.display === 'none' || s.visibility === 'hidden' || parseFloat(s.opacity || '1') === 0) return true;
 				return false;
-			}`).Bool()
+			}`, selectorsFor(SelectorNoteModal)).Bool()
 		})
 		if closed {
 			return
@@ -5,7 +5,8 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
 )
 
 // CommentFeedAction 表示 Feed 评论动作
@@ -19,30 +20,43 @@ func NewCommentFeedAction(page *rod.Page) *CommentFeedAction {
 }
 
 // PostComment 发表评论到 Feed
-func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content string) error {
-	page := f.page.Context(ctx).Timeout(60 * time.Second)
+func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content string) (err error) {
+	defer recoverAction(&err, "发表评论")
+
+	page := f.page.Context(ctx).Timeout(configs.Get().Timeouts.Action)
 
 	// 构建详情页 URL
 	url := makeFeedDetailURL(feedID, xsecToken)
 
-	logrus.Infof("Opening feed detail page: %s", url)
+	logging.From(ctx).Infof("Opening feed detail page: %s", url)
 
 	// 导航到详情页
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
+	if err := page.Navigate(url); err != nil {
+		return stepErr("打开笔记详情页", "", err)
+	}
+	if err := waitDOMStable(page, "等待笔记详情页加载"); err != nil {
+		return err
+	}
 
 	time.Sleep(1 * time.Second)
 
-	elem := page.MustElement("div.input-box div.content-edit span")
-	elem.MustClick()
+	if err := requireLogin(page, "打开笔记详情页"); err != nil {
+		return err
+	}
+
+	if err := clickElement(page, "点击评论输入框", SelectorCommentTrigger); err != nil {
+		return err
+	}
 
-	elem2 := page.MustElement("div.input-box div.content-edit p.content-input")
-	elem2.MustInput(content)
+	if err := inputElement(page, "输入评论内容", SelectorCommentInput, content); err != nil {
+		return err
+	}
 
 	time.Sleep(1 * time.Second)
 
-	submitButton := page.MustElement("div.bottom button.submit")
-	submitButton.MustClick()
+	if err := clickElement(page, "点击发送评论", SelectorCommentSubmit); err != nil {
+		return err
+	}
 
 	time.Sleep(1 * time.Second)
 
@@ -0,0 +1,33 @@
+package xiaohongshu
+
+import "context"
+
+// PublishPreview 点击发布前的最终内容，用于请求用户确认
+type PublishPreview struct {
+	Title   string
+	Content string
+	Tags    []string
+	Images  []string // 图文的本地图片路径，网络图片为下载后的路径
+	Video   string   // 视频的本地路径
+}
+
+// ConfirmFunc 请求用户确认发布，返回错误时放弃发布。等待确认期间发布页保持打开
+type ConfirmFunc func(ctx context.Context, preview PublishPreview) error
+
+type confirmKey struct{}
+
+// WithConfirm 返回携带确认回调的 ctx，发布操作在上传完成、填写内容之前通过 ConfirmPublish 请求确认
+func WithConfirm(ctx context.Context, fn ConfirmFunc) context.Context {
+	return context.WithValue(ctx, confirmKey{}, fn)
+}
+
+// ConfirmPublish 请求用户确认发布，ctx 中没有回调时直接通过
+func ConfirmPublish(ctx context.Context, preview PublishPreview) error {
+	fn, ok := ctx.Value(confirmKey{}).(ConfirmFunc)
+	if !ok {
+		return nil
+	}
+
+	ReportProgress(ctx, Progress{Stage: StageConfirm, Message: "等待用户确认发布"})
+	return fn(ctx, preview)
+}
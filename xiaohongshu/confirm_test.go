@@ -0,0 +1,26 @@
+package xiaohongshu
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmPublish(t *testing.T) {
+	preview := PublishPreview{Title: "今日穿搭", Tags: []string{"穿搭"}, Images: []string{"/tmp/1.jpg"}}
+	assert.NoError(t, ConfirmPublish(context.Background(), preview), "没有回调时直接通过")
+
+	var stages []string
+	var got PublishPreview
+	ctx := WithProgress(context.Background(), func(p Progress) { stages = append(stages, p.Stage) })
+	ctx = WithConfirm(ctx, func(_ context.Context, p PublishPreview) error {
+		got = p
+		return errors.Wrap(ErrNotConfirmed, "用户拒绝")
+	})
+
+	assert.ErrorIs(t, ConfirmPublish(ctx, preview), ErrNotConfirmed)
+	assert.Equal(t, preview, got)
+	assert.Equal(t, []string{StageConfirm}, stages)
+}
@@ -0,0 +1,128 @@
+package xiaohongshu
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+)
+
+// 可区分的业务错误。动作返回的错误通过 errors.Is 与下列错误匹配，
+// 上层据此决定重新登录、重试还是放弃。
+var (
+	ErrNotLoggedIn      = errors.New("未登录或登录已失效")
+	ErrNoteNotFound     = errors.New("笔记不存在或已被删除")
+	ErrXsecTokenExpired = errors.New("xsec_token 已失效")
+	ErrSelectorMissing  = errors.New("页面元素缺失，页面结构可能已变更")
+	ErrUploadTimeout    = errors.New("上传超时")
+	ErrRiskControl      = errors.New("触发验证码或风控")
+	ErrContentRejected  = errors.New("内容被平台拒绝")
+	ErrNotConfirmed     = errors.New("发布未获用户确认")
+)
+
+// riskControlURLParts 风控验证页的 URL 特征
+var riskControlURLParts = []string{"/website-login/captcha", "/captcha", "verifyType=", "/web-login/verify"}
+
+// loginURLParts 被重定向到登录页时的 URL 特征（创作者中心登录页见 matchPageURL）
+var loginURLParts = []string{"/website-login"}
+
+// xsecExpiredTexts 笔记 404 页面中表示 xsec_token 失效的提示
+var xsecExpiredTexts = []string{"链接已失效", "链接已过期", "访问链接无效", "安全限制"}
+
+// diagnosePage 根据页面 URL 判断是否被重定向到风控验证页、登录页或笔记 404 页，
+// 识别出异常时返回对应的业务错误，否则返回 nil。
+// 只看 URL 与 404 页的提示，不扫描正文，避免笔记内容中的关键字造成误判
+func diagnosePage(page *rod.Page, step string) error {
+	info, err := page.Info()
+	if err != nil {
+		return nil // 无法获取页面信息时不做判断，交由后续步骤报错
+	}
+
+	if problem := matchPageURL(info.URL); problem != nil {
+		pageLog(page).Warnf("%s: 检测到异常页面 %s: %v", step, info.URL, problem)
+		traceStep(page, "检查页面 "+info.URL, "", problem)
+		return errors.Wrap(problem, step)
+	}
+	return nil
+}
+
+// requireLogin 在 diagnosePage 的基础上检查是否弹出了登录框，用于点赞、评论等需要登录的操作
+func requireLogin(page *rod.Page, step string) error {
+	if err := diagnosePage(page, step); err != nil {
+		return err
+	}
+	if visible, _ := isLoginModalVisible(page); visible {
+		return errors.Wrap(ErrNotLoggedIn, step)
+	}
+	return nil
+}
+
+// matchPageURL 根据 URL 匹配异常类型
+func matchPageURL(rawURL string) error {
+	for _, part := range riskControlURLParts {
+		if strings.Contains(rawURL, part) {
+			return ErrRiskControl
+		}
+	}
+	for _, part := range loginURLParts {
+		if strings.Contains(rawURL, part) {
+			return ErrNotLoggedIn
+		}
+	}
+	if strings.HasPrefix(rawURL, creatorURL("/login")) {
+		return ErrNotLoggedIn
+	}
+
+	// 笔记失效时会被重定向到 /404?error_code=...&error_msg=...
+	if u, err := url.Parse(rawURL); err == nil && u.Path == "/404" {
+		decoded, err := url.QueryUnescape(rawURL)
+		if err != nil {
+			decoded = rawURL
+		}
+		for _, t := range xsecExpiredTexts {
+			if strings.Contains(decoded, t) {
+				return ErrXsecTokenExpired
+			}
+		}
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+// isLoginModalVisible 判断页面上是否弹出了登录框（未登录访问详情页、评论等会弹出）
+func isLoginModalVisible(page *rod.Page) (bool, error) {
+	res, err := page.Eval(`() => {
+		const el = document.querySelector('.login-container');
+		if (!el) return false;
+		const rect = el.getBoundingClientRect();
+		return rect.width > 0 && rect.height > 0;
+	}`)
+	if err != nil {
+		return false, err
+	}
+	return res.Value.Bool(), nil
+}
+
+// rejectedToastTexts 发布后提示框中表示内容被拒绝的关键字
+var rejectedToastTexts = []string{"违规", "审核不通过", "不符合社区规范", "涉嫌", "无法发布", "发布失败"}
+
+// checkPublishRejected 发布提交后检查页面提示框，内容被平台拒绝时返回 ErrContentRejected。
+// 只读取提示框文本，避免正文中的关键字造成误判
+func checkPublishRejected(page *rod.Page) error {
+	res, err := page.Eval(`() => {
+		const nodes = document.querySelectorAll('.d-toast, [class*="toast"], [class*="message-content"], .d-modal-content');
+		return Array.from(nodes).map(n => (n.innerText || "").trim()).filter(Boolean).join("\n");
+	}`)
+	if err != nil {
+		return nil
+	}
+
+	text := res.Value.String()
+	for _, t := range rejectedToastTexts {
+		if strings.Contains(text, t) {
+			return errors.Wrapf(ErrContentRejected, "平台提示: %s", strings.TrimSpace(text))
+		}
+	}
+	return nil
+}
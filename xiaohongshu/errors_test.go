@@ -0,0 +1,28 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPageURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{"explore", "https://www.xiaohongshu.com/explore", nil},
+		{"note", "https://www.xiaohongshu.com/explore/abc?xsec_token=xyz", nil},
+		{"captcha", "https://www.xiaohongshu.com/website-login/captcha?redirectPath=x", ErrRiskControl},
+		{"creator login", "https://creator.xiaohongshu.com/login?source=publish", ErrNotLoggedIn},
+		{"404", "https://www.xiaohongshu.com/404?error_code=300031", ErrNoteNotFound},
+		{"404 xsec expired", "https://www.xiaohongshu.com/404?error_msg=%E9%93%BE%E6%8E%A5%E5%B7%B2%E5%A4%B1%E6%95%88", ErrXsecTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchPageURL(tt.url))
+		})
+	}
+}
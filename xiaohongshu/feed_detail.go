@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 // FeedDetailAction 表示 Feed 详情页动作
@@ -20,24 +22,32 @@ func NewFeedDetailAction(page *rod.Page) *FeedDetailAction {
 }
 
 // GetFeedDetail 获取 Feed 详情页数据
-func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (*FeedDetailResponse, error) {
-	page := f.page.Context(ctx).Timeout(60 * time.Second)
+func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (_ *FeedDetailResponse, err error) {
+	defer recoverAction(&err, "获取笔记详情")
+
+	page := f.page.Context(ctx).Timeout(configs.Get().Timeouts.Action)
 
 	// 构建详情页 URL
 	url := makeFeedDetailURL(feedID, xsecToken)
 
 	// 导航到详情页
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
+	if err := page.Navigate(url); err != nil {
+		return nil, stepErr("打开笔记详情页", "", err)
+	}
+	if err := waitDOMStable(page, "等待笔记详情页加载"); err != nil {
+		return nil, err
+	}
 	time.Sleep(1 * time.Second)
 
+	if err := diagnosePage(page, "打开笔记详情页"); err != nil {
+		return nil, err
+	}
+
 	// 获取 window.__INITIAL_STATE__ 并转换为 JSON 字符串
-	result := page.MustEval(`() => {
-		if (window.__INITIAL_STATE__) {
-			return JSON.stringify(window.__INITIAL_STATE__);
-		}
-		return "";
-	}`).String()
+	result, err := readInitialState(page)
+	if err != nil {
+		return nil, err
+	}
 
 	if result == "" {
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
@@ -60,7 +70,7 @@ func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken
 	// 从 noteDetailMap 中获取对应 feedID 的数据
 	noteDetail, exists := initialState.Note.NoteDetailMap[feedID]
 	if !exists {
-		return nil, fmt.Errorf("feed %s not found in noteDetailMap", feedID)
+		return nil, errors.Wrapf(ErrNoteNotFound, "feed %s not found in noteDetailMap", feedID)
 	}
 
 	return &FeedDetailResponse{
@@ -70,5 +80,5 @@ func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken
 }
 
 func makeFeedDetailURL(feedID, xsecToken string) string {
-	return fmt.Sprintf("https://www.xiaohongshu.com/explore/%s?xsec_token=%s&xsec_source=pc_feed", feedID, xsecToken)
+	return siteURL(fmt.Sprintf("/explore/%s?xsec_token=%s&xsec_source=pc_feed", feedID, xsecToken))
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 type FeedsListAction struct {
@@ -19,27 +20,33 @@ type FeedsResult struct {
 }
 
 func NewFeedsListAction(page *rod.Page) *FeedsListAction {
-	pp := page.Timeout(60 * time.Second)
+	return &FeedsListAction{page: page}
+}
 
-	pp.MustNavigate("https://www.xiaohongshu.com")
-	pp.MustWaitDOMStable()
+// GetFeedsList 打开首页并获取页面的 Feed 列表数据
+func (f *FeedsListAction) GetFeedsList(ctx context.Context) (_ []Feed, err error) {
+	defer recoverAction(&err, "获取首页推荐")
 
-	return &FeedsListAction{page: pp}
-}
+	page := f.page.Context(ctx).Timeout(configs.Get().Timeouts.Action)
 
-// GetFeedsList 获取页面的 Feed 列表数据
-func (f *FeedsListAction) GetFeedsList(ctx context.Context) ([]Feed, error) {
-	page := f.page.Context(ctx)
+	if err := page.Navigate(siteURL("/")); err != nil {
+		return nil, stepErr("打开首页", "", err)
+	}
+	if err := waitDOMStable(page, "等待首页加载"); err != nil {
+		return nil, err
+	}
 
 	time.Sleep(1 * time.Second)
 
+	if err := diagnosePage(page, "打开首页"); err != nil {
+		return nil, err
+	}
+
 	// 获取 window.__INITIAL_STATE__ 并转换为 JSON 字符串
-	result := page.MustEval(`() => {
-		if (window.__INITIAL_STATE__) {
-			return JSON.stringify(window.__INITIAL_STATE__);
-		}
-		return "";
-	}`).String()
+	result, err := readInitialState(page)
+	if err != nil {
+		return nil, err
+	}
 
 	if result == "" {
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
@@ -20,7 +20,7 @@ func TestGetFeedsList(t *testing.T) {
 	page := b.NewPage()
 	defer page.Close()
 
-	// NewFeedsListAction 内部已经处理导航
+	// GetFeedsList 内部会导航到首页
 	action := NewFeedsListAction(page)
 
 	feeds, err := action.GetFeedsList(context.Background())
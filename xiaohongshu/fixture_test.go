@@ -0,0 +1,222 @@
+package xiaohongshu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 离线测试：用 httptest 启动本地 fixture server，返回录制的小红书页面（带 __INITIAL_STATE__ 与关键 DOM），
+// 并通过 SetOrigins 将所有页面地址指向它，配合本地无头 Chrome 测试各个读取动作以及点赞、收藏流程。
+// 未找到 Chrome 时跳过，可通过 ROD_BROWSER_BIN 指定浏览器路径。
+
+const (
+	fixtureNoteID    = "6800000000000000000000a1"
+	fixtureXsecToken = "XSEC_A"
+	fixtureUserID    = "5f0000000000000000000001"
+)
+
+// fixtureServer 返回 testdata/fixtures 下录制的页面，并记录收到的请求
+type fixtureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*url.URL
+}
+
+func newFixtureServer(t *testing.T) *fixtureServer {
+	t.Helper()
+
+	fs := &fixtureServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fs.page("explore.html")(w, r)
+	})
+	mux.HandleFunc("/explore", fs.page("explore.html"))
+	mux.HandleFunc("/search_result", fs.page("search_result.html"))
+	mux.HandleFunc("/user/profile/", fs.page("user_profile.html"))
+	mux.HandleFunc("/explore/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/explore/") != fixtureNoteID {
+			// 与线上一致：笔记不存在时重定向到 404 页
+			fs.record(r)
+			http.Redirect(w, r, "/404?error_code=300031", http.StatusFound)
+			return
+		}
+		fs.page("note.html")(w, r)
+	})
+	mux.HandleFunc("/404", func(w http.ResponseWriter, r *http.Request) {
+		fs.record(r)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body><div id="app">当前笔记暂时无法浏览</div></body></html>`))
+	})
+	mux.HandleFunc("/favicon.ico", http.NotFound)
+
+	fs.Server = httptest.NewServer(mux)
+	t.Cleanup(fs.Close)
+
+	SetOrigins(fs.URL, fs.URL)
+	t.Cleanup(func() { SetOrigins("", "") })
+
+	return fs
+}
+
+func (fs *fixtureServer) page(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fs.record(r)
+		data, err := os.ReadFile(filepath.Join("testdata", "fixtures", name))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(data)
+	}
+}
+
+func (fs *fixtureServer) record(r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.requests = append(fs.requests, r.URL)
+}
+
+// lastRequest 返回最近一次请求 path 的 URL
+func (fs *fixtureServer) lastRequest(path string) *url.URL {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for i := len(fs.requests) - 1; i >= 0; i-- {
+		if fs.requests[i].Path == path {
+			return fs.requests[i]
+		}
+	}
+	return nil
+}
+
+// newFixturePage 启动本地无头 Chrome 并返回新页面
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	bin := os.Getenv("ROD_BROWSER_BIN")
+	if bin == "" {
+		bin, _ = launcher.LookPath()
+	}
+	if bin == "" {
+		t.Skip("SKIP: 未找到 Chrome，可通过 ROD_BROWSER_BIN 指定")
+	}
+
+	controlURL, err := launcher.New().Bin(bin).Headless(true).NoSandbox(true).Launch()
+	require.NoError(t, err)
+
+	b := rod.New().ControlURL(controlURL)
+	require.NoError(t, b.Connect())
+	t.Cleanup(func() { _ = b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	require.NoError(t, err)
+	return page.Timeout(60 * time.Second)
+}
+
+func TestFixtureFeedsList(t *testing.T) {
+	newFixtureServer(t)
+	page := newFixturePage(t)
+
+	feeds, err := NewFeedsListAction(page).GetFeedsList(context.Background())
+	require.NoError(t, err)
+	require.Len(t, feeds, 2)
+
+	assert.Equal(t, fixtureNoteID, feeds[0].ID)
+	assert.Equal(t, fixtureXsecToken, feeds[0].XsecToken)
+	assert.Equal(t, "周末去哪儿：城市公园野餐攻略", feeds[0].NoteCard.DisplayTitle)
+	assert.Equal(t, "阿离", feeds[0].NoteCard.User.Nickname)
+	require.NotNil(t, feeds[1].NoteCard.Video)
+	assert.Equal(t, 62, feeds[1].NoteCard.Video.Capa.Duration)
+}
+
+func TestFixtureSearch(t *testing.T) {
+	fs := newFixtureServer(t)
+	page := newFixturePage(t)
+
+	feeds, err := NewSearchAction(page).Search(context.Background(), "咖啡")
+	require.NoError(t, err)
+	require.Len(t, feeds, 1)
+	assert.Equal(t, "咖啡新手入门清单", feeds[0].NoteCard.DisplayTitle)
+
+	req := fs.lastRequest("/search_result")
+	require.NotNil(t, req)
+	assert.Equal(t, "咖啡", req.Query().Get("keyword"))
+}
+
+func TestFixtureFeedDetail(t *testing.T) {
+	fs := newFixtureServer(t)
+	page := newFixturePage(t)
+
+	detail, err := NewFeedDetailAction(page).GetFeedDetail(context.Background(), fixtureNoteID, fixtureXsecToken)
+	require.NoError(t, err)
+	assert.Equal(t, "周末去哪儿：城市公园野餐攻略", detail.Note.Title)
+	assert.Equal(t, "上海", detail.Note.IPLocation)
+	require.Len(t, detail.Comments.List, 1)
+	assert.Equal(t, "第一条评论", detail.Comments.List[0].Content)
+
+	req := fs.lastRequest("/explore/" + fixtureNoteID)
+	require.NotNil(t, req)
+	assert.Equal(t, fixtureXsecToken, req.Query().Get("xsec_token"))
+
+	_, err = NewFeedDetailAction(page).GetFeedDetail(context.Background(), "deleted-note", fixtureXsecToken)
+	assert.ErrorIs(t, err, ErrNoteNotFound)
+}
+
+func TestFixtureUserProfile(t *testing.T) {
+	newFixtureServer(t)
+	page := newFixturePage(t)
+
+	profile, err := NewUserProfileAction(page).UserProfile(context.Background(), fixtureUserID, fixtureXsecToken)
+	require.NoError(t, err)
+	assert.Equal(t, "阿离", profile.UserBasicInfo.Nickname)
+	assert.Equal(t, "100000001", profile.UserBasicInfo.RedId)
+	assert.Len(t, profile.Interactions, 3)
+	require.Len(t, profile.Feeds, 1)
+	assert.Equal(t, fixtureNoteID, profile.Feeds[0].ID)
+}
+
+func TestFixtureLikeAndFavorite(t *testing.T) {
+	newFixtureServer(t)
+	page := newFixturePage(t)
+	ctx := context.Background()
+
+	interact := newInteractAction(page)
+	state := func() (bool, bool) {
+		liked, collected, err := interact.getInteractState(page, fixtureNoteID)
+		require.NoError(t, err)
+		return liked, collected
+	}
+
+	require.NoError(t, NewLikeAction(page).Like(ctx, fixtureNoteID, fixtureXsecToken))
+	liked, collected := state()
+	assert.True(t, liked)
+	assert.False(t, collected)
+
+	require.NoError(t, NewFavoriteAction(page).Favorite(ctx, fixtureNoteID, fixtureXsecToken))
+	_, collected = state()
+	assert.True(t, collected)
+
+	// 每次操作都会重新打开详情页，fixture 页面的初始状态为未收藏，取消收藏时应直接跳过
+	require.NoError(t, NewFavoriteAction(page).Unfavorite(ctx, fixtureNoteID, fixtureXsecToken))
+	_, collected = state()
+	assert.False(t, collected)
+}
@@ -0,0 +1,374 @@
+package xiaohongshu
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// 调试包中的文件
+const (
+	BundleScreenshotFile   = "screenshot.png"
+	BundleHTMLFile         = "page.html"
+	BundleInitialStateFile = "initial_state.json"
+	BundleConsoleFile      = "console.log"
+	BundleTraceFile        = "trace.json"
+)
+
+// bundleFiles 调试包中允许读取的文件
+var bundleFiles = []string{BundleScreenshotFile, BundleHTMLFile, BundleInitialStateFile, BundleConsoleFile, BundleTraceFile}
+
+const (
+	maxConsoleEntries = 500              // 每个页面最多保留的控制台日志条数
+	maxTraceSteps     = 200              // 每个页面最多保留的步骤数
+	captureTimeout    = 20 * time.Second // 采集调试信息的最长时间
+)
+
+// bundleIDPattern 调试包 ID 只包含字母、数字、下划线和短横线，防止路径穿越
+var bundleIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// TraceStep 页面操作的一个步骤
+type TraceStep struct {
+	Time     time.Time `json:"time"`
+	Step     string    `json:"step"`
+	Selector string    `json:"selector,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// BundleTrace 调试包中 trace.json 的内容
+type BundleTrace struct {
+	ID        string      `json:"id"`
+	Action    string      `json:"action"`
+	Error     string      `json:"error"`
+	URL       string      `json:"url,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Steps     []TraceStep `json:"steps"`
+	Files     []string    `json:"files"`    // 成功写入的文件
+	Failures  []string    `json:"failures"` // 采集失败的项目
+}
+
+// PageRecorder 记录页面的控制台输出与操作步骤，操作失败时据此生成调试包
+type PageRecorder struct {
+	page   *rod.Page
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	console []string
+	steps   []TraceStep
+}
+
+var (
+	recordersMu sync.Mutex
+	recorders   = map[proto.TargetTargetID]*PageRecorder{}
+)
+
+// StartRecording 开始记录页面的控制台输出与操作步骤，页面关闭前需调用 Stop。
+// 未配置调试包目录时不做记录，也不在页面上开启 Runtime、Log 事件
+func StartRecording(page *rod.Page) *PageRecorder {
+	if configs.GetDebugDir() == "" {
+		return &PageRecorder{page: page, cancel: func() {}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &PageRecorder{page: page, cancel: cancel}
+
+	recordersMu.Lock()
+	recorders[page.TargetID] = r
+	recordersMu.Unlock()
+
+	go page.Context(ctx).EachEvent(
+		func(e *proto.RuntimeConsoleAPICalled) {
+			r.addConsole(string(e.Type), formatConsoleArgs(e.Args))
+		},
+		func(e *proto.RuntimeExceptionThrown) {
+			text := e.ExceptionDetails.Text
+			if e.ExceptionDetails.Exception != nil && e.ExceptionDetails.Exception.Description != "" {
+				text = e.ExceptionDetails.Exception.Description
+			}
+			r.addConsole("exception", text)
+		},
+		func(e *proto.LogEntryAdded) {
+			r.addConsole("log."+string(e.Entry.Level), e.Entry.Text+" "+e.Entry.URL)
+		},
+	)()
+
+	return r
+}
+
+// Stop 停止记录
+func (r *PageRecorder) Stop() {
+	r.cancel()
+
+	recordersMu.Lock()
+	if recorders[r.page.TargetID] == r {
+		delete(recorders, r.page.TargetID)
+	}
+	recordersMu.Unlock()
+}
+
+func (r *PageRecorder) addConsole(kind, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.console) >= maxConsoleEntries {
+		r.console = r.console[1:]
+	}
+	r.console = append(r.console, fmt.Sprintf("%s [%s] %s", time.Now().Format("15:04:05.000"), kind, strings.TrimSpace(text)))
+}
+
+func (r *PageRecorder) addStep(step TraceStep) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.steps) >= maxTraceSteps {
+		r.steps = r.steps[1:]
+	}
+	r.steps = append(r.steps, step)
+}
+
+// traceStep 记录一个操作步骤，页面没有在记录时什么也不做
+func traceStep(page *rod.Page, step string, key SelectorKey, err error) {
+	recordersMu.Lock()
+	r := recorders[page.TargetID]
+	recordersMu.Unlock()
+	if r == nil {
+		return
+	}
+
+	s := TraceStep{Time: time.Now(), Step: step}
+	if key != "" {
+		s.Selector = selectorLabel(key)
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	r.addStep(s)
+}
+
+// Capture 将页面截图、HTML、__INITIAL_STATE__、控制台输出和步骤记录写入调试包，返回调试包 ID。
+// 未配置调试包目录时返回空字符串。写入后按 paths.debug_max_bundles 删除最旧的调试包
+func (r *PageRecorder) Capture(action string, cause error) (string, error) {
+	root := configs.GetDebugDir()
+	if root == "" {
+		return "", nil
+	}
+
+	id := newBundleID(action)
+	dir := filepath.Join(root, id)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("创建调试包目录失败: %w", err)
+	}
+
+	// 调用方的 ctx 可能已取消，使用独立的超时采集
+	ctx, cancel := context.WithTimeout(context.Background(), captureTimeout)
+	defer cancel()
+	page := r.page.Context(ctx)
+
+	trace := BundleTrace{ID: id, Action: action, CreatedAt: time.Now()}
+	if cause != nil {
+		trace.Error = cause.Error()
+	}
+	if info, err := page.Info(); err == nil {
+		trace.URL = info.URL
+	}
+
+	write := func(name string, collect func() ([]byte, error)) {
+		data, err := collect()
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, name), data, 0o600)
+		}
+		if err != nil {
+			trace.Failures = append(trace.Failures, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		trace.Files = append(trace.Files, name)
+	}
+
+	write(BundleScreenshotFile, func() ([]byte, error) {
+		return page.Screenshot(true, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng})
+	})
+	write(BundleHTMLFile, func() ([]byte, error) {
+		html, err := page.HTML()
+		return []byte(html), err
+	})
+	write(BundleInitialStateFile, func() ([]byte, error) {
+		return readInitialStateSafe(page)
+	})
+
+	r.mu.Lock()
+	consoleLines := append([]string(nil), r.console...)
+	trace.Steps = append([]TraceStep(nil), r.steps...)
+	r.mu.Unlock()
+
+	write(BundleConsoleFile, func() ([]byte, error) {
+		return []byte(strings.Join(consoleLines, "\n")), nil
+	})
+
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, BundleTraceFile), data, 0o600); err != nil {
+		return "", fmt.Errorf("写入调试包失败: %w", err)
+	}
+
+	pageLog(r.page).Warnf("%s 失败，已保存调试包 %s", action, dir)
+	if err := pruneBundles(root, configs.Get().Paths.DebugMaxBundles); err != nil {
+		pageLog(r.page).Warnf("清理旧调试包失败: %v", err)
+	}
+	return id, nil
+}
+
+// pruneBundles 只保留最新的 max 个调试包，max 为 0 时不清理。调试包 ID 以时间开头，按名称排序即按时间排序
+func pruneBundles(root string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() && bundleIDPattern.MatchString(e.Name()) {
+			ids = append(ids, e.Name())
+		}
+	}
+	if len(ids) <= max {
+		return nil
+	}
+
+	sort.Strings(ids)
+	var errs []error
+	for _, id := range ids[:len(ids)-max] {
+		if err := os.RemoveAll(filepath.Join(root, id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readInitialStateSafe 序列化 window.__INITIAL_STATE__，跳过循环引用
+func readInitialStateSafe(page *rod.Page) ([]byte, error) {
+	res, err := page.Eval(`() => {
+		if (window.__INITIAL_STATE__ === undefined) return "null";
+		const seen = new WeakSet();
+		return JSON.stringify(window.__INITIAL_STATE__, (key, value) => {
+			if (typeof value === "object" && value !== null) {
+				if (seen.has(value)) return "[Circular]";
+				seen.add(value);
+			}
+			return value;
+		}, 2);
+	}`)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(res.Value.String()), nil
+}
+
+// formatConsoleArgs 将 console.* 的参数拼接成一行文本
+func formatConsoleArgs(args []*proto.RuntimeRemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case !arg.Value.Nil():
+			parts = append(parts, arg.Value.String())
+		case arg.Description != "":
+			parts = append(parts, arg.Description)
+		default:
+			parts = append(parts, string(arg.Type))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// newBundleID 生成调试包 ID，形如 20251016-150405-publish_content-1a2b3c
+func newBundleID(action string) string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, action)
+
+	return fmt.Sprintf("%s-%s-%s", time.Now().Format("20060102-150405"), name, hex.EncodeToString(suffix))
+}
+
+// BundleFilePath 返回调试包中文件的路径，file 只能是调试包中的文件（Bundle*File）
+func BundleFilePath(id, file string) (string, error) {
+	if !bundleIDPattern.MatchString(id) {
+		return "", fmt.Errorf("无效的调试包 ID: %s", id)
+	}
+	if !slices.Contains(bundleFiles, file) {
+		return "", fmt.Errorf("无效的文件名: %s", file)
+	}
+
+	root := configs.GetDebugDir()
+	if root == "" {
+		return "", errors.New("未启用调试包")
+	}
+	return filepath.Join(root, id, file), nil
+}
+
+// ReadBundleTrace 读取调试包的 trace.json
+func ReadBundleTrace(id string) (*BundleTrace, error) {
+	path, err := BundleFilePath(id, BundleTraceFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var trace BundleTrace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return nil, fmt.Errorf("解析调试包失败: %w", err)
+	}
+	return &trace, nil
+}
+
+// BundleError 附带调试包 ID 的错误
+type BundleError struct {
+	BundleID string
+	Err      error
+}
+
+func (e *BundleError) Error() string {
+	return fmt.Sprintf("%v (调试包: %s)", e.Err, e.BundleID)
+}
+
+func (e *BundleError) Unwrap() error {
+	return e.Err
+}
+
+// BundleIDOf 返回错误关联的调试包 ID
+func BundleIDOf(err error) (string, bool) {
+	var be *BundleError
+	if errors.As(err, &be) {
+		return be.BundleID, true
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+func TestBundleFilePath(t *testing.T) {
+	dir := t.TempDir()
+	old := configs.GetDebugDir()
+	configs.SetDebugDir(dir)
+	t.Cleanup(func() { configs.SetDebugDir(old) })
+
+	id := newBundleID("publish_content")
+	assert.Regexp(t, `^\d{8}-\d{6}-publish_content-[0-9a-f]{6}$`, id)
+
+	path, err := BundleFilePath(id, BundleTraceFile)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, id, BundleTraceFile), path)
+
+	for _, tt := range []struct{ id, file string }{
+		{"../etc", ""},
+		{"a/b", ""},
+		{id, "../trace.json"},
+		{id, "sub/trace.json"},
+		{id, "."},
+		{id, ".."},
+		{id, ""},
+		{id, "other.txt"},
+	} {
+		_, err := BundleFilePath(tt.id, tt.file)
+		assert.Error(t, err, "id=%q file=%q", tt.id, tt.file)
+	}
+}
+
+func TestReadBundleTrace(t *testing.T) {
+	dir := t.TempDir()
+	old := configs.GetDebugDir()
+	configs.SetDebugDir(dir)
+	t.Cleanup(func() { configs.SetDebugDir(old) })
+
+	want := BundleTrace{
+		ID:        "20250101-120000-post_comment-abcdef",
+		Action:    "post_comment",
+		Error:     "点击发送评论失败",
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Steps:     []TraceStep{{Step: "点击发送评论", Selector: "div.bottom button.submit", Error: "timeout"}},
+		Files:     []string{BundleScreenshotFile, BundleTraceFile},
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, want.ID), 0o700))
+	data, err := json.Marshal(want)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, want.ID, BundleTraceFile), data, 0o600))
+
+	got, err := ReadBundleTrace(want.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want.Steps, got.Steps)
+	assert.Equal(t, want.Error, got.Error)
+
+	_, err = ReadBundleTrace("20250101-120000-missing-000000")
+	assert.Error(t, err)
+}
+
+func TestBundleIDOf(t *testing.T) {
+	err := fmt.Errorf("发表评论: %w", &BundleError{BundleID: "b1", Err: ErrNotLoggedIn})
+
+	id, ok := BundleIDOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, "b1", id)
+	assert.ErrorIs(t, err, ErrNotLoggedIn)
+	assert.Contains(t, err.Error(), "调试包: b1")
+
+	_, ok = BundleIDOf(ErrNotLoggedIn)
+	assert.False(t, ok)
+}
+
+func TestPruneBundles(t *testing.T) {
+	dir := t.TempDir()
+	ids := []string{
+		"20250101-120000-publish_content-000001",
+		"20250101-120001-post_comment-000002",
+		"20250102-090000-search_feeds-000003",
+	}
+	for _, id := range ids {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, id), 0o700))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600))
+
+	require.NoError(t, pruneBundles(dir, 2))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, append(ids[1:], "notes.txt"), names)
+
+	require.NoError(t, pruneBundles(dir, 0), "0 表示不清理")
+}
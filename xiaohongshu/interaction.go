@@ -0,0 +1,23 @@
+package xiaohongshu
+
+import "context"
+
+// ReserveFunc 浏览推荐页时每次点赞、收藏、评论前调用，action 见 configs.Action*。
+// 返回错误时跳过这次互动；互动失败时调用 release 归还额度
+type ReserveFunc func(action string) (release func(), err error)
+
+type reserveKey struct{}
+
+// WithReserve 返回携带额度回调的 ctx，浏览推荐页时的互动逐次通过它占用额度
+func WithReserve(ctx context.Context, fn ReserveFunc) context.Context {
+	return context.WithValue(ctx, reserveKey{}, fn)
+}
+
+// reserveInteraction 占用一次互动的额度，ctx 中没有回调时直接放行
+func reserveInteraction(ctx context.Context, action string) (release func(), err error) {
+	fn, ok := ctx.Value(reserveKey{}).(ReserveFunc)
+	if !ok {
+		return func() {}, nil
+	}
+	return fn(action)
+}
@@ -0,0 +1,31 @@
+package xiaohongshu
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+func TestReserveInteraction(t *testing.T) {
+	release, err := reserveInteraction(context.Background(), configs.ActionLike)
+	assert.NoError(t, err, "没有回调时直接放行")
+	release()
+
+	var reserved []string
+	ctx := WithReserve(context.Background(), func(action string) (func(), error) {
+		if action == configs.ActionFavorite {
+			return nil, errors.New("超出额度")
+		}
+		reserved = append(reserved, action)
+		return func() {}, nil
+	})
+
+	_, err = reserveInteraction(ctx, configs.ActionLike)
+	assert.NoError(t, err)
+	_, err = reserveInteraction(ctx, configs.ActionFavorite)
+	assert.Error(t, err)
+	assert.Equal(t, []string{configs.ActionLike}, reserved)
+}
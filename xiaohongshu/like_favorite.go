@@ -8,7 +8,8 @@ import (
 
 	"github.com/go-rod/rod"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
 )
 
 // ActionResult 通用动作响应（点赞/收藏等）
@@ -18,12 +19,6 @@ type ActionResult struct {
 	Message string `json:"message"`
 }
 
-// 选择器常量
-const (
-	SelectorLikeButton    = ".interact-container .left .like-lottie"
-	SelectorCollectButton = ".interact-container .left .reds-icon.collect-icon"
-)
-
 // interactActionType 交互动作类型
 type interactActionType string
 
@@ -42,21 +37,28 @@ func newInteractAction(page *rod.Page) *interactAction {
 	return &interactAction{page: page}
 }
 
-func (a *interactAction) preparePage(ctx context.Context, actionType interactActionType, feedID, xsecToken string) *rod.Page {
-	page := a.page.Context(ctx).Timeout(60 * time.Second)
+func (a *interactAction) preparePage(ctx context.Context, actionType interactActionType, feedID, xsecToken string) (*rod.Page, error) {
+	page := a.page.Context(ctx).Timeout(configs.Get().Timeouts.Action)
 	url := makeFeedDetailURL(feedID, xsecToken)
-	logrus.Infof("Opening feed detail page for %s: %s", actionType, url)
+	logging.From(ctx).Infof("Opening feed detail page for %s: %s", actionType, url)
 
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
+	if err := page.Navigate(url); err != nil {
+		return nil, stepErr("打开笔记详情页", "", err)
+	}
+	if err := waitDOMStable(page, "等待笔记详情页加载"); err != nil {
+		return nil, err
+	}
 	time.Sleep(1 * time.Second)
 
-	return page
+	if err := requireLogin(page, "打开笔记详情页"); err != nil {
+		return nil, err
+	}
+
+	return page, nil
 }
 
-func (a *interactAction) performClick(page *rod.Page, selector string) {
-	element := page.MustElement(selector)
-	element.MustClick()
+func (a *interactAction) performClick(page *rod.Page, actionType interactActionType, key SelectorKey) error {
+	return clickElement(page, "点击"+string(actionType)+"按钮", key)
 }
 
 // LikeAction 负责处理点赞相关交互
@@ -78,26 +80,30 @@ func (a *LikeAction) Unlike(ctx context.Context, feedID, xsecToken string) error
 	return a.perform(ctx, feedID, xsecToken, false)
 }
 
-func (a *LikeAction) perform(ctx context.Context, feedID, xsecToken string, targetLiked bool) error {
+func (a *LikeAction) perform(ctx context.Context, feedID, xsecToken string, targetLiked bool) (err error) {
 	actionType := actionLike
 	if !targetLiked {
 		actionType = actionUnlike
 	}
+	defer recoverAction(&err, string(actionType))
 
-	page := a.preparePage(ctx, actionType, feedID, xsecToken)
+	page, err := a.preparePage(ctx, actionType, feedID, xsecToken)
+	if err != nil {
+		return err
+	}
 
 	liked, _, err := a.getInteractState(page, feedID)
 	if err != nil {
-		logrus.Warnf("failed to read interact state: %v (continue to try clicking)", err)
+		logging.From(ctx).Warnf("failed to read interact state: %v (continue to try clicking)", err)
 		return a.toggleLike(page, feedID, targetLiked, actionType)
 	}
 
 	if targetLiked && liked {
-		logrus.Infof("feed %s already liked, skip clicking", feedID)
+		logging.From(ctx).Infof("feed %s already liked, skip clicking", feedID)
 		return nil
 	}
 	if !targetLiked && !liked {
-		logrus.Infof("feed %s not liked yet, skip clicking", feedID)
+		logging.From(ctx).Infof("feed %s not liked yet, skip clicking", feedID)
 		return nil
 	}
 
@@ -105,30 +111,34 @@ func (a *LikeAction) perform(ctx context.Context, feedID, xsecToken string, targ
 }
 
 func (a *LikeAction) toggleLike(page *rod.Page, feedID string, targetLiked bool, actionType interactActionType) error {
-	a.performClick(page, SelectorLikeButton)
+	if err := a.performClick(page, actionType, SelectorLikeButton); err != nil {
+		return err
+	}
 	time.Sleep(3 * time.Second)
 
 	liked, _, err := a.getInteractState(page, feedID)
 	if err != nil {
-		logrus.Warnf("验证%s状态失败: %v", actionType, err)
+		pageLog(page).Warnf("验证%s状态失败: %v", actionType, err)
 		return nil
 	}
 	if liked == targetLiked {
-		logrus.Infof("feed %s %s成功", feedID, actionType)
+		pageLog(page).Infof("feed %s %s成功", feedID, actionType)
 		return nil
 	}
 
-	logrus.Warnf("feed %s %s可能未成功，状态未变化，尝试再次点击", feedID, actionType)
-	a.performClick(page, SelectorLikeButton)
+	pageLog(page).Warnf("feed %s %s可能未成功，状态未变化，尝试再次点击", feedID, actionType)
+	if err := a.performClick(page, actionType, SelectorLikeButton); err != nil {
+		return err
+	}
 	time.Sleep(2 * time.Second)
 
 	liked, _, err = a.getInteractState(page, feedID)
 	if err != nil {
-		logrus.Warnf("第二次验证%s状态失败: %v", actionType, err)
+		pageLog(page).Warnf("第二次验证%s状态失败: %v", actionType, err)
 		return nil
 	}
 	if liked == targetLiked {
-		logrus.Infof("feed %s 第二次点击%s成功", feedID, actionType)
+		pageLog(page).Infof("feed %s 第二次点击%s成功", feedID, actionType)
 		return nil
 	}
 
@@ -154,26 +164,30 @@ func (a *FavoriteAction) Unfavorite(ctx context.Context, feedID, xsecToken strin
 	return a.perform(ctx, feedID, xsecToken, false)
 }
 
-func (a *FavoriteAction) perform(ctx context.Context, feedID, xsecToken string, targetCollected bool) error {
+func (a *FavoriteAction) perform(ctx context.Context, feedID, xsecToken string, targetCollected bool) (err error) {
 	actionType := actionFavorite
 	if !targetCollected {
 		actionType = actionUnfavorite
 	}
+	defer recoverAction(&err, string(actionType))
 
-	page := a.preparePage(ctx, actionType, feedID, xsecToken)
+	page, err := a.preparePage(ctx, actionType, feedID, xsecToken)
+	if err != nil {
+		return err
+	}
 
 	_, collected, err := a.getInteractState(page, feedID)
 	if err != nil {
-		logrus.Warnf("failed to read interact state: %v (continue to try clicking)", err)
+		logging.From(ctx).Warnf("failed to read interact state: %v (continue to try clicking)", err)
 		return a.toggleFavorite(page, feedID, targetCollected, actionType)
 	}
 
 	if targetCollected && collected {
-		logrus.Infof("feed %s already favorited, skip clicking", feedID)
+		logging.From(ctx).Infof("feed %s already favorited, skip clicking", feedID)
 		return nil
 	}
 	if !targetCollected && !collected {
-		logrus.Infof("feed %s not favorited yet, skip clicking", feedID)
+		logging.From(ctx).Infof("feed %s not favorited yet, skip clicking", feedID)
 		return nil
 	}
 
@@ -181,30 +195,34 @@ func (a *FavoriteAction) perform(ctx context.Context, feedID, xsecToken string,
 }
 
 func (a *FavoriteAction) toggleFavorite(page *rod.Page, feedID string, targetCollected bool, actionType interactActionType) error {
-	a.performClick(page, SelectorCollectButton)
+	if err := a.performClick(page, actionType, SelectorCollectButton); err != nil {
+		return err
+	}
 	time.Sleep(3 * time.Second)
 
 	_, collected, err := a.getInteractState(page, feedID)
 	if err != nil {
-		logrus.Warnf("验证%s状态失败: %v", actionType, err)
+		pageLog(page).Warnf("验证%s状态失败: %v", actionType, err)
 		return nil
 	}
 	if collected == targetCollected {
-		logrus.Infof("feed %s %s成功", feedID, actionType)
+		pageLog(page).Infof("feed %s %s成功", feedID, actionType)
 		return nil
 	}
 
-	logrus.Warnf("feed %s %s可能未成功，状态未变化，尝试再次点击", feedID, actionType)
-	a.performClick(page, SelectorCollectButton)
+	pageLog(page).Warnf("feed %s %s可能未成功，状态未变化，尝试再次点击", feedID, actionType)
+	if err := a.performClick(page, actionType, SelectorCollectButton); err != nil {
+		return err
+	}
 	time.Sleep(2 * time.Second)
 
 	_, collected, err = a.getInteractState(page, feedID)
 	if err != nil {
-		logrus.Warnf("第二次验证%s状态失败: %v", actionType, err)
+		pageLog(page).Warnf("第二次验证%s状态失败: %v", actionType, err)
 		return nil
 	}
 	if collected == targetCollected {
-		logrus.Infof("feed %s 第二次点击%s成功", feedID, actionType)
+		pageLog(page).Infof("feed %s 第二次点击%s成功", feedID, actionType)
 		return nil
 	}
 
@@ -213,12 +231,10 @@ func (a *FavoriteAction) toggleFavorite(page *rod.Page, feedID string, targetCol
 
 // getInteractState 从 __INITIAL_STATE__ 读取笔记的点赞/收藏状态
 func (a *interactAction) getInteractState(page *rod.Page, feedID string) (liked bool, collected bool, err error) {
-	result := page.MustEval(`() => {
-		if (window.__INITIAL_STATE__) {
-			return JSON.stringify(window.__INITIAL_STATE__);
-		}
-		return "";
-	}`).String()
+	result, err := readInitialState(page)
+	if err != nil {
+		return false, false, err
+	}
 	if result == "" {
 		return false, false, fmt.Errorf("__INITIAL_STATE__ not found")
 	}
@@ -0,0 +1,12 @@
+package xiaohongshu
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/logging"
+)
+
+// pageLog 返回带有页面绑定的 ctx 中日志字段（请求 ID、任务 ID 等）的日志记录器
+func pageLog(page *rod.Page) *logrus.Entry {
+	return logging.From(page.GetContext())
+}
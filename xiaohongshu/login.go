@@ -16,13 +16,22 @@ func NewLogin(page *rod.Page) *LoginAction {
 	return &LoginAction{page: page}
 }
 
-func (a *LoginAction) CheckLoginStatus(ctx context.Context) (bool, error) {
+func (a *LoginAction) CheckLoginStatus(ctx context.Context) (_ bool, err error) {
+	defer recoverAction(&err, "检查登录状态")
+
 	pp := a.page.Context(ctx)
-	pp.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
+	if err := navigate(pp, "打开发现页", siteURL("/explore")); err != nil {
+		return false, err
+	}
 
 	time.Sleep(1 * time.Second)
 
-	exists, _, err := pp.Has(`.main-container .user .link-wrapper .channel`)
+	// 风控验证页需要人工处理，直接返回；未登录则正常返回 false
+	if err := diagnosePage(pp, "检查登录状态"); errors.Is(err, ErrRiskControl) {
+		return false, err
+	}
+
+	exists, _, err := hasElement(pp, SelectorLoginUserEntry)
 	if err != nil {
 		return false, errors.Wrap(err, "check login status failed")
 	}
@@ -34,44 +43,55 @@ func (a *LoginAction) CheckLoginStatus(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-func (a *LoginAction) Login(ctx context.Context) error {
+func (a *LoginAction) Login(ctx context.Context) (err error) {
+	defer recoverAction(&err, "扫码登录")
+
 	pp := a.page.Context(ctx)
 
 	// 导航到小红书首页，这会触发二维码弹窗
-	pp.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
+	if err := navigate(pp, "打开发现页", siteURL("/explore")); err != nil {
+		return err
+	}
 
 	// 等待一小段时间让页面完全加载
 	time.Sleep(2 * time.Second)
 
 	// 检查是否已经登录
-	if exists, _, _ := pp.Has(".main-container .user .link-wrapper .channel"); exists {
+	if exists, _, _ := hasElement(pp, SelectorLoginUserEntry); exists {
 		// 已经登录，直接返回
 		return nil
 	}
 
 	// 等待扫码成功提示或者登录完成
 	// 这里我们等待登录成功的元素出现，这样更简单可靠
-	pp.MustElement(".main-container .user .link-wrapper .channel")
-
-	return nil
+	_, err = findElement(pp, "等待登录完成", SelectorLoginUserEntry)
+	return err
 }
 
-func (a *LoginAction) FetchQrcodeImage(ctx context.Context) (string, bool, error) {
+func (a *LoginAction) FetchQrcodeImage(ctx context.Context) (_ string, _ bool, err error) {
+	defer recoverAction(&err, "获取登录二维码")
+
 	pp := a.page.Context(ctx)
 
 	// 导航到小红书首页，这会触发二维码弹窗
-	pp.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
+	if err := navigate(pp, "打开发现页", siteURL("/explore")); err != nil {
+		return "", false, err
+	}
 
 	// 等待一小段时间让页面完全加载
 	time.Sleep(2 * time.Second)
 
 	// 检查是否已经登录
-	if exists, _, _ := pp.Has(".main-container .user .link-wrapper .channel"); exists {
+	if exists, _, _ := hasElement(pp, SelectorLoginUserEntry); exists {
 		return "", true, nil
 	}
 
 	// 获取二维码图片
-	src, err := pp.MustElement(".login-container .qrcode-img").Attribute("src")
+	qrcode, err := findElement(pp, "查找登录二维码", SelectorLoginQrcode)
+	if err != nil {
+		return "", false, err
+	}
+	src, err := qrcode.Attribute("src")
 	if err != nil {
 		return "", false, errors.Wrap(err, "get qrcode src failed")
 	}
@@ -92,8 +112,7 @@ func (a *LoginAction) WaitForLogin(ctx context.Context) bool {
 		case <-ctx.Done():
 			return false
 		case <-ticker.C:
-			el, err := pp.Element(".main-container .user .link-wrapper .channel")
-			if err == nil && el != nil {
+			if exists, _, _ := hasElement(pp, SelectorLoginUserEntry); exists {
 				return true
 			}
 		}
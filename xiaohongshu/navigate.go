@@ -14,12 +14,14 @@ func NewNavigate(page *rod.Page) *NavigateAction {
 	return &NavigateAction{page: page}
 }
 
-func (n *NavigateAction) ToExplorePage(ctx context.Context) error {
-	page := n.page.Context(ctx)
+func (n *NavigateAction) ToExplorePage(ctx context.Context) (err error) {
+	defer recoverAction(&err, "打开发现页")
 
-	page.MustNavigate("https://www.xiaohongshu.com/explore").
-		MustWaitLoad().
-		MustElement(`div#app`)
+	page := n.page.Context(ctx)
 
-	return nil
+	if err := navigate(page, "打开发现页", siteURL("/explore")); err != nil {
+		return err
+	}
+	_, err = findElement(page, "等待发现页渲染", SelectorExploreApp)
+	return err
 }
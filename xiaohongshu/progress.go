@@ -0,0 +1,91 @@
+package xiaohongshu
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// 进度上报的阶段
+const (
+	StageAcquirePage  = "acquire_page"      // 等待空闲的浏览器页面
+	StageOpenPublish  = "open_publish_page" // 打开发布页
+	StageUploadImages = "upload_images"     // 上传图片，current/total 为已上传/总图片数
+	StageProcessVideo = "process_video"     // 视频上传与处理中
+	StageVideoReady   = "video_ready"       // 视频处理完成
+	StageConfirm      = "confirm"           // 等待用户确认发布（开启 confirm.publish 时）
+	StageInputTitle   = "input_title"       // 输入标题
+	StageInputContent = "input_content"     // 输入正文与话题
+	StageSubmit       = "submit"            // 点击发布并检查结果
+	StageBrowse       = "browse"            // 浏览推荐页，browse 中为实时统计
+)
+
+// Progress 页面操作的进度
+type Progress struct {
+	Stage          string          `json:"stage"`
+	Message        string          `json:"message"`
+	Current        int             `json:"current,omitempty"`
+	Total          int             `json:"total,omitempty"`
+	ElapsedSeconds int             `json:"elapsed_seconds,omitempty"` // 当前阶段已等待的时间
+	Browse         *BrowseProgress `json:"browse,omitempty"`
+}
+
+// BrowseProgress 浏览推荐页的实时统计
+type BrowseProgress struct {
+	InstanceID     string `json:"instance_id,omitempty"`
+	ElapsedSeconds int    `json:"elapsed_seconds"`
+	ScrollCount    int    `json:"scroll_count"`
+	ClickCount     int    `json:"click_count"`
+	LikeCount      int    `json:"like_count"`
+	FavoriteCount  int    `json:"favorite_count"`
+	CommentCount   int    `json:"comment_count"`
+	ViewedCount    int    `json:"viewed_count"`
+}
+
+// ProgressFunc 接收进度的回调，可能在任意 goroutine 中调用，不应阻塞
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress 返回携带进度回调的 ctx，页面操作通过 ReportProgress 上报进度。
+// ctx 中已有回调时两者都会收到进度
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	if parent, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		next := fn
+		fn = func(p Progress) {
+			parent(p)
+			next(p)
+		}
+	}
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress 上报进度，ctx 中没有回调时什么也不做
+func ReportProgress(ctx context.Context, p Progress) {
+	if ctx == nil {
+		return
+	}
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(p)
+	}
+}
+
+// reportPageProgress 通过页面绑定的 ctx 上报进度
+func reportPageProgress(page *rod.Page, p Progress) {
+	ReportProgress(page.GetContext(), p)
+}
+
+// browseProgress 生成浏览统计的快照
+func browseProgress(instanceID string, stats *BrowseStats, elapsed time.Duration) *BrowseProgress {
+	return &BrowseProgress{
+		InstanceID:     instanceID,
+		ElapsedSeconds: int(elapsed.Seconds()),
+		ScrollCount:    stats.ScrollCount,
+		ClickCount:     stats.ClickCount,
+		LikeCount:      stats.LikeCount,
+		FavoriteCount:  stats.FavoriteCount,
+		CommentCount:   stats.CommentCount,
+		ViewedCount:    len(stats.ViewedNotes),
+	}
+}
@@ -0,0 +1,22 @@
+package xiaohongshu
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProgressChainsCallbacks(t *testing.T) {
+	ReportProgress(context.Background(), Progress{Stage: StageSubmit}) // 没有回调时不做任何事
+
+	var outer, inner []string
+	ctx := WithProgress(context.Background(), func(p Progress) { outer = append(outer, p.Stage) })
+	ctx = WithProgress(ctx, func(p Progress) { inner = append(inner, p.Stage) })
+
+	ReportProgress(ctx, Progress{Stage: StageUploadImages, Current: 1, Total: 3})
+	ReportProgress(ctx, Progress{Stage: StageSubmit})
+
+	assert.Equal(t, []string{StageUploadImages, StageSubmit}, outer)
+	assert.Equal(t, outer, inner)
+}
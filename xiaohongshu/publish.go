@@ -2,7 +2,7 @@ package xiaohongshu
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
 	"math/rand"
 	"os"
 	"strings"
@@ -13,6 +13,7 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 // PublishImageContent 发布图文内容
@@ -28,18 +29,20 @@ type PublishAction struct {
 }
 
 const (
-	urlOfPublic = `https://creator.xiaohongshu.com/publish/publish?source=official`
+	pathOfPublish = `/publish/publish?source=official`
 )
 
 func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 
-	pp := page.Timeout(180 * time.Second)
+	pp := page.Timeout(configs.Get().Timeouts.Publish)
 
-	pp.MustNavigate(urlOfPublic).MustWaitIdle().MustWaitDOMStable()
+	if err := openPublishPage(pp); err != nil {
+		return nil, err
+	}
 	time.Sleep(1 * time.Second)
 
-	if err := mustClickPublishTab(page, "上传图文"); err != nil {
-		logrus.Errorf("点击上传图文 TAB 失败: %v", err)
+	if err := clickPublishTab(page, "上传图文"); err != nil {
+		pageLog(page).Errorf("点击上传图文 TAB 失败: %v", err)
 		return nil, err
 	}
 
@@ -50,7 +53,9 @@ func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 	}, nil
 }
 
-func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent) error {
+func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent) (err error) {
+	defer recoverAction(&err, "发布图文")
+
 	if len(content.ImagePaths) == 0 {
 		return errors.New("图片不能为空")
 	}
@@ -61,6 +66,15 @@ func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent
 		return errors.Wrap(err, "小红书上传图片失败")
 	}
 
+	if err := ConfirmPublish(ctx, PublishPreview{
+		Title:   content.Title,
+		Content: content.Content,
+		Tags:    content.Tags,
+		Images:  content.ImagePaths,
+	}); err != nil {
+		return err
+	}
+
 	if err := submitPublish(page, content.Title, content.Content, content.Tags); err != nil {
 		return errors.Wrap(err, "小红书发布失败")
 	}
@@ -68,15 +82,31 @@ func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent
 	return nil
 }
 
+// openPublishPage 打开创作者中心发布页并等待加载完成
+func openPublishPage(page *rod.Page) error {
+	if err := page.Navigate(creatorURL(pathOfPublish)); err != nil {
+		return stepErr("打开发布页", "", err)
+	}
+	if err := page.WaitIdle(time.Minute); err != nil {
+		return stepErr("等待发布页空闲", "", err)
+	}
+	if err := waitDOMStable(page, "等待发布页加载"); err != nil {
+		return err
+	}
+	return diagnosePage(page, "打开发布页")
+}
+
 func removePopCover(page *rod.Page) {
 
 	// 先移除弹窗封面
-	has, elem, err := page.Has("div.d-popover")
+	has, elem, err := hasElement(page, SelectorPublishPopover)
 	if err != nil {
 		return
 	}
 	if has {
-		elem.MustRemove()
+		if err := elem.Remove(); err != nil {
+			pageLog(page).Warnf("移除弹窗封面失败: %v", err)
+		}
 	}
 
 	// 兜底：点击一下空位置吧
@@ -86,17 +116,29 @@ func removePopCover(page *rod.Page) {
 func clickEmptyPosition(page *rod.Page) {
 	x := 380 + rand.Intn(100)
 	y := 20 + rand.Intn(60)
-	page.Mouse.MustMoveTo(float64(x), float64(y)).MustClick(proto.InputMouseButtonLeft)
+	if err := page.Mouse.MoveTo(proto.NewPoint(float64(x), float64(y))); err != nil {
+		pageLog(page).Warnf("移动鼠标到空白位置失败: %v", err)
+		return
+	}
+	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		pageLog(page).Warnf("点击空白位置失败: %v", err)
+	}
 }
 
-func mustClickPublishTab(page *rod.Page, tabname string) error {
-	page.MustElement(`div.upload-content`).MustWaitVisible()
+func clickPublishTab(page *rod.Page, tabname string) error {
+	uploadContent, err := findElement(page, "等待上传区域", SelectorPublishUpload)
+	if err != nil {
+		return err
+	}
+	if err := uploadContent.WaitVisible(); err != nil {
+		return stepErr("等待上传区域可见", selectorLabel(SelectorPublishUpload), err)
+	}
 
 	deadline := time.Now().Add(15 * time.Second)
 	for time.Now().Before(deadline) {
 		tab, blocked, err := getTabElement(page, tabname)
 		if err != nil {
-			logrus.Warnf("获取发布 TAB 元素失败: %v", err)
+			pageLog(page).Warnf("获取发布 TAB 元素失败: %v", err)
 			time.Sleep(200 * time.Millisecond)
 			continue
 		}
@@ -107,14 +149,14 @@ func mustClickPublishTab(page *rod.Page, tabname string) error {
 		}
 
 		if blocked {
-			logrus.Info("发布 TAB 被遮挡，尝试移除遮挡")
+			pageLog(page).Info("发布 TAB 被遮挡，尝试移除遮挡")
 			removePopCover(page)
 			time.Sleep(200 * time.Millisecond)
 			continue
 		}
 
 		if err := tab.Click(proto.InputMouseButtonLeft, 1); err != nil {
-			logrus.Warnf("点击发布 TAB 失败: %v", err)
+			pageLog(page).Warnf("点击发布 TAB 失败: %v", err)
 			time.Sleep(200 * time.Millisecond)
 			continue
 		}
@@ -122,11 +164,11 @@ func mustClickPublishTab(page *rod.Page, tabname string) error {
 		return nil
 	}
 
-	return errors.Errorf("没有找到发布 TAB - %s", tabname)
+	return stepErr("点击发布 TAB - "+tabname, selectorLabel(SelectorPublishTab), errors.New("没有找到可点击的 TAB"))
 }
 
 func getTabElement(page *rod.Page, tabname string) (*rod.Element, bool, error) {
-	elems, err := page.Elements("div.creator-tab")
+	elems, err := findAllElements(page, SelectorPublishTab)
 	if err != nil {
 		return nil, false, err
 	}
@@ -138,7 +180,7 @@ func getTabElement(page *rod.Page, tabname string) (*rod.Element, bool, error) {
 
 		text, err := elem.Text()
 		if err != nil {
-			logrus.Debugf("获取发布 TAB 文本失败: %v", err)
+			pageLog(page).Debugf("获取发布 TAB 文本失败: %v", err)
 			continue
 		}
 
@@ -186,10 +228,15 @@ func uploadImages(page *rod.Page, imagesPaths []string) error {
 	}
 
 	// 等待上传输入框出现
-	uploadInput := pp.MustElement(".upload-input")
+	uploadInput, err := findElement(pp, "查找图片上传输入框", SelectorUploadInput)
+	if err != nil {
+		return err
+	}
 
 	// 上传多个文件
-	uploadInput.MustSetFiles(imagesPaths...)
+	if err := uploadInput.SetFiles(imagesPaths); err != nil {
+		return stepErr("选择上传图片", selectorLabel(SelectorUploadInput), err)
+	}
 
 	// 等待并验证上传完成
 	return waitForUploadComplete(pp, len(imagesPaths))
@@ -197,150 +244,197 @@ func uploadImages(page *rod.Page, imagesPaths []string) error {
 
 // waitForUploadComplete 等待并验证上传完成
 func waitForUploadComplete(page *rod.Page, expectedCount int) error {
-	maxWaitTime := 60 * time.Second
+	maxWaitTime := configs.Get().Timeouts.ImageUpload
 	checkInterval := 500 * time.Millisecond
 	start := time.Now()
 
-	slog.Info("开始等待图片上传完成", "expected_count", expectedCount)
+	pageLog(page).WithField("expected_count", expectedCount).Info("开始等待图片上传完成")
 
+	reported := -1
 	for time.Since(start) < maxWaitTime {
+		if err := page.GetContext().Err(); err != nil {
+			return errors.Wrap(err, "等待图片上传时操作被取消")
+		}
+
 		// 使用具体的pr类名检查已上传的图片
-		uploadedImages, err := page.Elements(".img-preview-area .pr")
+		uploadedImages, err := findAllElements(page, SelectorImagePreview)
 
-		slog.Info("uploadedImages", "uploadedImages", uploadedImages)
+		pageLog(page).Debugf("uploadedImages: %v", uploadedImages)
 
 		if err == nil {
 			currentCount := len(uploadedImages)
-			slog.Info("检测到已上传图片", "current_count", currentCount, "expected_count", expectedCount)
+			pageLog(page).WithFields(logrus.Fields{
+				"current_count":  currentCount,
+				"expected_count": expectedCount,
+			}).Info("检测到已上传图片")
+			if currentCount != reported {
+				reported = currentCount
+				reportPageProgress(page, Progress{
+					Stage:          StageUploadImages,
+					Message:        fmt.Sprintf("已上传 %d/%d 张图片", min(currentCount, expectedCount), expectedCount),
+					Current:        min(currentCount, expectedCount),
+					Total:          expectedCount,
+					ElapsedSeconds: int(time.Since(start).Seconds()),
+				})
+			}
 			if currentCount >= expectedCount {
-				slog.Info("所有图片上传完成", "count", currentCount)
+				pageLog(page).WithField("count", currentCount).Info("所有图片上传完成")
 				return nil
 			}
 		} else {
-			slog.Debug("未找到已上传图片元素")
+			pageLog(page).Debug("未找到已上传图片元素")
 		}
 
 		time.Sleep(checkInterval)
 	}
 
-	return errors.New("上传超时，请检查网络连接和图片大小")
+	return errors.Wrapf(ErrUploadTimeout, "图片未在 %v 内上传完成，请检查网络连接和图片大小", maxWaitTime)
 }
 
 func submitPublish(page *rod.Page, title, content string, tags []string) error {
 
-	titleElem := page.MustElement("div.d-input input")
-	titleElem.MustInput(title)
+	reportPageProgress(page, Progress{Stage: StageInputTitle, Message: "输入标题"})
+	if err := inputElement(page, "输入标题", SelectorTitleInput, title); err != nil {
+		return err
+	}
 
 	time.Sleep(1 * time.Second)
 
-	if contentElem, ok := getContentElement(page); ok {
-		contentElem.MustInput(content)
-
-		inputTags(contentElem, tags)
-
-	} else {
-		return errors.New("没有找到内容输入框")
+	reportPageProgress(page, Progress{Stage: StageInputContent, Message: "输入正文与话题"})
+	if err := inputContentAndTags(page, content, tags); err != nil {
+		return err
 	}
 
 	time.Sleep(1 * time.Second)
 
-	submitButton := page.MustElement("div.submit div.d-button-content")
-	submitButton.MustClick()
+	reportPageProgress(page, Progress{Stage: StageSubmit, Message: "点击发布"})
+	if err := clickElement(page, "点击发布按钮", SelectorPublishButton); err != nil {
+		return err
+	}
 
 	time.Sleep(3 * time.Second)
 
-	return nil
+	return checkPublishRejected(page)
+}
+
+// inputContentAndTags 输入正文并在末尾追加话题标签（图文与视频发布共用）
+func inputContentAndTags(page *rod.Page, content string, tags []string) error {
+	contentElem, ok := getContentElement(page)
+	if !ok {
+		return stepErr("查找正文输入框", selectorLabel(SelectorContentEditor), errors.New("没有找到内容输入框"))
+	}
+
+	if err := contentElem.Input(content); err != nil {
+		return stepErr("输入正文", selectorLabel(SelectorContentEditor), err)
+	}
+
+	return inputTags(contentElem, tags)
 }
 
-// 查找内容输入框 - 使用Race方法处理两种样式
+// 查找内容输入框 - 使用Race方法处理两种样式（编辑器选择器与 placeholder 段落）
 func getContentElement(page *rod.Page) (*rod.Element, bool) {
-	var foundElement *rod.Element
-	var found bool
-
-	page.Race().
-		Element("div.ql-editor").MustHandle(func(e *rod.Element) {
-		foundElement = e
-		found = true
-	}).
+	race := page.Race()
+	for _, sel := range selectorsFor(SelectorContentEditor) {
+		race = race.Element(sel)
+	}
+	foundElement, err := race.
 		ElementFunc(func(page *rod.Page) (*rod.Element, error) {
 			return findTextboxByPlaceholder(page)
-		}).MustHandle(func(e *rod.Element) {
-		foundElement = e
-		found = true
-	}).
-		MustDo()
+		}).
+		Do()
 
-	if found {
+	if err == nil && foundElement != nil {
 		return foundElement, true
 	}
 
-	slog.Warn("no content element found by any method")
+	pageLog(page).WithError(err).Warn("no content element found by any method")
 	return nil, false
 }
 
-func inputTags(contentElem *rod.Element, tags []string) {
+func inputTags(contentElem *rod.Element, tags []string) error {
 	if len(tags) == 0 {
-		return
+		return nil
 	}
 
 	time.Sleep(1 * time.Second)
 
 	for i := 0; i < 20; i++ {
-		contentElem.MustKeyActions().
-			Type(input.ArrowDown).
-			MustDo()
+		if err := doKeyActions(contentElem, func(ka *rod.KeyActions) *rod.KeyActions {
+			return ka.Type(input.ArrowDown)
+		}); err != nil {
+			return stepErr("移动光标到正文末尾", "", err)
+		}
 		time.Sleep(10 * time.Millisecond)
 	}
 
-	contentElem.MustKeyActions().
-		Press(input.Enter).
-		Press(input.Enter).
-		MustDo()
+	if err := doKeyActions(contentElem, func(ka *rod.KeyActions) *rod.KeyActions {
+		return ka.Press(input.Enter).Press(input.Enter)
+	}); err != nil {
+		return stepErr("正文末尾换行", "", err)
+	}
 
 	time.Sleep(1 * time.Second)
 
 	for _, tag := range tags {
 		tag = strings.TrimLeft(tag, "#")
-		inputTag(contentElem, tag)
+		if err := inputTag(contentElem, tag); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func inputTag(contentElem *rod.Element, tag string) {
-	contentElem.MustInput("#")
+// doKeyActions 在元素上执行一组键盘动作
+func doKeyActions(elem *rod.Element, build func(ka *rod.KeyActions) *rod.KeyActions) error {
+	ka, err := elem.KeyActions()
+	if err != nil {
+		return err
+	}
+	return build(ka).Do()
+}
+
+func inputTag(contentElem *rod.Element, tag string) error {
+	step := "输入标签 #" + tag
+	if err := contentElem.Input("#"); err != nil {
+		return stepErr(step, "", err)
+	}
 	time.Sleep(200 * time.Millisecond)
 
 	for _, char := range tag {
-		contentElem.MustInput(string(char))
+		if err := contentElem.Input(string(char)); err != nil {
+			return stepErr(step, "", err)
+		}
 		time.Sleep(50 * time.Millisecond)
 	}
 
 	time.Sleep(1 * time.Second)
 
 	page := contentElem.Page()
-	topicContainer, err := page.Element("#creator-editor-topic-container")
-	if err == nil && topicContainer != nil {
-		firstItem, err := topicContainer.Element(".item")
-		if err == nil && firstItem != nil {
-			firstItem.MustClick()
-			slog.Info("成功点击标签联想选项", "tag", tag)
-			time.Sleep(200 * time.Millisecond)
-		} else {
-			slog.Warn("未找到标签联想选项，直接输入空格", "tag", tag)
-			// 如果没有找到联想选项，输入空格结束
-			contentElem.MustInput(" ")
+	firstItem, err := findElement(page, "查找标签联想选项", SelectorTopicItem)
+	if err == nil && firstItem != nil {
+		if err := firstItem.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return stepErr("点击标签联想选项 #"+tag, selectorLabel(SelectorTopicItem), err)
 		}
+		pageLog(contentElem.Page()).WithField("tag", tag).Info("成功点击标签联想选项")
+		time.Sleep(200 * time.Millisecond)
 	} else {
-		slog.Warn("未找到标签联想下拉框，直接输入空格", "tag", tag)
-		// 如果没有找到下拉框，输入空格结束
-		contentElem.MustInput(" ")
+		pageLog(contentElem.Page()).WithField("tag", tag).Warn("未找到标签联想选项，直接输入空格")
+		// 如果没有找到联想选项，输入空格结束
+		if err := contentElem.Input(" "); err != nil {
+			return stepErr(step, "", err)
+		}
 	}
 
 	time.Sleep(500 * time.Millisecond) // 等待标签处理完成
+	return nil
 }
 
 func findTextboxByPlaceholder(page *rod.Page) (*rod.Element, error) {
-	elements := page.MustElements("p")
-	if elements == nil {
+	elements, err := page.Elements("p")
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
 		return nil, errors.New("no p elements found")
 	}
 
@@ -415,7 +509,7 @@ func isElementVisible(elem *rod.Element) bool {
 
 	visible, err := elem.Visible()
 	if err != nil {
-		slog.Warn("无法获取元素可见性", "error", err)
+		pageLog(elem.Page()).WithError(err).Warn("无法获取元素可见性")
 		return true
 	}
 
@@ -2,7 +2,7 @@ package xiaohongshu
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -10,6 +10,7 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 // PublishVideoContent 发布视频内容
@@ -24,10 +25,12 @@ type PublishVideoContent struct {
 func NewPublishVideoAction(page *rod.Page) (*PublishAction, error) {
 	pp := page.Timeout(300 * time.Second)
 
-	pp.MustNavigate(urlOfPublic).MustWaitIdle().MustWaitDOMStable()
+	if err := openPublishPage(pp); err != nil {
+		return nil, err
+	}
 	time.Sleep(1 * time.Second)
 
-	if err := mustClickPublishTab(page, "上传视频"); err != nil {
+	if err := clickPublishTab(page, "上传视频"); err != nil {
 		return nil, errors.Wrap(err, "切换到上传视频失败")
 	}
 
@@ -37,7 +40,9 @@ func NewPublishVideoAction(page *rod.Page) (*PublishAction, error) {
 }
 
 // PublishVideo 上传视频并提交
-func (p *PublishAction) PublishVideo(ctx context.Context, content PublishVideoContent) error {
+func (p *PublishAction) PublishVideo(ctx context.Context, content PublishVideoContent) (err error) {
+	defer recoverAction(&err, "发布视频")
+
 	if content.VideoPath == "" {
 		return errors.New("视频不能为空")
 	}
@@ -48,6 +53,15 @@ func (p *PublishAction) PublishVideo(ctx context.Context, content PublishVideoCo
 		return errors.Wrap(err, "小红书上传视频失败")
 	}
 
+	if err := ConfirmPublish(ctx, PublishPreview{
+		Title:   content.Title,
+		Content: content.Content,
+		Tags:    content.Tags,
+		Video:   content.VideoPath,
+	}); err != nil {
+		return err
+	}
+
 	if err := submitPublishVideo(page, content.Title, content.Content, content.Tags); err != nil {
 		return errors.Wrap(err, "小红书发布失败")
 	}
@@ -56,45 +70,57 @@ func (p *PublishAction) PublishVideo(ctx context.Context, content PublishVideoCo
 
 // uploadVideo 上传单个本地视频
 func uploadVideo(page *rod.Page, videoPath string) error {
-	pp := page.Timeout(5 * time.Minute) // 视频处理耗时更长
+	pp := page.Timeout(configs.Get().Timeouts.VideoUpload) // 视频处理耗时更长
 
 	if _, err := os.Stat(videoPath); os.IsNotExist(err) {
 		return errors.Wrapf(err, "视频文件不存在: %s", videoPath)
 	}
 
 	// 寻找文件上传输入框（与图文一致的 class，或退回到 input[type=file]）
-	var fileInput *rod.Element
-	var err error
-	fileInput, err = pp.Element(".upload-input")
-	if err != nil || fileInput == nil {
-		fileInput, err = pp.Element("input[type='file']")
-		if err != nil || fileInput == nil {
-			return errors.New("未找到视频上传输入框")
-		}
+	fileInput, err := findElement(pp, "查找视频上传输入框", SelectorUploadInput)
+	if err != nil {
+		return err
 	}
 
-	fileInput.MustSetFiles(videoPath)
+	if err := fileInput.SetFiles([]string{videoPath}); err != nil {
+		return stepErr("选择上传视频", selectorLabel(SelectorUploadInput), err)
+	}
 
 	// 对于视频，等待发布按钮变为可点击即表示处理完成
-	btn, err := waitForPublishButtonClickable(pp)
-	if err != nil {
+	if _, err := waitForPublishButtonClickable(pp); err != nil {
 		return err
 	}
-	slog.Info("视频上传/处理完成，发布按钮可点击", "btn", btn)
+	pageLog(pp).Info("视频上传/处理完成，发布按钮可点击")
+	reportPageProgress(pp, Progress{Stage: StageVideoReady, Message: "视频处理完成"})
 	return nil
 }
 
+// videoProgressInterval 等待视频处理时上报进度的间隔
+const videoProgressInterval = 5 * time.Second
+
 // waitForPublishButtonClickable 等待发布按钮可点击
 func waitForPublishButtonClickable(page *rod.Page) (*rod.Element, error) {
-	maxWait := 10 * time.Minute
+	maxWait := configs.Get().Timeouts.VideoUpload
 	interval := 1 * time.Second
 	start := time.Now()
-	selector := "button.publishBtn"
-
-	slog.Info("开始等待发布按钮可点击(视频)")
+	pageLog(page).Info("开始等待发布按钮可点击(视频)")
 
+	lastReport := start
 	for time.Since(start) < maxWait {
-		btn, err := page.Element(selector)
+		if err := page.GetContext().Err(); err != nil {
+			return nil, errors.Wrap(err, "等待视频处理时操作被取消")
+		}
+		if time.Since(lastReport) >= videoProgressInterval {
+			lastReport = time.Now()
+			elapsed := int(time.Since(start).Seconds())
+			reportPageProgress(page, Progress{
+				Stage:          StageProcessVideo,
+				Message:        fmt.Sprintf("视频上传处理中，已等待 %d 秒", elapsed),
+				ElapsedSeconds: elapsed,
+			})
+		}
+
+		_, btn, err := hasElement(page, SelectorVideoPublishBtn)
 		if err == nil && btn != nil {
 			// 可见性
 			vis, verr := btn.Visible()
@@ -112,22 +138,22 @@ func waitForPublishButtonClickable(page *rod.Page) (*rod.Element, error) {
 		}
 		time.Sleep(interval)
 	}
-	return nil, errors.New("等待发布按钮可点击超时")
+	return nil, errors.Wrap(ErrUploadTimeout, "等待发布按钮可点击超时")
 }
 
 // submitPublishVideo 填写标题、正文、标签并点击发布（等待按钮可点击后再提交）
 func submitPublishVideo(page *rod.Page, title, content string, tags []string) error {
 	// 标题
-	titleElem := page.MustElement("div.d-input input")
-	titleElem.MustInput(title)
+	reportPageProgress(page, Progress{Stage: StageInputTitle, Message: "输入标题"})
+	if err := inputElement(page, "输入标题", SelectorTitleInput, title); err != nil {
+		return err
+	}
 	time.Sleep(1 * time.Second)
 
 	// 正文 + 标签
-	if contentElem, ok := getContentElement(page); ok {
-		contentElem.MustInput(content)
-		inputTags(contentElem, tags)
-	} else {
-		return errors.New("没有找到内容输入框")
+	reportPageProgress(page, Progress{Stage: StageInputContent, Message: "输入正文与话题"})
+	if err := inputContentAndTags(page, content, tags); err != nil {
+		return err
 	}
 
 	time.Sleep(1 * time.Second)
@@ -139,10 +165,11 @@ func submitPublishVideo(page *rod.Page, title, content string, tags []string) er
 	}
 
 	// 点击发布
+	reportPageProgress(page, Progress{Stage: StageSubmit, Message: "点击发布"})
 	if err := btn.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		return errors.Wrap(err, "点击发布按钮失败")
 	}
 
 	time.Sleep(3 * time.Second)
-	return nil
+	return checkPublishRejected(page)
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 type SearchResult struct {
@@ -21,27 +22,35 @@ type SearchAction struct {
 }
 
 func NewSearchAction(page *rod.Page) *SearchAction {
-	pp := page.Timeout(60 * time.Second)
-
-	return &SearchAction{page: pp}
+	return &SearchAction{page: page}
 }
 
-func (s *SearchAction) Search(ctx context.Context, keyword string) ([]Feed, error) {
-	page := s.page.Context(ctx)
+func (s *SearchAction) Search(ctx context.Context, keyword string) (_ []Feed, err error) {
+	defer recoverAction(&err, "搜索笔记")
+
+	page := s.page.Context(ctx).Timeout(configs.Get().Timeouts.Action)
 
 	searchURL := makeSearchURL(keyword)
-	page.MustNavigate(searchURL)
-	page.MustWaitStable()
+	if err := page.Navigate(searchURL); err != nil {
+		return nil, stepErr("打开搜索结果页", "", err)
+	}
+	if err := page.WaitStable(time.Second); err != nil {
+		return nil, stepErr("等待搜索结果页稳定", "", err)
+	}
 
-	page.MustWait(`() => window.__INITIAL_STATE__ !== undefined`)
+	if err := diagnosePage(page, "打开搜索结果页"); err != nil {
+		return nil, err
+	}
+
+	if err := waitInitialState(page); err != nil {
+		return nil, err
+	}
 
 	// 获取 window.__INITIAL_STATE__ 并转换为 JSON 字符串
-	result := page.MustEval(`() => {
-			if (window.__INITIAL_STATE__) {
-				return JSON.stringify(window.__INITIAL_STATE__);
-			}
-			return "";
-		}`).String()
+	result, err := readInitialState(page)
+	if err != nil {
+		return nil, err
+	}
 
 	if result == "" {
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
@@ -61,5 +70,5 @@ func makeSearchURL(keyword string) string {
 	values.Set("keyword", keyword)
 	values.Set("source", "web_explore_feed")
 
-	return fmt.Sprintf("https://www.xiaohongshu.com/search_result?%s", values.Encode())
+	return siteURL("/search_result?" + values.Encode())
 }
@@ -0,0 +1,339 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SelectorKey 页面逻辑元素的名称，每个元素对应一组按优先级排列的 CSS 选择器
+type SelectorKey string
+
+// 页面逻辑元素。小红书改版后可以在选择器文件中覆盖对应元素的选择器，无需重新编译
+const (
+	SelectorExploreApp      SelectorKey = "explore.app"       // 发现页根节点
+	SelectorNoteCard        SelectorKey = "explore.note_card" // 推荐流中的笔记卡片
+	SelectorNoteCardLink    SelectorKey = "explore.note_card_link"
+	SelectorNoteCardTitle   SelectorKey = "explore.note_card_title"
+	SelectorLoginUserEntry  SelectorKey = "login.user_entry" // 已登录时侧边栏的「我」入口
+	SelectorLoginQrcode     SelectorKey = "login.qrcode"
+	SelectorNoteModal       SelectorKey = "note.modal" // 笔记详情弹窗
+	SelectorNoteModalClose  SelectorKey = "note.modal_close"
+	SelectorLikeButton      SelectorKey = "note.like_button"
+	SelectorCollectButton   SelectorKey = "note.collect_button"
+	SelectorModalLike       SelectorKey = "note.modal_like_button"
+	SelectorModalCollect    SelectorKey = "note.modal_collect_button"
+	SelectorLikeState       SelectorKey = "note.like_state" // 用于读取点赞状态的元素
+	SelectorCollectState    SelectorKey = "note.collect_state"
+	SelectorFollowButton    SelectorKey = "note.follow_button"
+	SelectorImageSlider     SelectorKey = "note.image_slider"
+	SelectorImageSliderNext SelectorKey = "note.image_slider_next"
+	SelectorCommentItem     SelectorKey = "comment.item"
+	SelectorCommentTrigger  SelectorKey = "comment.input_trigger" // 点击后展开评论输入框
+	SelectorCommentInput    SelectorKey = "comment.input"
+	SelectorCommentSubmit   SelectorKey = "comment.submit"
+	SelectorPublishPopover  SelectorKey = "publish.popover"
+	SelectorPublishUpload   SelectorKey = "publish.upload_area"
+	SelectorPublishTab      SelectorKey = "publish.creator_tab"
+	SelectorUploadInput     SelectorKey = "publish.upload_input"
+	SelectorImagePreview    SelectorKey = "publish.image_preview" // 已上传图片的预览项
+	SelectorTitleInput      SelectorKey = "publish.title_input"
+	SelectorContentEditor   SelectorKey = "publish.content_editor"
+	SelectorTopicItem       SelectorKey = "publish.topic_item" // 话题联想下拉框的选项
+	SelectorPublishButton   SelectorKey = "publish.submit"
+	SelectorVideoPublishBtn SelectorKey = "publish.video_submit"
+)
+
+// DefaultSelectorsVersion 内置选择器的版本，选择器调整时同步更新
+const DefaultSelectorsVersion = "2025.10.1"
+
+// defaultSelectors 内置选择器，选择器文件中未覆盖的元素使用这里的值
+var defaultSelectors = map[SelectorKey][]string{
+	SelectorExploreApp:     {"div#app"},
+	SelectorNoteCard:       {"section.note-item"},
+	SelectorNoteCardLink:   {"a.cover"},
+	SelectorNoteCardTitle:  {"a.title span", "a.title", ".title span", ".title", "a[class*='title'] span", "a[class*='title']"},
+	SelectorLoginUserEntry: {".main-container .user .link-wrapper .channel"},
+	SelectorLoginQrcode:    {".login-container .qrcode-img"},
+	SelectorNoteModal:      {".note-detail-modal", ".modal", "[class*='detail']"},
+	SelectorNoteModalClose: {"div.close", "div[class*='mask']", "div[class*='overlay']", ".note-detail-mask"},
+	SelectorLikeButton:     {".interact-container .left .like-lottie"},
+	SelectorCollectButton:  {".interact-container .left .reds-icon.collect-icon"},
+	SelectorModalLike:      {".interact-container .left .like-lottie", ".like-lottie", "[class*='like']"},
+	SelectorModalCollect:   {".interact-container .left .reds-icon.collect-icon", ".collect-icon", "[class*='collect']"},
+	SelectorLikeState: {
+		".interact-container .left .like-wrapper", ".left .like-wrapper", ".like-wrapper",
+		".interact-container .left .like-lottie", ".like-lottie", ".reds-icon.like-icon", "[class*='like']",
+	},
+	SelectorCollectState: {
+		"#note-page-collect-board-guide", ".interact-container .left .collect-wrapper", ".left .collect-wrapper", ".collect-wrapper",
+		".interact-container .left .reds-icon.collect-icon", ".collect-icon", ".reds-icon.collect-icon", "[class*='collect']",
+	},
+	SelectorFollowButton:    {"button.follow-button", ".follow-button", "button[class*='follow']"},
+	SelectorImageSlider:     {".slider-container .note-slider", ".note-slider", ".slider-container"},
+	SelectorImageSliderNext: {".slider-container .arrow-controller.right", ".arrow-controller.right"},
+	SelectorCommentItem:     {".comment-item", ".comments-container .comment-item", "[class*='comment-item']", ".comment-list .item"},
+	SelectorCommentTrigger:  {"div.input-box div.content-edit span"},
+	SelectorCommentInput:    {"div.input-box div.content-edit p.content-input"},
+	SelectorCommentSubmit:   {"div.bottom button.submit"},
+	SelectorPublishPopover:  {"div.d-popover"},
+	SelectorPublishUpload:   {"div.upload-content"},
+	SelectorPublishTab:      {"div.creator-tab"},
+	SelectorUploadInput:     {".upload-input", "input[type='file']"},
+	SelectorImagePreview:    {".img-preview-area .pr"},
+	SelectorTitleInput:      {"div.d-input input"},
+	SelectorContentEditor:   {"div.ql-editor"},
+	SelectorTopicItem:       {"#creator-editor-topic-container .item"},
+	SelectorPublishButton:   {"div.submit div.d-button-content"},
+	SelectorVideoPublishBtn: {"button.publishBtn"},
+}
+
+// SelectorFile 选择器文件格式（JSON 或 YAML），只需列出要覆盖的元素：
+//
+//	version: "2025.11.1"
+//	selectors:
+//	  note.like_button:
+//	    - ".interact-container .left .like-lottie"
+//	    - ".like-wrapper"
+type SelectorFile struct {
+	Version   string                   `json:"version" yaml:"version"`
+	Selectors map[SelectorKey][]string `json:"selectors" yaml:"selectors"`
+}
+
+// SelectorsInfo 当前生效的选择器信息
+type SelectorsInfo struct {
+	Version        string                   `json:"version"`         // 选择器文件的版本，未加载文件时为内置版本
+	BuiltinVersion string                   `json:"builtin_version"` // 内置选择器的版本
+	Source         string                   `json:"source"`          // 选择器文件路径，未加载文件时为 builtin
+	LoadedAt       time.Time                `json:"loaded_at"`
+	Overrides      []SelectorKey            `json:"overrides"` // 被文件覆盖的元素
+	Selectors      map[SelectorKey][]string `json:"selectors"`
+}
+
+// selectorRegistry 运行时可替换的选择器表
+type selectorRegistry struct {
+	mu        sync.RWMutex
+	version   string
+	path      string
+	loadedAt  time.Time
+	overrides []SelectorKey
+	selectors map[SelectorKey][]string
+}
+
+var selectors = &selectorRegistry{
+	version:   DefaultSelectorsVersion,
+	loadedAt:  time.Now(),
+	selectors: defaultSelectors,
+}
+
+// LoadSelectors 从 JSON 或 YAML 文件加载选择器并覆盖内置值，之后可通过 ReloadSelectors 重新加载。
+// 文件有误时保留当前选择器不变并返回错误
+func LoadSelectors(path string) (SelectorsInfo, error) {
+	file, err := readSelectorFile(path)
+	if err != nil {
+		return SelectorsInfo{}, err
+	}
+
+	merged, overrides := mergeSelectors(file)
+
+	selectors.mu.Lock()
+	selectors.version = file.Version
+	selectors.path = path
+	selectors.loadedAt = time.Now()
+	selectors.overrides = overrides
+	selectors.selectors = merged
+	selectors.mu.Unlock()
+
+	logrus.Infof("已加载选择器文件 %s，版本 %s，覆盖 %d 个元素", path, file.Version, len(overrides))
+	return CurrentSelectors(), nil
+}
+
+// ReloadSelectors 重新读取上次加载的选择器文件，未加载过文件时返回错误
+func ReloadSelectors() (SelectorsInfo, error) {
+	selectors.mu.RLock()
+	path := selectors.path
+	selectors.mu.RUnlock()
+
+	if path == "" {
+		return SelectorsInfo{}, errors.New("未配置选择器文件，使用的是内置选择器")
+	}
+	return LoadSelectors(path)
+}
+
+// CurrentSelectors 返回当前生效的选择器
+func CurrentSelectors() SelectorsInfo {
+	selectors.mu.RLock()
+	defer selectors.mu.RUnlock()
+
+	source := selectors.path
+	if source == "" {
+		source = "builtin"
+	}
+
+	all := make(map[SelectorKey][]string, len(selectors.selectors))
+	for key, list := range selectors.selectors {
+		all[key] = append([]string(nil), list...)
+	}
+
+	return SelectorsInfo{
+		Version:        selectors.version,
+		BuiltinVersion: DefaultSelectorsVersion,
+		Source:         source,
+		LoadedAt:       selectors.loadedAt,
+		Overrides:      append([]SelectorKey{}, selectors.overrides...),
+		Selectors:      all,
+	}
+}
+
+// selectorsFor 返回元素的候选选择器（按优先级排列）
+func selectorsFor(key SelectorKey) []string {
+	selectors.mu.RLock()
+	defer selectors.mu.RUnlock()
+
+	return selectors.selectors[key]
+}
+
+// readSelectorFile 读取并校验选择器文件，按扩展名选择 JSON 或 YAML 解析
+func readSelectorFile(path string) (*SelectorFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取选择器文件失败")
+	}
+
+	var file SelectorFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("不支持的选择器文件格式: %s（仅支持 .json、.yaml、.yml）", path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "解析选择器文件 %s 失败", path)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, errors.Wrapf(err, "选择器文件 %s 无效", path)
+	}
+	return &file, nil
+}
+
+// validate 校验版本号与选择器：元素必须是已知的，且至少有一个非空选择器
+func (f *SelectorFile) validate() error {
+	if strings.TrimSpace(f.Version) == "" {
+		return errors.New("缺少 version")
+	}
+
+	for key, list := range f.Selectors {
+		if _, ok := defaultSelectors[key]; !ok {
+			return fmt.Errorf("未知的元素 %q", key)
+		}
+		if len(list) == 0 {
+			return fmt.Errorf("元素 %q 没有配置选择器", key)
+		}
+		for _, s := range list {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("元素 %q 包含空选择器", key)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeSelectors 以内置选择器为基础，用文件中的元素整体替换对应的候选列表
+func mergeSelectors(file *SelectorFile) (map[SelectorKey][]string, []SelectorKey) {
+	merged := make(map[SelectorKey][]string, len(defaultSelectors))
+	for key, list := range defaultSelectors {
+		merged[key] = list
+	}
+
+	overrides := make([]SelectorKey, 0, len(file.Selectors))
+	for key, list := range file.Selectors {
+		merged[key] = append([]string(nil), list...)
+		overrides = append(overrides, key)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i] < overrides[j] })
+
+	return merged, overrides
+}
+
+// selectorLabel 用于错误信息的选择器描述
+func selectorLabel(key SelectorKey) string {
+	return strings.Join(selectorsFor(key), " | ")
+}
+
+// hasElement 按优先级检查元素是否已存在（不等待），返回第一个命中的元素
+func hasElement(page *rod.Page, key SelectorKey) (bool, *rod.Element, error) {
+	var lastErr error
+	for _, sel := range selectorsFor(key) {
+		has, el, err := page.Has(sel)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if has {
+			return true, el, nil
+		}
+	}
+	return false, nil, lastErr
+}
+
+// findElement 查找元素：已存在多个候选时按优先级返回，否则等待任一候选出现。
+// 找不到时返回带步骤与选择器的错误
+func findElement(page *rod.Page, step string, key SelectorKey) (el *rod.Element, err error) {
+	defer func() {
+		if err != nil {
+			traceStep(page, step, key, err)
+		}
+	}()
+
+	list := selectorsFor(key)
+	if len(list) == 1 {
+		el, err := page.Element(list[0])
+		if err != nil {
+			return nil, stepErr(step, list[0], err)
+		}
+		return el, nil
+	}
+
+	if has, el, _ := hasElement(page, key); has {
+		return el, nil
+	}
+
+	race := page.Race()
+	for _, sel := range list {
+		race = race.Element(sel)
+	}
+	el, err = race.Do()
+	if err != nil {
+		return nil, stepErr(step, selectorLabel(key), err)
+	}
+	return el, nil
+}
+
+// findAllElements 返回第一个有匹配结果的候选选择器找到的全部元素（不等待）
+func findAllElements(page *rod.Page, key SelectorKey) (rod.Elements, error) {
+	var lastErr error
+	for _, sel := range selectorsFor(key) {
+		elems, err := page.Elements(sel)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(elems) > 0 {
+			return elems, nil
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,90 @@
+package xiaohongshu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetSelectors 测试结束后恢复内置选择器
+func resetSelectors(t *testing.T) {
+	t.Cleanup(func() {
+		selectors.mu.Lock()
+		defer selectors.mu.Unlock()
+		selectors.version = DefaultSelectorsVersion
+		selectors.path = ""
+		selectors.overrides = nil
+		selectors.selectors = defaultSelectors
+	})
+}
+
+func writeSelectorFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadSelectorsYAML(t *testing.T) {
+	resetSelectors(t)
+
+	path := writeSelectorFile(t, "selectors.yaml", `
+version: "2025.11.1"
+selectors:
+  note.like_button:
+    - ".new-like"
+    - ".interact-container .left .like-lottie"
+`)
+
+	info, err := LoadSelectors(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2025.11.1", info.Version)
+	assert.Equal(t, path, info.Source)
+	assert.Equal(t, []SelectorKey{SelectorLikeButton}, info.Overrides)
+
+	assert.Equal(t, []string{".new-like", ".interact-container .left .like-lottie"}, selectorsFor(SelectorLikeButton))
+	// 未覆盖的元素保持内置值
+	assert.Equal(t, defaultSelectors[SelectorCollectButton], selectorsFor(SelectorCollectButton))
+}
+
+func TestLoadSelectorsInvalidKeepsCurrent(t *testing.T) {
+	resetSelectors(t)
+
+	path := writeSelectorFile(t, "selectors.json", `{"version": "v1", "selectors": {"comment.submit": ["button.send"]}}`)
+	_, err := LoadSelectors(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing version", `{"selectors": {"comment.submit": ["button.x"]}}`},
+		{"unknown key", `{"version": "v2", "selectors": {"comment.unknown": ["button.x"]}}`},
+		{"empty list", `{"version": "v2", "selectors": {"comment.submit": []}}`},
+		{"blank selector", `{"version": "v2", "selectors": {"comment.submit": [" "]}}`},
+		{"bad json", `{"version": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			_, err := ReloadSelectors()
+			assert.Error(t, err)
+			assert.Equal(t, "v1", CurrentSelectors().Version)
+			assert.Equal(t, []string{"button.send"}, selectorsFor(SelectorCommentSubmit))
+		})
+	}
+}
+
+func TestReloadSelectorsWithoutFile(t *testing.T) {
+	resetSelectors(t)
+
+	_, err := ReloadSelectors()
+	assert.Error(t, err)
+
+	info := CurrentSelectors()
+	assert.Equal(t, "builtin", info.Source)
+	assert.Equal(t, DefaultSelectorsVersion, info.Version)
+}
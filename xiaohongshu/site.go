@@ -0,0 +1,61 @@
+package xiaohongshu
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// 小红书站点地址。测试时可以通过 SetOrigins 指向本地的 fixture server
+const (
+	DefaultSiteOrigin    = configs.DefaultSiteOrigin
+	DefaultCreatorOrigin = configs.DefaultCreatorOrigin
+)
+
+var (
+	originMu      sync.RWMutex
+	siteOrigin    = DefaultSiteOrigin
+	creatorOrigin = DefaultCreatorOrigin
+)
+
+// SetOrigins 设置主站与创作者中心的地址（如 http://127.0.0.1:8080），为空时使用默认地址
+func SetOrigins(site, creator string) {
+	originMu.Lock()
+	defer originMu.Unlock()
+
+	siteOrigin = normalizeOrigin(site, DefaultSiteOrigin)
+	creatorOrigin = normalizeOrigin(creator, DefaultCreatorOrigin)
+}
+
+// SiteOrigin 返回主站地址
+func SiteOrigin() string {
+	originMu.RLock()
+	defer originMu.RUnlock()
+	return siteOrigin
+}
+
+// CreatorOrigin 返回创作者中心地址
+func CreatorOrigin() string {
+	originMu.RLock()
+	defer originMu.RUnlock()
+	return creatorOrigin
+}
+
+// siteURL 拼接主站页面地址，path 以 / 开头
+func siteURL(path string) string {
+	return SiteOrigin() + path
+}
+
+// creatorURL 拼接创作者中心页面地址，path 以 / 开头
+func creatorURL(path string) string {
+	return CreatorOrigin() + path
+}
+
+func normalizeOrigin(origin, fallback string) string {
+	origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+	if origin == "" {
+		return fallback
+	}
+	return origin
+}
@@ -0,0 +1,26 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOrigins(t *testing.T) {
+	t.Cleanup(func() { SetOrigins("", "") })
+
+	assert.Equal(t, "https://www.xiaohongshu.com/explore/abc?xsec_token=t&xsec_source=pc_feed", makeFeedDetailURL("abc", "t"))
+
+	SetOrigins("http://127.0.0.1:8080/", "http://127.0.0.1:8081")
+	assert.Equal(t, "http://127.0.0.1:8080/explore/abc?xsec_token=t&xsec_source=pc_feed", makeFeedDetailURL("abc", "t"))
+	assert.Equal(t, "http://127.0.0.1:8080/user/profile/u1?xsec_token=t&xsec_source=pc_note", makeUserProfileURL("u1", "t"))
+	assert.Equal(t, "http://127.0.0.1:8080/search_result?keyword=Kimi&source=web_explore_feed", makeSearchURL("Kimi"))
+	assert.Equal(t, "http://127.0.0.1:8081/publish/publish?source=official", creatorURL(pathOfPublish))
+
+	assert.Equal(t, ErrNoteNotFound, matchPageURL("http://127.0.0.1:8080/404?error_code=300031"))
+	assert.Equal(t, ErrNotLoggedIn, matchPageURL("http://127.0.0.1:8081/login?redirect=publish"))
+
+	SetOrigins("", "")
+	assert.Equal(t, DefaultSiteOrigin, SiteOrigin())
+	assert.Equal(t, DefaultCreatorOrigin, CreatorOrigin())
+}
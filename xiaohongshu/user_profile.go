@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
 type UserProfileAction struct {
@@ -14,27 +15,36 @@ type UserProfileAction struct {
 }
 
 func NewUserProfileAction(page *rod.Page) *UserProfileAction {
-	pp := page.Timeout(60 * time.Second)
-	return &UserProfileAction{page: pp}
+	return &UserProfileAction{page: page}
 }
 
 // UserProfile 获取用户基本信息及帖子
-func (u *UserProfileAction) UserProfile(ctx context.Context, userID, xsecToken string) (*UserProfileResponse, error) {
-	page := u.page.Context(ctx)
+func (u *UserProfileAction) UserProfile(ctx context.Context, userID, xsecToken string) (_ *UserProfileResponse, err error) {
+	defer recoverAction(&err, "获取用户主页")
+
+	page := u.page.Context(ctx).Timeout(configs.Get().Timeouts.Action)
 
 	searchURL := makeUserProfileURL(userID, xsecToken)
-	page.MustNavigate(searchURL)
-	page.MustWaitStable()
+	if err := page.Navigate(searchURL); err != nil {
+		return nil, stepErr("打开用户主页", "", err)
+	}
+	if err := page.WaitStable(time.Second); err != nil {
+		return nil, stepErr("等待用户主页稳定", "", err)
+	}
 
-	page.MustWait(`() => window.__INITIAL_STATE__ !== undefined`)
+	if err := diagnosePage(page, "打开用户主页"); err != nil {
+		return nil, err
+	}
+
+	if err := waitInitialState(page); err != nil {
+		return nil, err
+	}
 
 	// 获取 window.__INITIAL_STATE__ 并转换为 JSON 字符串
-	result := page.MustEval(`() => {
-			if (window.__INITIAL_STATE__) {
-				return JSON.stringify(window.__INITIAL_STATE__);
-			}
-			return "";
-		}`).String()
+	result, err := readInitialState(page)
+	if err != nil {
+		return nil, err
+	}
 
 	if result == "" {
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
@@ -67,5 +77,5 @@ func (u *UserProfileAction) UserProfile(ctx context.Context, userID, xsecToken s
 }
 
 func makeUserProfileURL(userID, xsecToken string) string {
-	return fmt.Sprintf("https://www.xiaohongshu.com/user/profile/%s?xsec_token=%s&xsec_source=pc_note", userID, xsecToken)
+	return siteURL(fmt.Sprintf("/user/profile/%s?xsec_token=%s&xsec_source=pc_note", userID, xsecToken))
 }